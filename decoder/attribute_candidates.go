@@ -34,20 +34,30 @@ func detailForAttribute(attr *schema.AttributeSchema) string {
 		detail = "Optional"
 	}
 
-	if len(attr.ValueTypes) > 0 {
-		detail += fmt.Sprintf(", %s", strings.Join(attr.ValueTypes.FriendlyNames(), " or "))
-	} else {
-		detail += fmt.Sprintf(", %s", attr.ValueType.FriendlyName())
-	}
+	detail += fmt.Sprintf(", %s", typeNameForAttribute(attr))
 
 	return detail
 }
 
+func typeNameForAttribute(attr *schema.AttributeSchema) string {
+	if len(attr.ValueTypes) > 0 {
+		return strings.Join(attr.ValueTypes.FriendlyNames(), " or ")
+	}
+	return attr.ValueType.FriendlyName()
+}
+
 func snippetForAttribute(name string, attr *schema.AttributeSchema) string {
+	return fmt.Sprintf("%s = %s", name, snippetForAttributeValue(1, attr))
+}
+
+func snippetForAttributeValue(placeholder uint, attr *schema.AttributeSchema) string {
+	if len(attr.AllowedValues) > 0 {
+		return snippetForAllowedValues(placeholder, attr.AllowedValues)
+	}
 	if len(attr.ValueTypes) > 0 {
-		return fmt.Sprintf("%s = %s", name, snippetForAttrValue(1, attr.ValueTypes[0]))
+		return snippetForAttrValue(placeholder, attr.ValueTypes[0])
 	}
-	return fmt.Sprintf("%s = %s", name, snippetForAttrValue(1, attr.ValueType))
+	return snippetForAttrValue(placeholder, attr.ValueType)
 }
 
 func snippetForAttrValue(placeholder uint, attrType cty.Type) string {
@@ -63,8 +73,8 @@ func snippetForAttrValue(placeholder uint, attrType cty.Type) string {
 	}
 
 	if attrType.IsMapType() {
-		return fmt.Sprintf("{\n"+`  "${1:key}" = %s`+"\n}",
-			snippetForAttrValue(placeholder+1, *attrType.MapElementType()))
+		return fmt.Sprintf("{\n"+`  "${%d:key}" = %s`+"\n}",
+			placeholder, snippetForAttrValue(placeholder+1, *attrType.MapElementType()))
 	}
 
 	if attrType.IsListType() || attrType.IsSetType() {
@@ -98,6 +98,18 @@ func TestSnippetForAttribute(t *testing.T) {
 			},
 			`mynil = ${1}`,
 		},
+		{
+			"allowed values",
+			"myenum",
+			&schema.AttributeSchema{
+				ValueType: cty.String,
+				AllowedValues: []schema.AllowedValue{
+					{Value: "first"},
+					{Value: "second,third"},
+				},
+			},
+			`myenum = "${1|first,second\,third|}"`,
+		},
 		// TODO: Indent nested objects correctly
 		// 		{
 		// 			"nested object",
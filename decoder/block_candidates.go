@@ -2,25 +2,27 @@ package decoder
 
 import (
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/hashicorp/hcl-lang/lang"
 	"github.com/hashicorp/hcl-lang/schema"
 	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 )
 
 func blockSchemaToCandidate(blockType string, block *schema.BlockSchema, rng hcl.Range) lang.Candidate {
+	// The cursor ends up in the first dependency key label
+	// after insertion (see snippetForBlockWithBody), so suggestion
+	// is triggered if there is any, as it has candidates
+	// based on DependentSchema.
 	triggerSuggest := false
-	if len(block.Labels) > 0 {
-		// We make some naive assumptions here for simplicity
-		// and because this works just well enough for Terraform.
-		// - if there is "completable" label it's the first one and the only one
-		// - the label has some candidates based on DependentSchema
-		//
-		// The implementation can certainly be more sophisticated
-		// but it would likely involve changes in snippet placeholder
-		// numbering and full understanding of UX implications.
-		triggerSuggest = block.Labels[0].IsDepKey
+	for _, l := range block.Labels {
+		if l.IsDepKey {
+			triggerSuggest = true
+			break
+		}
 	}
 
 	return lang.Candidate{
@@ -54,18 +56,120 @@ func detailForBlock(block *schema.BlockSchema) string {
 	return strings.TrimSpace(detail)
 }
 
+// snippetForBlock returns snippet for the block
+// with required attributes and blocks pre-filled
 func snippetForBlock(blockType string, block *schema.BlockSchema) string {
-	labels := ""
-	placeholder := 1
+	snippet, _ := snippetForBlockWithBody(blockType, block,
+		schema.NewLayeredBodySchema(block.Body), 1, "", nil)
+	return snippet
+}
 
-	for _, l := range block.Labels {
+// snippetForBlockWithBody returns snippet for the block with the given body
+// schema, starting at the given placeholder, and the next unused placeholder.
+//
+// parents are schemas of blocks the snippet is nested in.
+func snippetForBlockWithBody(blockType string, block *schema.BlockSchema, body *schema.LayeredBodySchema, placeholder uint, indent string, parents []*schema.BlockSchema) (string, uint) {
+	// placeholders of dependency key labels come first, such that
+	// their values can be suggested right after insertion
+	labelPlaceholders := make([]uint, len(block.Labels))
+	for i, l := range block.Labels {
 		if l.IsDepKey {
-			labels += fmt.Sprintf(` "${%d}"`, placeholder)
+			labelPlaceholders[i] = placeholder
+			placeholder++
+		}
+	}
+	for i, l := range block.Labels {
+		if !l.IsDepKey {
+			labelPlaceholders[i] = placeholder
+			placeholder++
+		}
+	}
+
+	labels := ""
+	for i, l := range block.Labels {
+		if l.IsDepKey {
+			labels += fmt.Sprintf(` "${%d}"`, labelPlaceholders[i])
 		} else {
-			labels += fmt.Sprintf(` "${%d:%s}"`, placeholder, l.Name)
+			labels += fmt.Sprintf(` "${%d:%s}"`, labelPlaceholders[i], l.Name)
+		}
+	}
+
+	bodySnippet, placeholder := snippetForRequiredBody(body, nil, placeholder, indent+"  ",
+		append(parents, block))
+
+	return fmt.Sprintf("%s%s {\n%s%s  ${%d}\n%s}", blockType, labels,
+		bodySnippet, indent, placeholder, indent), placeholder + 1
+}
+
+// snippetForRequiredBody returns snippet of required attributes
+// and blocks (MinItems > 0) of the body schema, which are not yet
+// declared in the given body (if any), one per line with the given
+// indentation, and the next unused placeholder.
+//
+// Required blocks of the same schema as any of the parents
+// (i.e. recursive schemas) are not pre-filled.
+func snippetForRequiredBody(bodySchema *schema.LayeredBodySchema, body *hclsyntax.Body, placeholder uint, indent string, parents []*schema.BlockSchema) (string, uint) {
+	var sb strings.Builder
+
+	for _, name := range bodySchema.AttributeNames() {
+		attr, _ := bodySchema.Attribute(name)
+		if !attr.IsRequired {
+			continue
+		}
+		if body != nil {
+			if _, declared := body.Attributes[name]; declared {
+				continue
+			}
+		}
+
+		value := snippetForAttributeValue(placeholder, attr)
+		placeholder = nextPlaceholder(value, placeholder)
+		fmt.Fprintf(&sb, "%s%s = %s\n", indent, name, indentSnippet(value, indent))
+	}
+
+	declaredBody := body
+	if declaredBody == nil {
+		declaredBody = &hclsyntax.Body{}
+	}
+	for _, bType := range bodySchema.BlockTypes() {
+		block, _ := bodySchema.Block(bType)
+		if !isBlockRequired(declaredBody, bType, block) || isBlockSchemaIn(block, parents) {
+			continue
 		}
-		placeholder++
+
+		var snippet string
+		snippet, placeholder = snippetForBlockWithBody(bType, block,
+			schema.NewLayeredBodySchema(block.Body), placeholder, indent, parents)
+		fmt.Fprintf(&sb, "%s%s\n", indent, snippet)
 	}
 
-	return fmt.Sprintf("%s%s {\n  ${%d}\n}", blockType, labels, placeholder)
+	return sb.String(), placeholder
+}
+
+func isBlockSchemaIn(block *schema.BlockSchema, blocks []*schema.BlockSchema) bool {
+	for _, b := range blocks {
+		if b == block {
+			return true
+		}
+	}
+	return false
+}
+
+var placeholderRegexp = regexp.MustCompile(`\$\{(\d+)`)
+
+// nextPlaceholder returns placeholder following the highest one
+// used in the snippet, or the given placeholder if there is none
+func nextPlaceholder(snippet string, placeholder uint) uint {
+	for _, match := range placeholderRegexp.FindAllStringSubmatch(snippet, -1) {
+		n, err := strconv.ParseUint(match[1], 10, 64)
+		if err == nil && uint(n) >= placeholder {
+			placeholder = uint(n) + 1
+		}
+	}
+	return placeholder
+}
+
+// indentSnippet indents all but the first line of a multi-line snippet
+func indentSnippet(snippet, indent string) string {
+	return strings.ReplaceAll(snippet, "\n", "\n"+indent)
 }
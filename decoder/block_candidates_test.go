@@ -0,0 +1,314 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestSnippetForBlock(t *testing.T) {
+	recursiveBlock := &schema.BlockSchema{
+		MinItems: 1,
+		Body: &schema.BodySchema{
+			Attributes: map[string]*schema.AttributeSchema{
+				"name": {ValueType: cty.String, IsRequired: true},
+			},
+		},
+	}
+	recursiveBlock.Body.Blocks = map[string]*schema.BlockSchema{
+		"node": recursiveBlock,
+	}
+
+	testCases := []struct {
+		testName        string
+		blockType       string
+		blockSchema     *schema.BlockSchema
+		expectedSnippet string
+	}{
+		{
+			"no required fields",
+			"provider",
+			&schema.BlockSchema{
+				Labels: []*schema.LabelSchema{
+					{Name: "name"},
+				},
+				Body: &schema.BodySchema{
+					Attributes: map[string]*schema.AttributeSchema{
+						"alias": {ValueType: cty.String, IsOptional: true},
+					},
+				},
+			},
+			`provider "${1:name}" {
+  ${2}
+}`,
+		},
+		{
+			"required attributes",
+			"resource",
+			&schema.BlockSchema{
+				Labels: []*schema.LabelSchema{
+					{Name: "type", IsDepKey: true},
+					{Name: "name"},
+				},
+				Body: &schema.BodySchema{
+					Attributes: map[string]*schema.AttributeSchema{
+						"count": {ValueType: cty.Number, IsOptional: true},
+						"tags":  {ValueType: cty.Map(cty.String), IsRequired: true},
+						"mode": {
+							ValueType:  cty.String,
+							IsRequired: true,
+							AllowedValues: []schema.AllowedValue{
+								{Value: "fast"},
+								{Value: "slow"},
+							},
+						},
+					},
+				},
+			},
+			`resource "${1}" "${2:name}" {
+  mode = "${3|fast,slow|}"
+  tags = {
+    "${4:key}" = "${5:value}"
+  }
+  ${6}
+}`,
+		},
+		{
+			"required nested blocks",
+			"resource",
+			&schema.BlockSchema{
+				Body: &schema.BodySchema{
+					Attributes: map[string]*schema.AttributeSchema{
+						"name": {ValueType: cty.String, IsRequired: true},
+					},
+					Blocks: map[string]*schema.BlockSchema{
+						"lifecycle": {},
+						"network_interface": {
+							MinItems: 1,
+							Body: &schema.BodySchema{
+								Attributes: map[string]*schema.AttributeSchema{
+									"device_index": {ValueType: cty.Number, IsRequired: true},
+								},
+							},
+						},
+					},
+				},
+			},
+			`resource {
+  name = "${1:value}"
+  network_interface {
+    device_index = ${2:1}
+    ${3}
+  }
+  ${4}
+}`,
+		},
+		{
+			"second label dependency key",
+			"connector",
+			&schema.BlockSchema{
+				Labels: []*schema.LabelSchema{
+					{Name: "name"},
+					{Name: "type", IsDepKey: true},
+				},
+			},
+			`connector "${2:name}" "${1}" {
+  ${3}
+}`,
+		},
+		{
+			"recursive required blocks",
+			"node",
+			recursiveBlock,
+			`node {
+  name = "${1:value}"
+  ${2}
+}`,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.testName), func(t *testing.T) {
+			snippet := snippetForBlock(tc.blockType, tc.blockSchema)
+			if diff := cmp.Diff(tc.expectedSnippet, snippet); diff != "" {
+				t.Fatalf("unexpected snippet: %s", diff)
+			}
+		})
+	}
+}
+
+func TestBlockSchemaToCandidate_triggerSuggest(t *testing.T) {
+	testCases := []struct {
+		name                   string
+		labels                 []*schema.LabelSchema
+		expectedTriggerSuggest bool
+	}{
+		{
+			"no labels",
+			nil,
+			false,
+		},
+		{
+			"no dependency key labels",
+			[]*schema.LabelSchema{
+				{Name: "name"},
+			},
+			false,
+		},
+		{
+			"first label dependency key",
+			[]*schema.LabelSchema{
+				{Name: "type", IsDepKey: true},
+				{Name: "name"},
+			},
+			true,
+		},
+		{
+			"second label dependency key",
+			[]*schema.LabelSchema{
+				{Name: "name"},
+				{Name: "type", IsDepKey: true},
+			},
+			true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			candidate := blockSchemaToCandidate("connector", &schema.BlockSchema{
+				Labels: tc.labels,
+			}, hcl.Range{})
+			if candidate.TriggerSuggest != tc.expectedTriggerSuggest {
+				t.Fatalf("expected TriggerSuggest: %t, given: %t",
+					tc.expectedTriggerSuggest, candidate.TriggerSuggest)
+			}
+		})
+	}
+}
+
+func TestDecoder_CandidatesAtPos_labelWithRequiredFields(t *testing.T) {
+	resourceSchema := &schema.BlockSchema{
+		Labels: []*schema.LabelSchema{
+			{Name: "type", IsDepKey: true},
+			{Name: "name"},
+		},
+		Body: &schema.BodySchema{
+			Attributes: map[string]*schema.AttributeSchema{
+				"count": {ValueType: cty.Number, IsOptional: true},
+			},
+		},
+		DependentBody: map[schema.SchemaKey]*schema.BodySchema{
+			schema.NewSchemaKey(schema.DependencyKeys{
+				Labels: []schema.LabelDependent{
+					{Index: 0, Value: "aws_instance"},
+				},
+			}): {
+				Attributes: map[string]*schema.AttributeSchema{
+					"ami":           {ValueType: cty.String, IsRequired: true},
+					"instance_type": {ValueType: cty.String, IsRequired: true},
+				},
+				Blocks: map[string]*schema.BlockSchema{
+					"ebs_block_device": {
+						MinItems: 1,
+						Body: &schema.BodySchema{
+							Attributes: map[string]*schema.AttributeSchema{
+								"device_name": {ValueType: cty.String, IsRequired: true},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	bodySchema := &schema.BodySchema{
+		Blocks: map[string]*schema.BlockSchema{
+			"resource": resourceSchema,
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		cfg           string
+		pos           hcl.Pos
+		expectedEdits []lang.TextEdit
+	}{
+		{
+			"space indentation",
+			`resource "" "example" {
+  ami = "ami-123"
+}
+`,
+			hcl.Pos{Line: 1, Column: 11, Byte: 10},
+			[]lang.TextEdit{
+				{
+					Range: hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 24, Byte: 23},
+						End:      hcl.Pos{Line: 1, Column: 24, Byte: 23},
+					},
+					NewText: `
+  instance_type = "value"
+  ebs_block_device {
+    device_name = "value"
+  }`,
+					Snippet: `
+  instance_type = "value"
+  ebs_block_device {
+    device_name = "value"
+  }`,
+				},
+			},
+		},
+		{
+			"tab indentation",
+			"\tresource \"\" \"example\" {}\n",
+			hcl.Pos{Line: 1, Column: 12, Byte: 11},
+			[]lang.TextEdit{
+				{
+					Range: hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 25, Byte: 24},
+						End:      hcl.Pos{Line: 1, Column: 25, Byte: 24},
+					},
+					NewText: "\n\t  ami = \"value\"\n\t  instance_type = \"value\"\n" +
+						"\t  ebs_block_device {\n\t    device_name = \"value\"\n\t  }\n\t",
+					Snippet: "\n\t  ami = \"value\"\n\t  instance_type = \"value\"\n" +
+						"\t  ebs_block_device {\n\t    device_name = \"value\"\n\t  }\n\t",
+				},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(bodySchema)
+			f, pDiags := hclsyntax.ParseConfig([]byte(tc.cfg), "test.tf", hcl.InitialPos)
+			if len(pDiags) > 0 {
+				t.Fatal(pDiags)
+			}
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			candidates, err := d.CandidatesAtPos("test.tf", tc.pos)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(candidates.List) != 1 {
+				t.Fatalf("expected 1 candidate, given: %d", len(candidates.List))
+			}
+
+			if diff := cmp.Diff(tc.expectedEdits, candidates.List[0].AdditionalTextEdits); diff != "" {
+				t.Fatalf("unexpected edits: %s", diff)
+			}
+		})
+	}
+}
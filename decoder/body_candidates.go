@@ -1,96 +1,72 @@
 package decoder
 
 import (
-	"sort"
-	"strings"
-
 	"github.com/hashicorp/hcl-lang/lang"
 	"github.com/hashicorp/hcl-lang/schema"
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 )
 
-func (d *Decoder) bodySchemaCandidates(body *hclsyntax.Body, schema *schema.BodySchema, prefixRng, editRng hcl.Range) lang.Candidates {
+func (d *Decoder) bodySchemaCandidates(body *hclsyntax.Body, schema *schema.LayeredBodySchema, prefixRng, editRng hcl.Range, req candidatesRequest) lang.Candidates {
 	prefix, _ := d.bytesFromRange(prefixRng)
 
-	candidates := lang.NewCandidates()
-	count := 0
+	ranked := make([]rankedCandidate, 0)
 
-	if len(schema.Attributes) > 0 {
-		attrNames := sortedAttributeNames(schema.Attributes)
+	attrNames := schema.AttributeNames()
+	if len(attrNames) > 0 {
 		for _, name := range attrNames {
-			attr := schema.Attributes[name]
+			name := name
+			attr, _ := schema.Attribute(name)
 
 			if !isAttributeDeclarable(body, name, attr) {
 				continue
 			}
-			if len(prefix) > 0 && !strings.HasPrefix(name, string(prefix)) {
+			score, ok := fuzzyMatchScore(string(prefix), name)
+			if !ok {
 				continue
 			}
-			if uint(count) >= d.maxCandidates {
-				return candidates
-			}
 
-			candidates.List = append(candidates.List, attributeSchemaToCandidate(name, attr, editRng))
-			count++
-		}
-	} else if attr := schema.AnyAttribute; attr != nil && len(prefix) == 0 {
-		if uint(count) >= d.maxCandidates {
-			return candidates
+			ranked = append(ranked, rankedCandidate{
+				label: name,
+				score: score + metadataScore(attr.IsRequired, attr.IsDeprecated),
+				candidate: func() lang.Candidate {
+					return attributeSchemaToCandidate(name, attr, editRng)
+				},
+			})
 		}
-
-		candidates.List = append(candidates.List, attributeSchemaToCandidate("name", attr, editRng))
-		count++
+	} else if attr := schema.AnyAttribute(); attr != nil && len(prefix) == 0 {
+		ranked = append(ranked, rankedCandidate{
+			label: "name",
+			score: metadataScore(false, attr.IsDeprecated),
+			candidate: func() lang.Candidate {
+				return attributeSchemaToCandidate("name", attr, editRng)
+			},
+		})
 	}
 
-	blockTypes := sortedBlockTypes(schema.Blocks)
+	blockTypes := schema.BlockTypes()
 	for _, bType := range blockTypes {
-		block := schema.Blocks[bType]
+		bType := bType
+		block, _ := schema.Block(bType)
 
 		if !isBlockDeclarable(body, bType, block) {
 			continue
 		}
-		if len(prefix) > 0 && !strings.HasPrefix(bType, string(prefix)) {
+		score, ok := fuzzyMatchScore(string(prefix), bType)
+		if !ok {
 			continue
 		}
-		if uint(count) >= d.maxCandidates {
-			return candidates
-		}
-
-		candidates.List = append(candidates.List, blockSchemaToCandidate(bType, block, editRng))
-		count++
-	}
-
-	candidates.IsComplete = true
-
-	// TODO: sort by more metadata, such as IsRequired or IsDeprecated
-	sort.Slice(candidates.List, func(i, j int) bool {
-		return candidates.List[i].Label < candidates.List[j].Label
-	})
 
-	return candidates
-}
-
-func sortedAttributeNames(attrs map[string]*schema.AttributeSchema) []string {
-	names := make([]string, len(attrs))
-	i := 0
-	for name := range attrs {
-		names[i] = name
-		i++
+		ranked = append(ranked, rankedCandidate{
+			label: bType,
+			score: score + metadataScore(isBlockRequired(body, bType, block), block.IsDeprecated),
+			candidate: func() lang.Candidate {
+				return blockSchemaToCandidate(bType, block, editRng)
+			},
+		})
 	}
-	sort.Strings(names)
-	return names
-}
 
-func sortedBlockTypes(blocks map[string]*schema.BlockSchema) []string {
-	bTypes := make([]string, len(blocks))
-	i := 0
-	for bType := range blocks {
-		bTypes[i] = bType
-		i++
-	}
-	sort.Strings(bTypes)
-	return bTypes
+	return rankCandidates(ranked, req)
 }
 
 func isAttributeDeclarable(body *hclsyntax.Body, name string, attr *schema.AttributeSchema) bool {
@@ -123,3 +99,19 @@ func isBlockDeclarable(body *hclsyntax.Body, blockType string, bSchema *schema.B
 	}
 	return true
 }
+
+// isBlockRequired returns true if fewer blocks of the given type
+// are declared in the body than the schema requires
+func isBlockRequired(body *hclsyntax.Body, blockType string, bSchema *schema.BlockSchema) bool {
+	if bSchema.MinItems == 0 {
+		return false
+	}
+
+	itemCount := uint64(0)
+	for _, block := range body.Blocks {
+		if block.Type == blockType {
+			itemCount++
+		}
+	}
+	return itemCount < bSchema.MinItems
+}
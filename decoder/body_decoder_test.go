@@ -31,7 +31,7 @@ func TestDecoder_CandidateAtPos_incompleteAttributes(t *testing.T) {
 	}
 
 	d := NewDecoder()
-	d.maxCandidates = 1
+	d.SetMaxCandidates(1)
 
 	d.SetSchema(bodySchema)
 
@@ -74,14 +74,27 @@ func TestDecoder_CandidateAtPos_incompleteAttributes(t *testing.T) {
 					NewText: "attr1",
 					Snippet: "attr1 = ${1:1}",
 				},
-				Kind: lang.AttributeCandidateKind,
+				Kind:       lang.AttributeCandidateKind,
+				SortText:   "0",
+				FilterText: "attr1",
 			},
 		},
 		IsComplete: false,
 	}
-	if diff := cmp.Diff(expectedCandidates, candidates); diff != "" {
+	if diff := cmp.Diff(expectedCandidates, candidates, ignoreContinuationKey); diff != "" {
 		t.Fatalf("unexpected candidates: %s", diff)
 	}
+
+	expectedPageKey := candidatesPageKey{
+		Filename: "test.tf",
+		Line:     2,
+		Column:   7,
+		Byte:     29,
+		Offset:   1,
+	}
+	if diff := cmp.Diff(expectedPageKey, decodePageKey(t, candidates.ContinuationKey)); diff != "" {
+		t.Fatalf("unexpected continuation key: %s", diff)
+	}
 }
 
 func TestDecoder_CandidateAtPos_computedAttributes(t *testing.T) {
@@ -123,6 +136,9 @@ func TestDecoder_CandidateAtPos_computedAttributes(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	// attributes are matched as a subsequence, so "attr" also matches
+	// another_attr and some_other_attr, ranked below the prefix match
+	// (computed-only attr1 is never offered)
 	expectedCandidates := lang.Candidates{
 		List: []lang.Candidate{
 			{
@@ -145,7 +161,57 @@ func TestDecoder_CandidateAtPos_computedAttributes(t *testing.T) {
 					NewText: "attr2",
 					Snippet: "attr2 = ${1:1}",
 				},
-				Kind: lang.AttributeCandidateKind,
+				Kind:       lang.AttributeCandidateKind,
+				SortText:   "0",
+				FilterText: "attr2",
+			},
+			{
+				Label:  "another_attr",
+				Detail: "Optional, number",
+				TextEdit: lang.TextEdit{
+					Range: hcl.Range{
+						Filename: "test.tf",
+						Start: hcl.Pos{
+							Line:   2,
+							Column: 3,
+							Byte:   25,
+						},
+						End: hcl.Pos{
+							Line:   2,
+							Column: 7,
+							Byte:   29,
+						},
+					},
+					NewText: "another_attr",
+					Snippet: "another_attr = ${1:1}",
+				},
+				Kind:       lang.AttributeCandidateKind,
+				SortText:   "1",
+				FilterText: "another_attr",
+			},
+			{
+				Label:  "some_other_attr",
+				Detail: "Optional, number",
+				TextEdit: lang.TextEdit{
+					Range: hcl.Range{
+						Filename: "test.tf",
+						Start: hcl.Pos{
+							Line:   2,
+							Column: 3,
+							Byte:   25,
+						},
+						End: hcl.Pos{
+							Line:   2,
+							Column: 7,
+							Byte:   29,
+						},
+					},
+					NewText: "some_other_attr",
+					Snippet: "some_other_attr = ${1:1}",
+				},
+				Kind:       lang.AttributeCandidateKind,
+				SortText:   "2",
+				FilterText: "some_other_attr",
 			},
 		},
 		IsComplete: true,
@@ -176,7 +242,7 @@ func TestDecoder_CandidateAtPos_incompleteBlocks(t *testing.T) {
 	}
 
 	d := NewDecoder()
-	d.maxCandidates = 1
+	d.SetMaxCandidates(1)
 
 	d.SetSchema(bodySchema)
 
@@ -220,12 +286,25 @@ func TestDecoder_CandidateAtPos_incompleteBlocks(t *testing.T) {
 					NewText: "block2",
 					Snippet: "block2 {\n  ${1}\n}",
 				},
-				Kind: lang.BlockCandidateKind,
+				Kind:       lang.BlockCandidateKind,
+				SortText:   "0",
+				FilterText: "block2",
 			},
 		},
 		IsComplete: false,
 	}
-	if diff := cmp.Diff(expectedCandidates, candidates); diff != "" {
+	if diff := cmp.Diff(expectedCandidates, candidates, ignoreContinuationKey); diff != "" {
 		t.Fatalf("unexpected candidates: %s", diff)
 	}
+
+	expectedPageKey := candidatesPageKey{
+		Filename: "test.tf",
+		Line:     3,
+		Column:   8,
+		Byte:     42,
+		Offset:   1,
+	}
+	if diff := cmp.Diff(expectedPageKey, decodePageKey(t, candidates.ContinuationKey)); diff != "" {
+		t.Fatalf("unexpected continuation key: %s", diff)
+	}
 }
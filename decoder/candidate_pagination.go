@@ -0,0 +1,126 @@
+package decoder
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// SetMaxCandidates sets the maximum number of candidates returned
+// at once by CandidatesAtPos and CandidatesContinuation.
+//
+// Zero means no limit. The default limit is 100.
+func (d *Decoder) SetMaxCandidates(max uint) {
+	d.rootSchemaMu.Lock()
+	defer d.rootSchemaMu.Unlock()
+
+	d.maxCandidates = max
+}
+
+// candidatesPageKey identifies the next page of candidates
+// at the position at which completion was requested
+type candidatesPageKey struct {
+	Filename string `json:"file"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Byte     int    `json:"byte"`
+	Offset   int    `json:"offset"`
+}
+
+func (k candidatesPageKey) pos() hcl.Pos {
+	return hcl.Pos{Line: k.Line, Column: k.Column, Byte: k.Byte}
+}
+
+// CandidatesContinuation returns the next page of candidates
+// of an incomplete list previously returned by CandidatesAtPos
+// (or CandidatesContinuation), identified by its ContinuationKey.
+//
+// Candidates are ordered deterministically, such that pages
+// do not overlap unless the file or schema has changed since.
+func (d *Decoder) CandidatesContinuation(key string) (lang.Candidates, error) {
+	var pk candidatesPageKey
+	err := decodeOpaqueKey(key, &pk)
+	if err != nil {
+		return lang.ZeroCandidates(), fmt.Errorf("invalid continuation key: %w", err)
+	}
+	if pk.Offset < 0 {
+		return lang.ZeroCandidates(), fmt.Errorf("invalid continuation key: negative offset (%d)", pk.Offset)
+	}
+
+	return d.candidatesPage(pk.Filename, pk.pos(), pk.Offset)
+}
+
+// candidatesRequest represents the page of candidates requested
+// at a position, such that candidates outside of it are not built
+type candidatesRequest struct {
+	// offset is the number of (ordered) candidates to skip
+	offset int
+
+	// max is the maximum number of candidates, zero means no limit
+	max uint
+
+	// omitDetails means that details which can be resolved
+	// later (see UseCandidateResolve) are not built
+	omitDetails bool
+
+	// label (if set) restricts candidates to those with the label,
+	// i.e. when resolving a particular candidate
+	label string
+
+	// tokens of the file, which is only lexed once per request
+	// (empty if it could not be lexed without errors)
+	tokens hclsyntax.Tokens
+}
+
+// isRequested returns true if candidate with the given label
+// is requested (i.e. it's not filtered out by label)
+func (r candidatesRequest) isRequested(label string) bool {
+	return r.label == "" || r.label == label
+}
+
+// isFull returns true if the given number of candidates (in order)
+// reaches past the requested page, i.e. there are more candidates
+// than can be returned
+func (r candidatesRequest) isFull(count int) bool {
+	return r.max > 0 && count >= r.offset+int(r.max)
+}
+
+// candidatesPage returns up to maxCandidates candidates
+// at the given position, starting at the given offset
+func (d *Decoder) candidatesPage(filename string, pos hcl.Pos, offset int) (lang.Candidates, error) {
+	d.rootSchemaMu.RLock()
+	maxCandidates, useResolve := d.maxCandidates, d.useCandidateResolve
+	d.rootSchemaMu.RUnlock()
+
+	req := candidatesRequest{
+		offset:      offset,
+		max:         maxCandidates,
+		omitDetails: useResolve,
+	}
+	candidates, err := d.resolvedCandidatesAtPos(filename, pos, req)
+	if err != nil {
+		return candidates, err
+	}
+
+	if !candidates.IsComplete {
+		key, err := encodeOpaqueKey(candidatesPageKey{
+			Filename: filename,
+			Line:     pos.Line,
+			Column:   pos.Column,
+			Byte:     pos.Byte,
+			Offset:   offset + len(candidates.List),
+		})
+		if err != nil {
+			return candidates, err
+		}
+		candidates.ContinuationKey = key
+	}
+
+	if !useResolve {
+		return candidates, nil
+	}
+
+	return withResolveKeys(candidates, filename, pos)
+}
@@ -0,0 +1,287 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ignoreContinuationKey allows comparing candidates regardless
+// of encoding of the key, which is checked via decodePageKey
+var ignoreContinuationKey = cmpopts.IgnoreFields(lang.Candidates{}, "ContinuationKey")
+
+func decodePageKey(t *testing.T, key string) candidatesPageKey {
+	var pk candidatesPageKey
+	err := decodeOpaqueKey(key, &pk)
+	if err != nil {
+		t.Fatalf("invalid continuation key %q: %s", key, err)
+	}
+	return pk
+}
+
+func TestDecoder_CandidatesContinuation(t *testing.T) {
+	depBodies := make(map[schema.SchemaKey]*schema.BodySchema, 0)
+	expectedLabels := make([]string, 0)
+	for i := 0; i < 7; i++ {
+		value := fmt.Sprintf("type_%d", i)
+		depBodies[schema.NewSchemaKey(schema.DependencyKeys{
+			Labels: []schema.LabelDependent{
+				{Index: 0, Value: value},
+			},
+		})] = &schema.BodySchema{}
+		expectedLabels = append(expectedLabels, value)
+	}
+	bodySchema := &schema.BodySchema{
+		Blocks: map[string]*schema.BlockSchema{
+			"resource": {
+				Labels: []*schema.LabelSchema{
+					{Name: "type", IsDepKey: true},
+				},
+				DependentBody: depBodies,
+			},
+		},
+	}
+
+	d := NewDecoder()
+	d.SetSchema(bodySchema)
+	d.SetMaxCandidates(3)
+
+	f, pDiags := hclsyntax.ParseConfig([]byte(`resource "" {}`), "test.tf", hcl.InitialPos)
+	if len(pDiags) > 0 {
+		t.Fatal(pDiags)
+	}
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	candidates, err := d.CandidatesAtPos("test.tf", hcl.Pos{Line: 1, Column: 11, Byte: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	labels := make([]string, 0)
+	pageSizes := make([]int, 0)
+	for {
+		pageSizes = append(pageSizes, len(candidates.List))
+		for _, c := range candidates.List {
+			labels = append(labels, c.Label)
+		}
+		if candidates.IsComplete {
+			if candidates.ContinuationKey != "" {
+				t.Fatal("expected no continuation key for complete list")
+			}
+			break
+		}
+
+		candidates, err = d.CandidatesContinuation(candidates.ContinuationKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if diff := cmp.Diff([]int{3, 3, 1}, pageSizes); diff != "" {
+		t.Fatalf("unexpected page sizes: %s", diff)
+	}
+	if diff := cmp.Diff(expectedLabels, labels); diff != "" {
+		t.Fatalf("unexpected candidates: %s", diff)
+	}
+}
+
+func TestDecoder_CandidatesContinuation_deprecatedLabels(t *testing.T) {
+	depBodies := make(map[schema.SchemaKey]*schema.BodySchema, 0)
+	for i, value := range []string{"type_a", "type_b", "type_c", "type_d", "type_e"} {
+		depBodies[schema.NewSchemaKey(schema.DependencyKeys{
+			Labels: []schema.LabelDependent{
+				{Index: 0, Value: value},
+			},
+		})] = &schema.BodySchema{IsDeprecated: i == 0}
+	}
+	// more specific body describing the same value is ignored
+	depBodies[schema.NewSchemaKey(schema.DependencyKeys{
+		Labels: []schema.LabelDependent{
+			{Index: 0, Value: "type_b"},
+			{Index: 1, Value: "example"},
+		},
+	})] = &schema.BodySchema{IsDeprecated: true}
+	bodySchema := &schema.BodySchema{
+		Blocks: map[string]*schema.BlockSchema{
+			"resource": {
+				Labels: []*schema.LabelSchema{
+					{Name: "type", IsDepKey: true},
+					{Name: "name", IsDepKey: true},
+				},
+				DependentBody: depBodies,
+			},
+		},
+	}
+
+	d := NewDecoder()
+	d.SetSchema(bodySchema)
+	d.SetMaxCandidates(2)
+
+	f, pDiags := hclsyntax.ParseConfig([]byte(`resource "" "" {}`), "test.tf", hcl.InitialPos)
+	if len(pDiags) > 0 {
+		t.Fatal(pDiags)
+	}
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	candidates, err := d.CandidatesAtPos("test.tf", hcl.Pos{Line: 1, Column: 11, Byte: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pages := make([][]string, 0)
+	for {
+		labels := make([]string, 0)
+		for _, c := range candidates.List {
+			labels = append(labels, c.Label)
+		}
+		pages = append(pages, labels)
+		if candidates.IsComplete {
+			break
+		}
+
+		candidates, err = d.CandidatesContinuation(candidates.ContinuationKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expectedPages := [][]string{
+		{"type_b", "type_c"},
+		{"type_d", "type_e"},
+		{"type_a"},
+	}
+	if diff := cmp.Diff(expectedPages, pages); diff != "" {
+		t.Fatalf("unexpected pages: %s", diff)
+	}
+}
+
+func TestDecoder_CandidatesContinuation_allowedValues(t *testing.T) {
+	allowedValues := make([]schema.AllowedValue, 0)
+	expectedLabels := make([]string, 0)
+	for i := 0; i < 5; i++ {
+		value := fmt.Sprintf("value_%d", i)
+		allowedValues = append(allowedValues, schema.AllowedValue{Value: value})
+		expectedLabels = append(expectedLabels, value)
+	}
+	bodySchema := &schema.BodySchema{
+		Attributes: map[string]*schema.AttributeSchema{
+			"mode": {
+				ValueType:     cty.String,
+				AllowedValues: allowedValues,
+			},
+		},
+	}
+
+	d := NewDecoder()
+	d.SetSchema(bodySchema)
+	d.SetMaxCandidates(2)
+
+	f, pDiags := hclsyntax.ParseConfig([]byte(`mode = ""`), "test.tf", hcl.InitialPos)
+	if len(pDiags) > 0 {
+		t.Fatal(pDiags)
+	}
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	candidates, err := d.CandidatesAtPos("test.tf", hcl.Pos{Line: 1, Column: 9, Byte: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	labels := make([]string, 0)
+	pageSizes := make([]int, 0)
+	for {
+		pageSizes = append(pageSizes, len(candidates.List))
+		for _, c := range candidates.List {
+			labels = append(labels, c.Label)
+		}
+		if candidates.IsComplete {
+			break
+		}
+
+		candidates, err = d.CandidatesContinuation(candidates.ContinuationKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if diff := cmp.Diff([]int{2, 2, 1}, pageSizes); diff != "" {
+		t.Fatalf("unexpected page sizes: %s", diff)
+	}
+	if diff := cmp.Diff(expectedLabels, labels); diff != "" {
+		t.Fatalf("unexpected candidates: %s", diff)
+	}
+}
+
+func TestDecoder_CandidatesAtPos_limitCountsMatchingOnly(t *testing.T) {
+	depBodies := make(map[schema.SchemaKey]*schema.BodySchema, 0)
+	for i := 0; i < 10; i++ {
+		depBodies[schema.NewSchemaKey(schema.DependencyKeys{
+			Labels: []schema.LabelDependent{
+				{Index: 0, Value: fmt.Sprintf("aaa_%d", i)},
+			},
+		})] = &schema.BodySchema{}
+	}
+	depBodies[schema.NewSchemaKey(schema.DependencyKeys{
+		Labels: []schema.LabelDependent{
+			{Index: 0, Value: "zzz"},
+		},
+	})] = &schema.BodySchema{}
+	bodySchema := &schema.BodySchema{
+		Blocks: map[string]*schema.BlockSchema{
+			"resource": {
+				Labels: []*schema.LabelSchema{
+					{Name: "type", IsDepKey: true},
+				},
+				DependentBody: depBodies,
+			},
+		},
+	}
+
+	d := NewDecoder()
+	d.SetSchema(bodySchema)
+	d.SetMaxCandidates(2)
+
+	f, pDiags := hclsyntax.ParseConfig([]byte(`resource "zz" {}`), "test.tf", hcl.InitialPos)
+	if len(pDiags) > 0 {
+		t.Fatal(pDiags)
+	}
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	candidates, err := d.CandidatesAtPos("test.tf", hcl.Pos{Line: 1, Column: 13, Byte: 12})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !candidates.IsComplete {
+		t.Fatal("expected complete list of candidates")
+	}
+	if len(candidates.List) != 1 || candidates.List[0].Label != "zzz" {
+		t.Fatalf("unexpected candidates: %#v", candidates.List)
+	}
+}
+
+func TestDecoder_CandidatesContinuation_invalidKey(t *testing.T) {
+	d := NewDecoder()
+	_, err := d.CandidatesContinuation("not-a-valid-key!")
+	if err == nil {
+		t.Fatal("expected error for invalid key")
+	}
+}
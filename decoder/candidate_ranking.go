@@ -0,0 +1,246 @@
+package decoder
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/hashicorp/hcl-lang/lang"
+)
+
+const (
+	// scores of individual matched characters
+	matchScore            = 1
+	boundaryMatchBonus    = 3
+	consecutiveMatchBonus = 2
+	maxGapPenalty         = 3
+
+	// prefixMatchBonus is added if the text starts with the query
+	prefixMatchBonus = 10
+
+	// metadata-based adjustments
+	requiredBonus     = 5
+	deprecatedPenalty = 20
+
+	// noFuzzyMatch represents no match in the score table
+	noFuzzyMatch = math.MinInt32
+
+	// fuzzyMatchBufferSize is the length of texts which
+	// are scored without allocating the score table
+	fuzzyMatchBufferSize = 64
+)
+
+// rankedCandidate represents a candidate with its relevance score
+type rankedCandidate struct {
+	label string
+	score int
+
+	// candidate builds the candidate, such that only candidates
+	// which are returned are built (there can be thousands of them)
+	candidate func() lang.Candidate
+
+	// details (if set) adds details which can be resolved later
+	// (see UseCandidateResolve) to the candidate
+	details func(c *lang.Candidate)
+}
+
+// fuzzyMatchScore returns a score representing how well the text matches
+// the query as a (case-insensitive) subsequence, e.g. "itype" matches
+// "instance_type". Consecutive matches and matches at the start
+// of words score higher. It returns false if the text doesn't match.
+//
+// The score is computed in O(len(query)*len(text)) time
+// and texts which do not match are rejected in linear time.
+func fuzzyMatchScore(query, text string) (int, bool) {
+	if query == "" {
+		return 0, true
+	}
+	if len(query) > len(text) || !isSubsequenceFold(query, text) {
+		return 0, false
+	}
+
+	// prev[j] represents the best score of matching the query
+	// so far, with the last matched character at text[j]
+	var buf [2 * fuzzyMatchBufferSize]int
+	var prev, cur []int
+	if len(text) <= fuzzyMatchBufferSize {
+		prev = buf[:len(text)]
+		cur = buf[fuzzyMatchBufferSize : fuzzyMatchBufferSize+len(text)]
+	} else {
+		rows := make([]int, 2*len(text))
+		prev, cur = rows[:len(text)], rows[len(text):]
+	}
+
+	for i := 0; i < len(query); i++ {
+		qc := toLowerASCII(query[i])
+		// best score of any match preceding the current character
+		// by more than maxGapPenalty characters, where the gap
+		// penalty no longer depends on the distance
+		farBest := noFuzzyMatch
+
+		for j := 0; j < len(text); j++ {
+			if i > 0 && j > maxGapPenalty && prev[j-maxGapPenalty-1] > farBest {
+				farBest = prev[j-maxGapPenalty-1]
+			}
+
+			cur[j] = noFuzzyMatch
+			if j < i || toLowerASCII(text[j]) != qc {
+				continue
+			}
+			charScore := matchScore
+			if isWordStart(text, j) {
+				charScore += boundaryMatchBonus
+			}
+
+			if i == 0 {
+				cur[j] = charScore - gapPenalty(j)
+				continue
+			}
+
+			best := noFuzzyMatch
+			if prev[j-1] != noFuzzyMatch {
+				best = prev[j-1] + consecutiveMatchBonus
+			}
+			for gap := 1; gap <= maxGapPenalty && j-gap-1 >= 0; gap++ {
+				if prev[j-gap-1] != noFuzzyMatch && prev[j-gap-1]-gap > best {
+					best = prev[j-gap-1] - gap
+				}
+			}
+			if farBest != noFuzzyMatch && farBest-maxGapPenalty > best {
+				best = farBest - maxGapPenalty
+			}
+
+			if best != noFuzzyMatch {
+				cur[j] = best + charScore
+			}
+		}
+
+		prev, cur = cur, prev
+	}
+
+	best := noFuzzyMatch
+	for _, score := range prev {
+		if score > best {
+			best = score
+		}
+	}
+	if best == noFuzzyMatch {
+		return 0, false
+	}
+
+	if hasPrefixFold(text, query) {
+		best += prefixMatchBonus
+	}
+
+	return best, true
+}
+
+// isSubsequenceFold returns true if all characters of the query
+// appear in the text in the same order (ignoring case)
+func isSubsequenceFold(query, text string) bool {
+	i := 0
+	for j := 0; j < len(text) && i < len(query); j++ {
+		if toLowerASCII(text[j]) == toLowerASCII(query[i]) {
+			i++
+		}
+	}
+	return i == len(query)
+}
+
+func hasPrefixFold(text, prefix string) bool {
+	if len(prefix) > len(text) {
+		return false
+	}
+	for i := 0; i < len(prefix); i++ {
+		if toLowerASCII(text[i]) != toLowerASCII(prefix[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func toLowerASCII(c byte) byte {
+	if 'A' <= c && c <= 'Z' {
+		return c + ('a' - 'A')
+	}
+	return c
+}
+
+func isWordStart(text string, idx int) bool {
+	if idx == 0 {
+		return true
+	}
+	switch text[idx-1] {
+	case '_', '-', '.', ' ', '/':
+		return true
+	}
+	return false
+}
+
+func gapPenalty(gap int) int {
+	if gap > maxGapPenalty {
+		return maxGapPenalty
+	}
+	return gap
+}
+
+// metadataScore promotes candidates which are required
+// and demotes deprecated ones
+func metadataScore(isRequired, isDeprecated bool) int {
+	score := 0
+	if isRequired {
+		score += requiredBonus
+	}
+	if isDeprecated {
+		score -= deprecatedPenalty
+	}
+	return score
+}
+
+// rankCandidates orders candidates by score (highest first) and label
+// and returns those within the requested page, with SortText set,
+// such that clients preserve the order, and FilterText (unless already set).
+//
+// The list is marked as incomplete if there are more candidates
+// beyond the page.
+func rankCandidates(ranked []rankedCandidate, req candidatesRequest) lang.Candidates {
+	if req.label != "" {
+		requested := make([]rankedCandidate, 0)
+		for _, rc := range ranked {
+			if req.isRequested(rc.label) {
+				requested = append(requested, rc)
+			}
+		}
+		ranked = requested
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].score != ranked[j].score {
+			return ranked[i].score > ranked[j].score
+		}
+		return ranked[i].label < ranked[j].label
+	})
+
+	candidates := lang.NewCandidates()
+	candidates.IsComplete = true
+
+	width := len(fmt.Sprintf("%d", len(ranked)))
+	for i := req.offset; i < len(ranked); i++ {
+		if req.isFull(i) {
+			candidates.IsComplete = false
+			break
+		}
+
+		c := ranked[i].candidate()
+		if ranked[i].details != nil && !req.omitDetails {
+			ranked[i].details(&c)
+		}
+		c.SortText = fmt.Sprintf("%0*d", width, i)
+		if c.FilterText == "" {
+			c.FilterText = c.Label
+		}
+		candidates.List = append(candidates.List, c)
+	}
+
+	return candidates
+}
@@ -0,0 +1,163 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestFuzzyMatchScore(t *testing.T) {
+	testCases := []struct {
+		query         string
+		text          string
+		expectedMatch bool
+	}{
+		{"", "instance_type", true},
+		{"inst", "instance_type", true},
+		{"itype", "instance_type", true},
+		{"IType", "instance_type", true},
+		{"typ", "instance_type", true},
+		{"tpi", "instance_type", false},
+		{"instance_types", "instance_type", false},
+		{"x", "instance_type", false},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.query), func(t *testing.T) {
+			_, ok := fuzzyMatchScore(tc.query, tc.text)
+			if ok != tc.expectedMatch {
+				t.Fatalf("expected match: %t, given: %t", tc.expectedMatch, ok)
+			}
+		})
+	}
+}
+
+func TestFuzzyMatchScore_ranking(t *testing.T) {
+	testCases := []struct {
+		query  string
+		better string
+		worse  string
+	}{
+		// prefix matches rank above other matches
+		{"in", "instance_type", "ami_instance"},
+		// matches at start of words rank above others
+		{"it", "instance_type", "initial"},
+		// consecutive matches rank above scattered ones
+		{"type", "type_name", "the_yellow_pen_e"},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.query), func(t *testing.T) {
+			betterScore, ok := fuzzyMatchScore(tc.query, tc.better)
+			if !ok {
+				t.Fatalf("expected %q to match %q", tc.query, tc.better)
+			}
+			worseScore, ok := fuzzyMatchScore(tc.query, tc.worse)
+			if !ok {
+				t.Fatalf("expected %q to match %q", tc.query, tc.worse)
+			}
+			if betterScore <= worseScore {
+				t.Fatalf("expected %q (%d) to score higher than %q (%d)",
+					tc.better, betterScore, tc.worse, worseScore)
+			}
+		})
+	}
+}
+
+func TestDecoder_CandidatesAtPos_ranking(t *testing.T) {
+	bodySchema := &schema.BodySchema{
+		Blocks: map[string]*schema.BlockSchema{
+			"resource": {
+				Body: &schema.BodySchema{
+					Attributes: map[string]*schema.AttributeSchema{
+						"ami":               {ValueType: cty.String, IsRequired: true},
+						"instance_type":     {ValueType: cty.String, IsRequired: true},
+						"image_type":        {ValueType: cty.String, IsOptional: true},
+						"instance_name":     {ValueType: cty.String, IsOptional: true},
+						"old_instance_type": {ValueType: cty.String, IsOptional: true, IsDeprecated: true},
+					},
+					Blocks: map[string]*schema.BlockSchema{
+						"network_interface": {MinItems: 1},
+						"ebs_volume":        {},
+						"timeouts":          {IsDeprecated: true},
+					},
+				},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name           string
+		cfg            string
+		pos            hcl.Pos
+		expectedLabels []string
+	}{
+		{
+			"empty prefix",
+			`resource {
+
+}
+`,
+			hcl.Pos{Line: 2, Column: 1, Byte: 11},
+			[]string{
+				"ami",
+				"instance_type",
+				"network_interface",
+				"ebs_volume",
+				"image_type",
+				"instance_name",
+				"old_instance_type",
+				"timeouts",
+			},
+		},
+		{
+			"fuzzy prefix",
+			`resource {
+  itype
+}
+`,
+			hcl.Pos{Line: 2, Column: 8, Byte: 18},
+			[]string{
+				"instance_type",
+				"image_type",
+				"old_instance_type",
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(bodySchema)
+
+			f, _ := hclsyntax.ParseConfig([]byte(tc.cfg), "test.tf", hcl.InitialPos)
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			candidates, err := d.CandidatesAtPos("test.tf", tc.pos)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			labels := make([]string, len(candidates.List))
+			for i, c := range candidates.List {
+				labels[i] = c.Label
+				expectedSortText := fmt.Sprintf("%d", i)
+				if c.SortText != expectedSortText {
+					t.Fatalf("expected SortText %q for %q, given: %q",
+						expectedSortText, c.Label, c.SortText)
+				}
+			}
+			if diff := cmp.Diff(tc.expectedLabels, labels); diff != "" {
+				t.Fatalf("unexpected candidates: %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package decoder
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl/v2"
+)
+
+// UseCandidateResolve enables two-phase completion (e.g. LSP's
+// completionItem/resolve), where CandidatesAtPos returns lightweight
+// candidates without Description, Detail and AdditionalTextEdits,
+// and with ResolveKey, which can be passed to ResolveCandidate
+// to obtain these details for a particular candidate.
+//
+// This reduces size of responses where there are many candidates
+// with large descriptions, such as labels of dependent bodies.
+func (d *Decoder) UseCandidateResolve(use bool) {
+	d.rootSchemaMu.Lock()
+	defer d.rootSchemaMu.Unlock()
+
+	d.useCandidateResolve = use
+}
+
+// candidateResolveKey identifies a candidate by the position
+// at which completion was requested and by its kind and label
+// (e.g. label value of a dependent body).
+//
+// SortText is kept, such that the candidate resolved on its own
+// preserves its order within the original list.
+type candidateResolveKey struct {
+	Filename string             `json:"file"`
+	Line     int                `json:"line"`
+	Column   int                `json:"column"`
+	Byte     int                `json:"byte"`
+	Kind     lang.CandidateKind `json:"kind"`
+	Label    string             `json:"label"`
+	SortText string             `json:"sort,omitempty"`
+}
+
+func (k candidateResolveKey) pos() hcl.Pos {
+	return hcl.Pos{Line: k.Line, Column: k.Column, Byte: k.Byte}
+}
+
+func encodeResolveKey(key candidateResolveKey) (string, error) {
+	return encodeOpaqueKey(key)
+}
+
+func decodeResolveKey(key string) (candidateResolveKey, error) {
+	var rk candidateResolveKey
+	err := decodeOpaqueKey(key, &rk)
+	return rk, err
+}
+
+// encodeOpaqueKey encodes v as a key which clients
+// are expected to pass back without interpreting it
+func encodeOpaqueKey(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func decodeOpaqueKey(key string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(key)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// withResolveKeys strips details from candidates which
+// can be resolved later and sets ResolveKey instead.
+//
+// Expensive details (such as those of label candidates)
+// are not built in the first place (see candidatesRequest).
+func withResolveKeys(candidates lang.Candidates, filename string, pos hcl.Pos) (lang.Candidates, error) {
+	for i, c := range candidates.List {
+		key, err := encodeResolveKey(candidateResolveKey{
+			Filename: filename,
+			Line:     pos.Line,
+			Column:   pos.Column,
+			Byte:     pos.Byte,
+			Kind:     c.Kind,
+			Label:    c.Label,
+			SortText: c.SortText,
+		})
+		if err != nil {
+			return candidates, err
+		}
+
+		c.Description = lang.MarkupContent{}
+		c.Detail = ""
+		c.AdditionalTextEdits = nil
+		c.ResolveKey = key
+		candidates.List[i] = c
+	}
+	return candidates, nil
+}
+
+// ResolveCandidate returns candidate identified by the key
+// (see UseCandidateResolve) with all details.
+//
+// Candidates are resolved against the currently loaded file
+// and schema, i.e. error is returned if the candidate is
+// no longer available at the original position.
+func (d *Decoder) ResolveCandidate(key string) (lang.Candidate, error) {
+	rk, err := decodeResolveKey(key)
+	if err != nil {
+		return lang.Candidate{}, fmt.Errorf("invalid resolve key: %w", err)
+	}
+
+	// only candidates with the label are built, with all details,
+	// e.g. label values are looked up in the dependent body index
+	candidates, err := d.resolvedCandidatesAtPos(rk.Filename, rk.pos(), candidatesRequest{
+		label: rk.Label,
+	})
+	if err != nil {
+		return lang.Candidate{}, err
+	}
+
+	for _, c := range candidates.List {
+		if c.Kind == rk.Kind && c.Label == rk.Label {
+			c.SortText = rk.SortText
+			return c, nil
+		}
+	}
+
+	return lang.Candidate{}, &CandidateNotFoundError{
+		Filename: rk.Filename,
+		Pos:      rk.pos(),
+		Label:    rk.Label,
+	}
+}
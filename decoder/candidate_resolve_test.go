@@ -0,0 +1,141 @@
+package decoder
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestDecoder_ResolveCandidate(t *testing.T) {
+	bodySchema := &schema.BodySchema{
+		Blocks: map[string]*schema.BlockSchema{
+			"resource": {
+				Labels: []*schema.LabelSchema{
+					{Name: "type", IsDepKey: true},
+					{Name: "name"},
+				},
+				DependentBody: map[schema.SchemaKey]*schema.BodySchema{
+					schema.NewSchemaKey(schema.DependencyKeys{
+						Labels: []schema.LabelDependent{
+							{Index: 0, Value: "aws_instance"},
+						},
+					}): {
+						Detail:      "AWS instance",
+						Description: lang.Markdown("Provides an EC2 instance resource"),
+						Attributes: map[string]*schema.AttributeSchema{
+							"ami": {ValueType: cty.String, IsRequired: true},
+						},
+					},
+					// value with the other one as its prefix
+					schema.NewSchemaKey(schema.DependencyKeys{
+						Labels: []schema.LabelDependent{
+							{Index: 0, Value: "aws_instance_profile"},
+						},
+					}): {
+						Detail:      "AWS instance profile",
+						Description: lang.Markdown("Provides an IAM instance profile"),
+						Attributes: map[string]*schema.AttributeSchema{
+							"role": {ValueType: cty.String, IsRequired: true},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	cfg := []byte(`resource "" "example" {
+}
+`)
+	pos := hcl.Pos{Line: 1, Column: 11, Byte: 10}
+
+	d := NewDecoder()
+	d.SetSchema(bodySchema)
+	f, pDiags := hclsyntax.ParseConfig(cfg, "test.tf", hcl.InitialPos)
+	if len(pDiags) > 0 {
+		t.Fatal(pDiags)
+	}
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedCandidates, err := d.CandidatesAtPos("test.tf", pos)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d.UseCandidateResolve(true)
+	candidates, err := d.CandidatesAtPos("test.tf", pos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(candidates.List) != 2 {
+		t.Fatalf("expected 2 candidates, given: %d", len(candidates.List))
+	}
+
+	for i, lightCandidate := range candidates.List {
+		if lightCandidate.ResolveKey == "" {
+			t.Fatal("expected resolve key to be set")
+		}
+		if lightCandidate.Detail != "" || lightCandidate.Description.Value != "" ||
+			len(lightCandidate.AdditionalTextEdits) != 0 {
+			t.Fatalf("expected details to be stripped, given: %#v", lightCandidate)
+		}
+
+		candidate, err := d.ResolveCandidate(lightCandidate.ResolveKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(expectedCandidates.List[i], candidate); diff != "" {
+			t.Fatalf("unexpected resolved candidate: %s", diff)
+		}
+	}
+}
+
+func TestDecoder_ResolveCandidate_errors(t *testing.T) {
+	bodySchema := &schema.BodySchema{
+		Attributes: map[string]*schema.AttributeSchema{
+			"name": {ValueType: cty.String, IsOptional: true},
+		},
+	}
+
+	d := NewDecoder()
+	d.SetSchema(bodySchema)
+	f, pDiags := hclsyntax.ParseConfig([]byte("\n"), "test.tf", hcl.InitialPos)
+	if len(pDiags) > 0 {
+		t.Fatal(pDiags)
+	}
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = d.ResolveCandidate("not-a-valid-key!")
+	if err == nil {
+		t.Fatal("expected error for invalid key")
+	}
+
+	key, err := encodeResolveKey(candidateResolveKey{
+		Filename: "test.tf",
+		Line:     1,
+		Column:   1,
+		Byte:     0,
+		Kind:     lang.AttributeCandidateKind,
+		Label:    "unknown",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = d.ResolveCandidate(key)
+	var nfErr *CandidateNotFoundError
+	if !errors.As(err, &nfErr) {
+		t.Fatalf("expected CandidateNotFoundError, given: %s", fmt.Sprint(err))
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/hashicorp/hcl-lang/schema"
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
 )
 
 // CandidatesAtPos returns completion candidates for a given position in a file
@@ -14,6 +15,11 @@ import (
 // Schema is required in order to return any candidates and method will return
 // error if there isn't one.
 func (d *Decoder) CandidatesAtPos(filename string, pos hcl.Pos) (lang.Candidates, error) {
+	return d.candidatesPage(filename, pos, 0)
+}
+
+// resolvedCandidatesAtPos returns the requested candidates with all details
+func (d *Decoder) resolvedCandidatesAtPos(filename string, pos hcl.Pos, req candidatesRequest) (lang.Candidates, error) {
 	f, err := d.fileByName(filename)
 	if err != nil {
 		return lang.ZeroCandidates(), err
@@ -31,17 +37,56 @@ func (d *Decoder) CandidatesAtPos(filename string, pos hcl.Pos) (lang.Candidates
 		return lang.ZeroCandidates(), &NoSchemaError{}
 	}
 
-	return d.candidatesAtPos(rootBody, d.rootSchema, pos)
+	tokens, diags := hclsyntax.LexConfig(f.Bytes, filename, hcl.InitialPos)
+	if ePos, ok := expressionPosition(tokens, filename, pos); ok {
+		rootSchema := schema.NewLayeredBodySchema(d.rootSchema)
+		if block, attr, bSchema, ok := d.dependentAttributeAtPos(rootBody, rootSchema, pos); ok {
+			return d.attributeCandidatesFromDependentSchema(block, attr, bSchema, pos, req)
+		}
+		return d.expressionPosCandidates(ePos, pos, req), nil
+	}
+	if !diags.HasErrors() {
+		req.tokens = tokens
+	}
+
+	return d.candidatesAtPos(rootBody, schema.NewLayeredBodySchema(d.rootSchema), pos, req)
 }
 
-func (d *Decoder) candidatesAtPos(body *hclsyntax.Body, bodySchema *schema.BodySchema, pos hcl.Pos) (lang.Candidates, error) {
-	if bodySchema == nil {
+func (d *Decoder) candidatesAtPos(body *hclsyntax.Body, bodySchema *schema.LayeredBodySchema, pos hcl.Pos, req candidatesRequest) (lang.Candidates, error) {
+	if bodySchema.IsEmpty() {
 		return lang.ZeroCandidates(), nil
 	}
 
 	filename := body.Range().Filename
 
 	for _, attr := range body.Attributes {
+		if expr, ok := attr.Expr.(*hclsyntax.ObjectConsExpr); ok && isPosInsideObjectCons(expr, pos) {
+			aSchema, ok := bodySchema.AttributeOrAny(attr.Name)
+			if ok {
+				consType := consTypeForAttribute(aSchema)
+				if consType != cty.NilType {
+					return d.objectConsCandidates(expr, consType, aSchema.MapKeys, pos, req)
+				}
+			}
+		}
+		if isPosInsideStringLiteral(attr.Expr, pos) {
+			aSchema, ok := bodySchema.AttributeOrAny(attr.Name)
+			if ok && len(aSchema.AllowedValues) > 0 {
+				rng := hcl.Range{
+					Filename: filename,
+					Start:    pos,
+					End:      pos,
+				}
+				prefixRng := rng
+				tokenRange, err := labelTokenRangeAtPos(req.tokens, pos)
+				if err == nil {
+					rng, prefixRng = tokenRange, tokenRange
+				}
+				prefixRng.End = pos
+
+				return d.allowedValueCandidates(aSchema, prefixRng, rng, req), nil
+			}
+		}
 		if isRightHandSidePos(attr, pos) {
 			// TODO: RHS candidates (requires a form of expression schema)
 			return lang.ZeroCandidates(), &PositionalError{
@@ -53,7 +98,7 @@ func (d *Decoder) candidatesAtPos(body *hclsyntax.Body, bodySchema *schema.BodyS
 		if attr.NameRange.ContainsPos(pos) {
 			prefixRng := attr.NameRange
 			prefixRng.End = pos
-			return d.bodySchemaCandidates(body, bodySchema, prefixRng, attr.Range()), nil
+			return d.bodySchemaCandidates(body, bodySchema, prefixRng, attr.Range(), req), nil
 		}
 	}
 
@@ -65,7 +110,7 @@ func (d *Decoder) candidatesAtPos(body *hclsyntax.Body, bodySchema *schema.BodyS
 
 	for _, block := range body.Blocks {
 		if block.Range().ContainsPos(pos) {
-			bSchema, ok := bodySchema.Blocks[block.Type]
+			bSchema, ok := bodySchema.Block(block.Type)
 			if !ok {
 				return lang.ZeroCandidates(), &PositionalError{
 					Filename: filename,
@@ -77,7 +122,7 @@ func (d *Decoder) candidatesAtPos(body *hclsyntax.Body, bodySchema *schema.BodyS
 			if block.TypeRange.ContainsPos(pos) {
 				prefixRng := block.TypeRange
 				prefixRng.End = pos
-				return d.bodySchemaCandidates(body, bodySchema, prefixRng, block.Range()), nil
+				return d.bodySchemaCandidates(body, bodySchema, prefixRng, block.Range(), req), nil
 			}
 
 			for i, labelRange := range block.LabelRanges {
@@ -91,13 +136,13 @@ func (d *Decoder) candidatesAtPos(body *hclsyntax.Body, bodySchema *schema.BodyS
 					}
 
 					prefixRng := rng
-					tokenRange, err := d.labelTokenRangeAtPos(labelRange.Filename, pos)
+					tokenRange, err := labelTokenRangeAtPos(req.tokens, pos)
 					if err == nil {
 						rng, prefixRng = tokenRange, tokenRange
 					}
 					prefixRng.End = pos
 
-					return d.labelCandidatesFromDependentSchema(i, bSchema.DependentBody, prefixRng, rng)
+					return d.labelCandidatesFromDependentSchema(i, block, bSchema, prefixRng, rng, req)
 				}
 			}
 
@@ -110,22 +155,23 @@ func (d *Decoder) candidatesAtPos(body *hclsyntax.Body, bodySchema *schema.BodyS
 			}
 
 			if block.Body != nil && block.Body.Range().ContainsPos(pos) {
-				mergedSchema, err := mergeBlockBodySchemas(block, bSchema)
-				if err != nil {
-					return lang.ZeroCandidates(), err
+				if attr, ok := depKeyAttributeAtPos(block.Body, bSchema, pos); ok {
+					return d.attributeCandidatesFromDependentSchema(block, attr, bSchema, pos, req)
 				}
 
-				return d.candidatesAtPos(block.Body, mergedSchema, pos)
+				mergedSchema := d.mergeBlockBodySchemas(block, bSchema)
+
+				return d.candidatesAtPos(block.Body, mergedSchema, pos, req)
 			}
 		}
 	}
 
-	tokenRng, err := d.nameTokenRangeAtPos(body.Range().Filename, pos)
+	tokenRng, err := nameTokenRangeAtPos(req.tokens, pos)
 	if err == nil {
 		rng = tokenRng
 	}
 
-	return d.bodySchemaCandidates(body, bodySchema, rng, rng), nil
+	return d.bodySchemaCandidates(body, bodySchema, rng, rng, req), nil
 }
 
 func isRightHandSidePos(attr *hclsyntax.Attribute, pos hcl.Pos) bool {
@@ -147,25 +193,6 @@ func isRightHandSidePos(attr *hclsyntax.Attribute, pos hcl.Pos) bool {
 	return true
 }
 
-func (d *Decoder) nameTokenRangeAtPos(filename string, pos hcl.Pos) (hcl.Range, error) {
-	rng := hcl.Range{
-		Filename: filename,
-		Start:    pos,
-		End:      pos,
-	}
-
-	f, err := d.fileByName(filename)
-	if err != nil {
-		return rng, err
-	}
-	tokens, diags := hclsyntax.LexConfig(f.Bytes, filename, hcl.InitialPos)
-	if diags.HasErrors() {
-		return rng, diags
-	}
-
-	return nameTokenRangeAtPos(tokens, pos)
-}
-
 func nameTokenRangeAtPos(tokens hclsyntax.Tokens, pos hcl.Pos) (hcl.Range, error) {
 	for i, t := range tokens {
 		if t.Range.ContainsPos(pos) {
@@ -194,30 +221,6 @@ func nameTokenRangeAtPos(tokens hclsyntax.Tokens, pos hcl.Pos) (hcl.Range, error
 	return hcl.Range{}, fmt.Errorf("no token found at %s", stringPos(pos))
 }
 
-func (d *Decoder) labelTokenRangeAtPos(filename string, pos hcl.Pos) (hcl.Range, error) {
-	rng := hcl.Range{
-		Filename: filename,
-		Start:    pos,
-		End:      pos,
-	}
-
-	f, err := d.fileByName(filename)
-	if err != nil {
-		return rng, err
-	}
-	tokens, diags := hclsyntax.LexConfig(f.Bytes, filename, hcl.InitialPos)
-	if diags.HasErrors() {
-		return rng, diags
-	}
-
-	prefixRng, err := labelTokenRangeAtPos(tokens, pos)
-	if err != nil {
-		return rng, err
-	}
-
-	return prefixRng, nil
-}
-
 func labelTokenRangeAtPos(tokens hclsyntax.Tokens, pos hcl.Pos) (hcl.Range, error) {
 	for i, t := range tokens {
 		if t.Range.ContainsPos(pos) {
@@ -235,6 +238,25 @@ func labelTokenRangeAtPos(tokens hclsyntax.Tokens, pos hcl.Pos) (hcl.Range, erro
 	return hcl.Range{}, fmt.Errorf("no valid token found at %s", stringPos(pos))
 }
 
+// quotedKeyRangeAtPos returns range of the quoted string
+// (including quotes) if the position is within its quotes
+func quotedKeyRangeAtPos(tokens hclsyntax.Tokens, pos hcl.Pos) (hcl.Range, error) {
+	for i, t := range tokens {
+		if t.Type != hclsyntax.TokenOQuote || t.Range.End.Byte > pos.Byte {
+			continue
+		}
+		j := i + 1
+		if j < len(tokens) && tokens[j].Type == hclsyntax.TokenQuotedLit {
+			j++
+		}
+		if j < len(tokens) && tokens[j].Type == hclsyntax.TokenCQuote &&
+			pos.Byte <= tokens[j].Range.Start.Byte {
+			return hcl.RangeBetween(t.Range, tokens[j].Range), nil
+		}
+	}
+	return hcl.Range{}, fmt.Errorf("no quoted string found at %s", stringPos(pos))
+}
+
 func isPosOutsideBody(block *hclsyntax.Block, pos hcl.Pos) bool {
 	if block.OpenBraceRange.ContainsPos(pos) {
 		return true
@@ -153,7 +153,9 @@ func TestDecoder_CandidatesAtPos_prefixNearEOF(t *testing.T) {
 				NewText: "resource",
 				Snippet: "resource \"${1:type}\" \"${2:name}\" {\n  ${3}\n}",
 			},
-			Kind: lang.BlockCandidateKind,
+			Kind:       lang.BlockCandidateKind,
+			SortText:   "0",
+			FilterText: "resource",
 		},
 	})
 	if diff := cmp.Diff(expectedCandidates, candidates, ctydebug.CmpOptions); diff != "" {
@@ -398,7 +400,9 @@ func TestDecoder_CandidatesAtPos_endOfLabel(t *testing.T) {
 				NewText: "myfirst",
 				Snippet: "myfirst",
 			},
-			Kind: lang.LabelCandidateKind,
+			Kind:       lang.LabelCandidateKind,
+			SortText:   "0",
+			FilterText: "myfirst",
 		},
 		{
 			Label: "mysecond",
@@ -419,7 +423,9 @@ func TestDecoder_CandidatesAtPos_endOfLabel(t *testing.T) {
 				NewText: "mysecond",
 				Snippet: "mysecond",
 			},
-			Kind: lang.LabelCandidateKind,
+			Kind:       lang.LabelCandidateKind,
+			SortText:   "1",
+			FilterText: "mysecond",
 		},
 	})
 	if diff := cmp.Diff(expectedCandidates, candidates); diff != "" {
@@ -476,6 +482,8 @@ func TestDecoder_CandidatesAtPos_zeroByteContent(t *testing.T) {
 			},
 			Kind:           lang.BlockCandidateKind,
 			TriggerSuggest: true,
+			SortText:       "0",
+			FilterText:     "resource",
 		},
 	})
 	if diff := cmp.Diff(expectedCandidates, candidates); diff != "" {
@@ -538,6 +546,8 @@ func TestDecoder_CandidatesAtPos_endOfFilePos(t *testing.T) {
 				},
 				Kind:           lang.BlockCandidateKind,
 				TriggerSuggest: true,
+				SortText:       "0",
+				FilterText:     "resource",
 			},
 		},
 		IsComplete: true,
@@ -620,7 +630,20 @@ func TestDecoder_CandidatesAtPos_emptyLabel(t *testing.T) {
 				NewText: "azurerm_subnet",
 				Snippet: "azurerm_subnet",
 			},
-			Kind: lang.LabelCandidateKind,
+			AdditionalTextEdits: []lang.TextEdit{
+				{
+					Range: hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1, Column: 17, Byte: 16},
+						End:      hcl.Pos{Line: 1, Column: 17, Byte: 16},
+					},
+					NewText: "\n  one = \"value\"",
+					Snippet: "\n  one = \"value\"",
+				},
+			},
+			Kind:       lang.LabelCandidateKind,
+			SortText:   "0",
+			FilterText: "azurerm_subnet",
 		},
 		{
 			Label: "random_resource",
@@ -633,7 +656,9 @@ func TestDecoder_CandidatesAtPos_emptyLabel(t *testing.T) {
 				NewText: "random_resource",
 				Snippet: "random_resource",
 			},
-			Kind: lang.LabelCandidateKind,
+			Kind:       lang.LabelCandidateKind,
+			SortText:   "1",
+			FilterText: "random_resource",
 		},
 	})
 	if diff := cmp.Diff(expectedCandidates, candidates); diff != "" {
@@ -729,6 +754,8 @@ resource "random_resource" "test" {
 					},
 					Kind:           lang.BlockCandidateKind,
 					TriggerSuggest: true,
+					SortText:       "0",
+					FilterText:     "resource",
 				},
 			}),
 		},
@@ -750,6 +777,8 @@ resource "random_resource" "test" {
 					},
 					Kind:           lang.BlockCandidateKind,
 					TriggerSuggest: true,
+					SortText:       "0",
+					FilterText:     "resource",
 				},
 			}),
 		},
@@ -768,7 +797,20 @@ resource "random_resource" "test" {
 						NewText: "azurerm_subnet",
 						Snippet: "azurerm_subnet",
 					},
-					Kind: lang.LabelCandidateKind,
+					AdditionalTextEdits: []lang.TextEdit{
+						{
+							Range: hcl.Range{
+								Filename: "test.tf",
+								Start:    hcl.Pos{Line: 1, Column: 38, Byte: 37},
+								End:      hcl.Pos{Line: 1, Column: 38, Byte: 37},
+							},
+							NewText: "\n  one = \"value\"",
+							Snippet: "\n  one = \"value\"",
+						},
+					},
+					Kind:       lang.LabelCandidateKind,
+					SortText:   "0",
+					FilterText: "azurerm_subnet",
 				},
 				{
 					Label: "random_resource",
@@ -781,7 +823,9 @@ resource "random_resource" "test" {
 						NewText: "random_resource",
 						Snippet: "random_resource",
 					},
-					Kind: lang.LabelCandidateKind,
+					Kind:       lang.LabelCandidateKind,
+					SortText:   "1",
+					FilterText: "random_resource",
 				},
 			}),
 		},
@@ -801,7 +845,9 @@ resource "random_resource" "test" {
 						NewText: "one",
 						Snippet: `one = "${1:value}"`,
 					},
-					Kind: lang.AttributeCandidateKind,
+					Kind:       lang.AttributeCandidateKind,
+					SortText:   "0",
+					FilterText: "one",
 				},
 				{
 					Label:  "three",
@@ -815,7 +861,9 @@ resource "random_resource" "test" {
 						NewText: "three",
 						Snippet: "three = ${1:false}",
 					},
-					Kind: lang.AttributeCandidateKind,
+					Kind:       lang.AttributeCandidateKind,
+					SortText:   "1",
+					FilterText: "three",
 				},
 				{
 					Label:  "two",
@@ -829,7 +877,9 @@ resource "random_resource" "test" {
 						NewText: "two",
 						Snippet: "two = ${1:1}",
 					},
-					Kind: lang.AttributeCandidateKind,
+					Kind:       lang.AttributeCandidateKind,
+					SortText:   "2",
+					FilterText: "two",
 				},
 			}),
 		},
@@ -849,7 +899,9 @@ resource "random_resource" "test" {
 						NewText: "one",
 						Snippet: `one = "${1:value}"`,
 					},
-					Kind: lang.AttributeCandidateKind,
+					Kind:       lang.AttributeCandidateKind,
+					SortText:   "0",
+					FilterText: "one",
 				},
 				{
 					Label:  "three",
@@ -863,7 +915,9 @@ resource "random_resource" "test" {
 						NewText: "three",
 						Snippet: "three = ${1:false}",
 					},
-					Kind: lang.AttributeCandidateKind,
+					Kind:       lang.AttributeCandidateKind,
+					SortText:   "1",
+					FilterText: "three",
 				},
 				{
 					Label:  "two",
@@ -877,7 +931,9 @@ resource "random_resource" "test" {
 						NewText: "two",
 						Snippet: "two = ${1:1}",
 					},
-					Kind: lang.AttributeCandidateKind,
+					Kind:       lang.AttributeCandidateKind,
+					SortText:   "2",
+					FilterText: "two",
 				},
 			}),
 		},
@@ -952,7 +1008,9 @@ func TestDecoder_CandidatesAtPos_AnyAttribute(t *testing.T) {
 				NewText: "name",
 				Snippet: "name = {\n  source = \"${1:value}\"\n  version = \"${2:value}\"\n}",
 			},
-			Kind: lang.AttributeCandidateKind,
+			Kind:       lang.AttributeCandidateKind,
+			SortText:   "0",
+			FilterText: "name",
 		},
 	})
 
@@ -1023,7 +1081,9 @@ func TestDecoder_CandidatesAtPos_multipleTypes(t *testing.T) {
 				NewText: "for_each",
 				Snippet: "for_each = [ ${1} ]",
 			},
-			Kind: lang.AttributeCandidateKind,
+			Kind:       lang.AttributeCandidateKind,
+			SortText:   "0",
+			FilterText: "for_each",
 		},
 	})
 
@@ -1082,7 +1142,9 @@ res
 						NewText: "resource",
 						Snippet: "resource \"${1:type}\" \"${2:name}\" {\n  ${3}\n}",
 					},
-					Kind: lang.BlockCandidateKind,
+					Kind:       lang.BlockCandidateKind,
+					SortText:   "0",
+					FilterText: "resource",
 				},
 			}),
 		},
@@ -1107,7 +1169,9 @@ resource "any" "ref" {
 						NewText: "count",
 						Snippet: "count = ${1:1}",
 					},
-					Kind: lang.AttributeCandidateKind,
+					Kind:       lang.AttributeCandidateKind,
+					SortText:   "0",
+					FilterText: "count",
 				},
 			}),
 		},
@@ -1132,7 +1196,9 @@ resource "any" "ref" {
 						NewText: "count",
 						Snippet: "count = ${1:1}",
 					},
-					Kind: lang.AttributeCandidateKind,
+					Kind:       lang.AttributeCandidateKind,
+					SortText:   "0",
+					FilterText: "count",
 				},
 			}),
 		},
@@ -1222,7 +1288,9 @@ res
 						NewText: "resource",
 						Snippet: "resource \"${1:type}\" \"${2:name}\" {\n  ${3}\n}",
 					},
-					Kind: lang.BlockCandidateKind,
+					Kind:       lang.BlockCandidateKind,
+					SortText:   "0",
+					FilterText: "resource",
 				},
 			}),
 		},
@@ -1247,7 +1315,9 @@ resource "any" "ref" {
 						NewText: "count",
 						Snippet: "count = ${1:1}",
 					},
-					Kind: lang.AttributeCandidateKind,
+					Kind:       lang.AttributeCandidateKind,
+					SortText:   "0",
+					FilterText: "count",
 				},
 			}),
 		},
@@ -1272,7 +1342,9 @@ resource "any" "ref" {
 						NewText: "count",
 						Snippet: "count = ${1:1}",
 					},
-					Kind: lang.AttributeCandidateKind,
+					Kind:       lang.AttributeCandidateKind,
+					SortText:   "0",
+					FilterText: "count",
 				},
 			}),
 		},
@@ -1309,3 +1381,770 @@ resource "random_resource" "test" {
   arg = ""
 }
 `)
+
+func TestDecoder_CandidatesAtPos_objectConsExpr(t *testing.T) {
+	bodySchema := &schema.BodySchema{
+		Blocks: map[string]*schema.BlockSchema{
+			"myblock": {
+				Body: &schema.BodySchema{
+					Attributes: map[string]*schema.AttributeSchema{
+						"obj": {
+							ValueType: cty.Object(map[string]cty.Type{
+								"first":  cty.String,
+								"second": cty.Number,
+								"nested": cty.Object(map[string]cty.Type{
+									"flag": cty.Bool,
+								}),
+							}),
+						},
+						"num_attr": {ValueType: cty.Number},
+					},
+				},
+			},
+		},
+	}
+	testConfig := []byte(`myblock {
+  obj = {
+    first = "foo"
+    nested = {
+      
+    }
+    se
+  }
+}
+`)
+
+	d := NewDecoder()
+	d.SetSchema(bodySchema)
+	f, _ := hclsyntax.ParseConfig(testConfig, "test.tf", hcl.InitialPos)
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name               string
+		pos                hcl.Pos
+		expectedCandidates lang.Candidates
+	}{
+		{
+			"attribute prefix",
+			hcl.Pos{Line: 7, Column: 7, Byte: 72},
+			lang.CompleteCandidates([]lang.Candidate{
+				{
+					Label:  "second",
+					Detail: "number",
+					TextEdit: lang.TextEdit{
+						Range: hcl.Range{
+							Filename: "test.tf",
+							Start:    hcl.Pos{Line: 7, Column: 5, Byte: 70},
+							End:      hcl.Pos{Line: 7, Column: 7, Byte: 72},
+						},
+						NewText: "second",
+						Snippet: "second = ${1:1}",
+					},
+					Kind: lang.AttributeCandidateKind,
+				},
+			}),
+		},
+		{
+			"nested object",
+			hcl.Pos{Line: 5, Column: 7, Byte: 59},
+			lang.CompleteCandidates([]lang.Candidate{
+				{
+					Label:  "flag",
+					Detail: "bool",
+					TextEdit: lang.TextEdit{
+						Range: hcl.Range{
+							Filename: "test.tf",
+							Start:    hcl.Pos{Line: 5, Column: 7, Byte: 59},
+							End:      hcl.Pos{Line: 5, Column: 7, Byte: 59},
+						},
+						NewText: "flag",
+						Snippet: "flag = ${1:false}",
+					},
+					Kind: lang.AttributeCandidateKind,
+				},
+			}),
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			candidates, err := d.CandidatesAtPos("test.tf", tc.pos)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expectedCandidates, candidates); diff != "" {
+				t.Fatalf("unexpected candidates: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecoder_CandidatesAtPos_mapKeys(t *testing.T) {
+	bodySchema := &schema.BodySchema{
+		Attributes: map[string]*schema.AttributeSchema{
+			"tags": {
+				ValueType:  cty.Map(cty.String),
+				IsOptional: true,
+				MapKeys: map[string]*schema.MapKeySchema{
+					"Owner": {
+						Description: lang.PlainText("Owner of the resource"),
+						IsRequired:  true,
+					},
+					"CostCenter":  {IsRequired: true},
+					"cost center": {IsDeprecated: true},
+				},
+			},
+		},
+	}
+	testConfig := []byte(`tags = {
+  CostCenter = "42"
+  
+}
+`)
+
+	d := NewDecoder()
+	d.SetSchema(bodySchema)
+	f, pDiags := hclsyntax.ParseConfig(testConfig, "test.tf", hcl.InitialPos)
+	if len(pDiags) > 0 {
+		t.Fatal(pDiags)
+	}
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	candidates, err := d.CandidatesAtPos("test.tf", hcl.Pos{Line: 3, Column: 3, Byte: 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rng := hcl.Range{
+		Filename: "test.tf",
+		Start:    hcl.Pos{Line: 3, Column: 3, Byte: 30},
+		End:      hcl.Pos{Line: 3, Column: 3, Byte: 30},
+	}
+	expectedCandidates := lang.CompleteCandidates([]lang.Candidate{
+		{
+			Label:       "Owner",
+			Detail:      "Required, string",
+			Description: lang.PlainText("Owner of the resource"),
+			TextEdit: lang.TextEdit{
+				Range:   rng,
+				NewText: "Owner",
+				Snippet: `Owner = "${1:value}"`,
+			},
+			Kind: lang.AttributeCandidateKind,
+		},
+		{
+			Label:        "cost center",
+			Detail:       "Optional, string",
+			IsDeprecated: true,
+			TextEdit: lang.TextEdit{
+				Range:   rng,
+				NewText: `"cost center"`,
+				Snippet: `"cost center" = "${1:value}"`,
+			},
+			Kind: lang.AttributeCandidateKind,
+		},
+	})
+	if diff := cmp.Diff(expectedCandidates, candidates); diff != "" {
+		t.Fatalf("unexpected candidates: %s", diff)
+	}
+}
+
+func TestDecoder_CandidatesAtPos_quotedMapKeys(t *testing.T) {
+	bodySchema := &schema.BodySchema{
+		Attributes: map[string]*schema.AttributeSchema{
+			"tags": {
+				ValueType:  cty.Map(cty.String),
+				IsOptional: true,
+				MapKeys: map[string]*schema.MapKeySchema{
+					"Owner":       {IsRequired: true},
+					"cost center": {},
+				},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name               string
+		cfg                string
+		pos                hcl.Pos
+		expectedCandidates lang.Candidates
+	}{
+		{
+			"prefix within quotes",
+			`tags = {
+  "Ow"
+}
+`,
+			hcl.Pos{Line: 2, Column: 6, Byte: 14},
+			lang.CompleteCandidates([]lang.Candidate{
+				{
+					Label:  "Owner",
+					Detail: "Required, string",
+					TextEdit: lang.TextEdit{
+						Range: hcl.Range{
+							Filename: "test.tf",
+							Start:    hcl.Pos{Line: 2, Column: 3, Byte: 11},
+							End:      hcl.Pos{Line: 2, Column: 7, Byte: 15},
+						},
+						NewText: `"Owner"`,
+						Snippet: `"Owner" = "${1:value}"`,
+					},
+					Kind:       lang.AttributeCandidateKind,
+					FilterText: `"Owner"`,
+				},
+			}),
+		},
+		{
+			"empty quotes",
+			`tags = {
+  ""
+}
+`,
+			hcl.Pos{Line: 2, Column: 4, Byte: 12},
+			lang.CompleteCandidates([]lang.Candidate{
+				{
+					Label:  "Owner",
+					Detail: "Required, string",
+					TextEdit: lang.TextEdit{
+						Range: hcl.Range{
+							Filename: "test.tf",
+							Start:    hcl.Pos{Line: 2, Column: 3, Byte: 11},
+							End:      hcl.Pos{Line: 2, Column: 5, Byte: 13},
+						},
+						NewText: `"Owner"`,
+						Snippet: `"Owner" = "${1:value}"`,
+					},
+					Kind:       lang.AttributeCandidateKind,
+					FilterText: `"Owner"`,
+				},
+				{
+					Label:  "cost center",
+					Detail: "Optional, string",
+					TextEdit: lang.TextEdit{
+						Range: hcl.Range{
+							Filename: "test.tf",
+							Start:    hcl.Pos{Line: 2, Column: 3, Byte: 11},
+							End:      hcl.Pos{Line: 2, Column: 5, Byte: 13},
+						},
+						NewText: `"cost center"`,
+						Snippet: `"cost center" = "${1:value}"`,
+					},
+					Kind:       lang.AttributeCandidateKind,
+					FilterText: `"cost center"`,
+				},
+			}),
+		},
+		{
+			"declared quoted key",
+			`tags = {
+  "cost" = "42"
+}
+`,
+			hcl.Pos{Line: 2, Column: 8, Byte: 16},
+			lang.CompleteCandidates([]lang.Candidate{
+				{
+					Label:  "cost center",
+					Detail: "Optional, string",
+					TextEdit: lang.TextEdit{
+						Range: hcl.Range{
+							Filename: "test.tf",
+							Start:    hcl.Pos{Line: 2, Column: 3, Byte: 11},
+							End:      hcl.Pos{Line: 2, Column: 9, Byte: 17},
+						},
+						NewText: `"cost center"`,
+						Snippet: `"cost center" = "${1:value}"`,
+					},
+					Kind:       lang.AttributeCandidateKind,
+					FilterText: `"cost center"`,
+				},
+			}),
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(bodySchema)
+			f, _ := hclsyntax.ParseConfig([]byte(tc.cfg), "test.tf", hcl.InitialPos)
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			candidates, err := d.CandidatesAtPos("test.tf", tc.pos)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expectedCandidates, candidates); diff != "" {
+				t.Fatalf("unexpected candidates: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecoder_CandidatesAtPos_allowedValues(t *testing.T) {
+	bodySchema := &schema.BodySchema{
+		Attributes: map[string]*schema.AttributeSchema{
+			"tier": {
+				ValueType:  cty.String,
+				IsOptional: true,
+				AllowedValues: []schema.AllowedValue{
+					{Value: "Basic", Description: lang.PlainText("Basic tier")},
+					{Value: "Standard"},
+					{Value: "Premium", IsDeprecated: true},
+				},
+			},
+		},
+	}
+	testConfig := []byte(`tier = "S"
+`)
+
+	d := NewDecoder()
+	d.SetSchema(bodySchema)
+	f, pDiags := hclsyntax.ParseConfig(testConfig, "test.tf", hcl.InitialPos)
+	if len(pDiags) > 0 {
+		t.Fatal(pDiags)
+	}
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name               string
+		pos                hcl.Pos
+		expectedCandidates lang.Candidates
+	}{
+		{
+			"empty prefix",
+			hcl.Pos{Line: 1, Column: 9, Byte: 8},
+			lang.CompleteCandidates([]lang.Candidate{
+				{
+					Label:       "Basic",
+					Detail:      "string",
+					Description: lang.PlainText("Basic tier"),
+					TextEdit: lang.TextEdit{
+						Range: hcl.Range{
+							Filename: "test.tf",
+							Start:    hcl.Pos{Line: 1, Column: 9, Byte: 8},
+							End:      hcl.Pos{Line: 1, Column: 10, Byte: 9},
+						},
+						NewText: "Basic",
+						Snippet: "Basic",
+					},
+					Kind: lang.ValueCandidateKind,
+				},
+				{
+					Label:  "Standard",
+					Detail: "string",
+					TextEdit: lang.TextEdit{
+						Range: hcl.Range{
+							Filename: "test.tf",
+							Start:    hcl.Pos{Line: 1, Column: 9, Byte: 8},
+							End:      hcl.Pos{Line: 1, Column: 10, Byte: 9},
+						},
+						NewText: "Standard",
+						Snippet: "Standard",
+					},
+					Kind: lang.ValueCandidateKind,
+				},
+				{
+					Label:        "Premium",
+					Detail:       "string",
+					IsDeprecated: true,
+					TextEdit: lang.TextEdit{
+						Range: hcl.Range{
+							Filename: "test.tf",
+							Start:    hcl.Pos{Line: 1, Column: 9, Byte: 8},
+							End:      hcl.Pos{Line: 1, Column: 10, Byte: 9},
+						},
+						NewText: "Premium",
+						Snippet: "Premium",
+					},
+					Kind: lang.ValueCandidateKind,
+				},
+			}),
+		},
+		{
+			"with prefix",
+			hcl.Pos{Line: 1, Column: 10, Byte: 9},
+			lang.CompleteCandidates([]lang.Candidate{
+				{
+					Label:  "Standard",
+					Detail: "string",
+					TextEdit: lang.TextEdit{
+						Range: hcl.Range{
+							Filename: "test.tf",
+							Start:    hcl.Pos{Line: 1, Column: 9, Byte: 8},
+							End:      hcl.Pos{Line: 1, Column: 10, Byte: 9},
+						},
+						NewText: "Standard",
+						Snippet: "Standard",
+					},
+					Kind: lang.ValueCandidateKind,
+				},
+			}),
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			candidates, err := d.CandidatesAtPos("test.tf", tc.pos)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expectedCandidates, candidates); diff != "" {
+				t.Fatalf("unexpected candidates: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecoder_CandidatesAtPos_allowedValuesAnyAttribute(t *testing.T) {
+	bodySchema := &schema.BodySchema{
+		AnyAttribute: &schema.AttributeSchema{
+			ValueType:  cty.String,
+			IsOptional: true,
+			AllowedValues: []schema.AllowedValue{
+				{Value: "enabled"},
+				{Value: "disabled"},
+			},
+		},
+	}
+
+	d := NewDecoder()
+	d.SetSchema(bodySchema)
+	f, pDiags := hclsyntax.ParseConfig([]byte(`feature = "d"
+`), "test.tf", hcl.InitialPos)
+	if len(pDiags) > 0 {
+		t.Fatal(pDiags)
+	}
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	candidates, err := d.CandidatesAtPos("test.tf", hcl.Pos{Line: 1, Column: 13, Byte: 12})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedCandidates := lang.CompleteCandidates([]lang.Candidate{
+		{
+			Label:  "disabled",
+			Detail: "string",
+			TextEdit: lang.TextEdit{
+				Range: hcl.Range{
+					Filename: "test.tf",
+					Start:    hcl.Pos{Line: 1, Column: 12, Byte: 11},
+					End:      hcl.Pos{Line: 1, Column: 13, Byte: 12},
+				},
+				NewText: "disabled",
+				Snippet: "disabled",
+			},
+			Kind: lang.ValueCandidateKind,
+		},
+	})
+	if diff := cmp.Diff(expectedCandidates, candidates); diff != "" {
+		t.Fatalf("unexpected candidates: %s", diff)
+	}
+}
+
+func TestDecoder_CandidatesAtPos_dependentAttributeValue(t *testing.T) {
+	resourceSchema := &schema.BlockSchema{
+		Labels: []*schema.LabelSchema{
+			{Name: "type", IsDepKey: true},
+			{Name: "name"},
+		},
+		Body: &schema.BodySchema{
+			Attributes: map[string]*schema.AttributeSchema{
+				"provider": {ValueType: cty.DynamicPseudoType, IsOptional: true, IsDepKey: true},
+				"mode":     {ValueType: cty.String, IsOptional: true, IsDepKey: true},
+			},
+		},
+		DependentBody: map[schema.SchemaKey]*schema.BodySchema{
+			schema.NewSchemaKey(schema.DependencyKeys{
+				Labels: []schema.LabelDependent{
+					{Index: 0, Value: "aws_instance"},
+				},
+				Attributes: []schema.AttributeDependent{
+					{
+						Name: "provider",
+						Expr: schema.ExpressionValue{
+							Reference: lang.Reference{
+								lang.RootStep{Name: "aws"},
+								lang.AttrStep{Name: "west"},
+							},
+						},
+					},
+				},
+			}): {
+				Detail: "West region",
+			},
+			schema.NewSchemaKey(schema.DependencyKeys{
+				Labels: []schema.LabelDependent{
+					{Index: 0, Value: "aws_instance"},
+				},
+				Attributes: []schema.AttributeDependent{
+					{
+						Name: "provider",
+						Expr: schema.ExpressionValue{
+							Reference: lang.Reference{
+								lang.RootStep{Name: "aws"},
+								lang.AttrStep{Name: "east"},
+							},
+						},
+					},
+				},
+			}): {},
+			schema.NewSchemaKey(schema.DependencyKeys{
+				Labels: []schema.LabelDependent{
+					{Index: 0, Value: "aws_instance"},
+				},
+				Attributes: []schema.AttributeDependent{
+					{
+						Name: "provider",
+						Expr: schema.ExpressionValue{
+							Reference: lang.Reference{
+								lang.RootStep{Name: "aws"},
+								lang.AttrStep{Name: "legacy"},
+							},
+						},
+					},
+				},
+			}): {
+				IsDeprecated: true,
+			},
+			schema.NewSchemaKey(schema.DependencyKeys{
+				Labels: []schema.LabelDependent{
+					{Index: 0, Value: "google_instance"},
+				},
+				Attributes: []schema.AttributeDependent{
+					{
+						Name: "provider",
+						Expr: schema.ExpressionValue{
+							Reference: lang.Reference{
+								lang.RootStep{Name: "aws"},
+								lang.AttrStep{Name: "central"},
+							},
+						},
+					},
+				},
+			}): {},
+			schema.NewSchemaKey(schema.DependencyKeys{
+				Attributes: []schema.AttributeDependent{
+					{
+						Name: "mode",
+						Expr: schema.ExpressionValue{
+							Static: cty.StringVal("fast"),
+						},
+					},
+				},
+			}): {},
+		},
+	}
+	bodySchema := &schema.BodySchema{
+		Blocks: map[string]*schema.BlockSchema{
+			"resource": resourceSchema,
+		},
+	}
+
+	d := NewDecoder()
+	d.SetSchema(bodySchema)
+
+	testCases := []struct {
+		name               string
+		src                string
+		pos                hcl.Pos
+		expectedCandidates lang.Candidates
+	}{
+		{
+			"reference",
+			`resource "aws_instance" "foo" {
+  provider = aws.
+}
+`,
+			hcl.Pos{Line: 2, Column: 17, Byte: 48},
+			lang.CompleteCandidates([]lang.Candidate{
+				{
+					Label: "aws.east",
+					TextEdit: lang.TextEdit{
+						Range: hcl.Range{
+							Filename: "test.tf",
+							Start:    hcl.Pos{Line: 2, Column: 14, Byte: 45},
+							End:      hcl.Pos{Line: 2, Column: 17, Byte: 48},
+						},
+						NewText: "aws.east",
+						Snippet: "aws.east",
+					},
+					Kind:       lang.ValueCandidateKind,
+					SortText:   "0",
+					FilterText: "aws.east",
+				},
+				{
+					Label:  "aws.west",
+					Detail: "West region",
+					TextEdit: lang.TextEdit{
+						Range: hcl.Range{
+							Filename: "test.tf",
+							Start:    hcl.Pos{Line: 2, Column: 14, Byte: 45},
+							End:      hcl.Pos{Line: 2, Column: 17, Byte: 48},
+						},
+						NewText: "aws.west",
+						Snippet: "aws.west",
+					},
+					Kind:       lang.ValueCandidateKind,
+					SortText:   "1",
+					FilterText: "aws.west",
+				},
+				{
+					Label:        "aws.legacy",
+					IsDeprecated: true,
+					TextEdit: lang.TextEdit{
+						Range: hcl.Range{
+							Filename: "test.tf",
+							Start:    hcl.Pos{Line: 2, Column: 14, Byte: 45},
+							End:      hcl.Pos{Line: 2, Column: 17, Byte: 48},
+						},
+						NewText: "aws.legacy",
+						Snippet: "aws.legacy",
+					},
+					Kind:       lang.ValueCandidateKind,
+					SortText:   "2",
+					FilterText: "aws.legacy",
+				},
+			}),
+		},
+		{
+			"reference after dot",
+			`resource "aws_instance" "foo" {
+  provider = aws.
+}
+`,
+			hcl.Pos{Line: 2, Column: 18, Byte: 49},
+			lang.CompleteCandidates([]lang.Candidate{
+				{
+					Label: "aws.east",
+					TextEdit: lang.TextEdit{
+						Range: hcl.Range{
+							Filename: "test.tf",
+							Start:    hcl.Pos{Line: 2, Column: 14, Byte: 45},
+							End:      hcl.Pos{Line: 2, Column: 18, Byte: 49},
+						},
+						NewText: "aws.east",
+						Snippet: "aws.east",
+					},
+					Kind:       lang.ValueCandidateKind,
+					SortText:   "0",
+					FilterText: "aws.east",
+				},
+				{
+					Label:  "aws.west",
+					Detail: "West region",
+					TextEdit: lang.TextEdit{
+						Range: hcl.Range{
+							Filename: "test.tf",
+							Start:    hcl.Pos{Line: 2, Column: 14, Byte: 45},
+							End:      hcl.Pos{Line: 2, Column: 18, Byte: 49},
+						},
+						NewText: "aws.west",
+						Snippet: "aws.west",
+					},
+					Kind:       lang.ValueCandidateKind,
+					SortText:   "1",
+					FilterText: "aws.west",
+				},
+				{
+					Label:        "aws.legacy",
+					IsDeprecated: true,
+					TextEdit: lang.TextEdit{
+						Range: hcl.Range{
+							Filename: "test.tf",
+							Start:    hcl.Pos{Line: 2, Column: 14, Byte: 45},
+							End:      hcl.Pos{Line: 2, Column: 18, Byte: 49},
+						},
+						NewText: "aws.legacy",
+						Snippet: "aws.legacy",
+					},
+					Kind:       lang.ValueCandidateKind,
+					SortText:   "2",
+					FilterText: "aws.legacy",
+				},
+			}),
+		},
+		{
+			"fuzzy reference",
+			`resource "aws_instance" "foo" {
+  provider = aw.wst
+}
+`,
+			hcl.Pos{Line: 2, Column: 20, Byte: 51},
+			lang.CompleteCandidates([]lang.Candidate{
+				{
+					Label:  "aws.west",
+					Detail: "West region",
+					TextEdit: lang.TextEdit{
+						Range: hcl.Range{
+							Filename: "test.tf",
+							Start:    hcl.Pos{Line: 2, Column: 14, Byte: 45},
+							End:      hcl.Pos{Line: 2, Column: 20, Byte: 51},
+						},
+						NewText: "aws.west",
+						Snippet: "aws.west",
+					},
+					Kind:       lang.ValueCandidateKind,
+					SortText:   "0",
+					FilterText: "aws.west",
+				},
+			}),
+		},
+		{
+			"static value",
+			`resource "aws_instance" "foo" {
+  mode = ""
+}
+`,
+			hcl.Pos{Line: 2, Column: 11, Byte: 42},
+			lang.CompleteCandidates([]lang.Candidate{
+				{
+					Label: `"fast"`,
+					TextEdit: lang.TextEdit{
+						Range: hcl.Range{
+							Filename: "test.tf",
+							Start:    hcl.Pos{Line: 2, Column: 10, Byte: 41},
+							End:      hcl.Pos{Line: 2, Column: 12, Byte: 43},
+						},
+						NewText: `"fast"`,
+						Snippet: `"fast"`,
+					},
+					Kind:       lang.ValueCandidateKind,
+					SortText:   "0",
+					FilterText: `"fast"`,
+				},
+			}),
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			f, _ := hclsyntax.ParseConfig([]byte(tc.src), "test.tf", hcl.InitialPos)
+
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			candidates, err := d.CandidatesAtPos("test.tf", tc.pos)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expectedCandidates, candidates); diff != "" {
+				t.Fatalf("unexpected candidates: %s", diff)
+			}
+		})
+	}
+}
@@ -2,25 +2,40 @@ package decoder
 
 import (
 	"fmt"
-	"reflect"
 	"sync"
 
 	"github.com/hashicorp/hcl-lang/lang"
 	"github.com/hashicorp/hcl-lang/schema"
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
-	"github.com/mitchellh/copystructure"
-	"github.com/zclconf/go-cty/cty"
 )
 
 type Decoder struct {
 	files   map[string]*hcl.File
 	filesMu *sync.RWMutex
 
-	rootSchema    *schema.BodySchema
-	rootSchemaMu  *sync.RWMutex
+	rootSchema   *schema.BodySchema
+	rootSchemaMu *sync.RWMutex
+
+	// maxCandidates is the maximum number of candidates
+	// returned at once (see SetMaxCandidates),
+	// guarded by rootSchemaMu
 	maxCandidates uint
 
+	// baseSchema is the schema set via SetSchema and partialSchemas
+	// are schemas added via AddSchema (in order of addition),
+	// which are merged together into rootSchema
+	baseSchema     *schema.BodySchema
+	partialSchemas []partialSchema
+
+	// evalCtx provides variables and functions available
+	// in expressions (see SetEvalContext)
+	evalCtx *hcl.EvalContext
+
+	// depBodyIndex contains precomputed DependentBody index
+	// of each block schema within rootSchema
+	depBodyIndex map[*schema.BlockSchema]*schema.DependentBodyIndex
+
 	// UTM parameters for docs URLs
 	// utm_source parameter, typically language server identification
 	utmSource string
@@ -28,6 +43,10 @@ type Decoder struct {
 	utmMedium string
 	// utm_content parameter, e.g. documentHover or documentLink
 	useUtmContent bool
+
+	// useCandidateResolve enables two-phase completion
+	// (see UseCandidateResolve), guarded by rootSchemaMu
+	useCandidateResolve bool
 }
 
 // NewDecoder creates a new Decoder
@@ -37,6 +56,7 @@ type Decoder struct {
 func NewDecoder() *Decoder {
 	return &Decoder{
 		rootSchemaMu:  &sync.RWMutex{},
+		depBodyIndex:  make(map[*schema.BlockSchema]*schema.DependentBodyIndex, 0),
 		files:         make(map[string]*hcl.File, 0),
 		filesMu:       &sync.RWMutex{},
 		maxCandidates: 100,
@@ -48,11 +68,164 @@ func NewDecoder() *Decoder {
 // This is useful for progressive enhancement experience, where a
 // Decoder without schema can provide limited functionality (e.g. symbols), and
 // the schema can be gradually enriched (e.g. Terraform core -> providers).
-func (d *Decoder) SetSchema(schema *schema.BodySchema) {
+//
+// Schema set here is merged with any partial schemas added via AddSchema.
+func (d *Decoder) SetSchema(bodySchema *schema.BodySchema) {
+	d.rootSchemaMu.Lock()
+	defer d.rootSchemaMu.Unlock()
+
+	d.baseSchema = bodySchema
+	d.rebuildRootSchema()
+}
+
+type partialSchema struct {
+	source string
+	schema *schema.BodySchema
+}
+
+// AddSchema adds partial schema identified by source (e.g. a provider
+// address) which is merged with schema set via SetSchema and with
+// any other partial schemas. Adding schema for an already known source
+// replaces the previous schema from that source.
+//
+// Error is returned (and the schema is not added) if the partial
+// schema conflicts with any of the other schemas.
+func (d *Decoder) AddSchema(source string, partial *schema.BodySchema) error {
+	d.rootSchemaMu.Lock()
+	defer d.rootSchemaMu.Unlock()
+
+	others := make([]partialSchema, 0, len(d.partialSchemas))
+	replacedIdx := -1
+	for i, ps := range d.partialSchemas {
+		if ps.source == source {
+			replacedIdx = i
+			continue
+		}
+		others = append(others, ps)
+	}
+
+	// only conflicts of the added schema are checked, such that
+	// conflicts between the other schemas (which SetSchema may
+	// introduce) do not prevent adding unrelated schemas
+	_, err := schema.MergeBodySchemasWithPolicy(schema.ConflictError,
+		mergeSchemas(d.baseSchema, others), partial)
+	if err != nil {
+		return fmt.Errorf("%s: %w", source, err)
+	}
+
+	if replacedIdx >= 0 {
+		d.partialSchemas[replacedIdx] = partialSchema{source, partial}
+	} else {
+		d.partialSchemas = append(d.partialSchemas, partialSchema{source, partial})
+	}
+	d.rebuildRootSchema()
+
+	return nil
+}
+
+// RemoveSchema removes partial schema previously added via AddSchema
+func (d *Decoder) RemoveSchema(source string) error {
+	d.rootSchemaMu.Lock()
+	defer d.rootSchemaMu.Unlock()
+
+	remaining := make([]partialSchema, 0, len(d.partialSchemas))
+	for _, ps := range d.partialSchemas {
+		if ps.source != source {
+			remaining = append(remaining, ps)
+		}
+	}
+	if len(remaining) == len(d.partialSchemas) {
+		return fmt.Errorf("%s: schema not found", source)
+	}
+
+	d.partialSchemas = remaining
+	d.rebuildRootSchema()
+
+	return nil
+}
+
+// rebuildRootSchema merges base and partial schemas into rootSchema
+func (d *Decoder) rebuildRootSchema() {
+	if len(d.partialSchemas) == 0 {
+		d.rootSchema = d.baseSchema
+	} else {
+		d.rootSchema = mergeSchemas(d.baseSchema, d.partialSchemas)
+	}
+	d.depBodyIndex = indexDependentBodies(d.rootSchema)
+}
+
+// mergeSchemas merges base and partial schemas, where base
+// (and earlier partial schemas) take precedence, as SetSchema cannot
+// fail and conflicts of partial schemas are checked in AddSchema
+func mergeSchemas(base *schema.BodySchema, partials []partialSchema) *schema.BodySchema {
+	schemas := make([]*schema.BodySchema, len(partials))
+	for i, ps := range partials {
+		schemas[i] = ps.schema
+	}
+
+	// merging never fails when preferring base
+	merged, _ := schema.MergeBodySchemasWithPolicy(schema.ConflictPreferBase, base, schemas...)
+	return merged
+}
+
+// indexDependentBodies builds DependentBody index for all block schemas
+// within the given body schema, including nested and dependent ones
+func indexDependentBodies(bodySchema *schema.BodySchema) map[*schema.BlockSchema]*schema.DependentBodyIndex {
+	index := make(map[*schema.BlockSchema]*schema.DependentBodyIndex, 0)
+	visited := make(map[*schema.BodySchema]bool, 0)
+
+	var walk func(*schema.BodySchema)
+	walk = func(bs *schema.BodySchema) {
+		if bs == nil || visited[bs] {
+			return
+		}
+		visited[bs] = true
+
+		for _, block := range bs.Blocks {
+			if len(block.DependentBody) > 0 {
+				if _, ok := index[block]; !ok {
+					index[block] = schema.NewDependentBodyIndex(block)
+				}
+			}
+			walk(block.Body)
+			for _, depBody := range block.DependentBody {
+				walk(depBody)
+			}
+		}
+	}
+	walk(bodySchema)
+
+	return index
+}
+
+// dependentBodySchema finds relevant dependent BodySchema of the block
+// using the precomputed index where available
+func (d *Decoder) dependentBodySchema(blockSchema *schema.BlockSchema, dks schema.DependencyKeys) (*schema.BodySchema, bool) {
+	idx, ok := d.depBodyIndex[blockSchema]
+	if ok {
+		return idx.BodySchema(dks)
+	}
+	return blockSchema.DependentBodySchema(dks)
+}
+
+// dependentBodyIndex returns precomputed index of DependentBody
+// or creates a new one if the block schema is not indexed
+func (d *Decoder) dependentBodyIndex(blockSchema *schema.BlockSchema) *schema.DependentBodyIndex {
+	idx, ok := d.depBodyIndex[blockSchema]
+	if ok {
+		return idx
+	}
+	return schema.NewDependentBodyIndex(blockSchema)
+}
+
+// SetEvalContext sets the context whose variables and functions
+// are offered as candidates within expressions, such as
+// template interpolations ("${...}") and directives ("%{...}").
+func (d *Decoder) SetEvalContext(ctx *hcl.EvalContext) {
 	d.rootSchemaMu.Lock()
 	defer d.rootSchemaMu.Unlock()
 
-	d.rootSchema = schema
+	d.evalCtx = ctx
 }
 
 func (d *Decoder) SetUtmSource(src string) {
@@ -157,52 +330,23 @@ func posEqual(pos, other hcl.Pos) bool {
 		pos.Byte == other.Byte
 }
 
-func mergeBlockBodySchemas(block *hclsyntax.Block, blockSchema *schema.BlockSchema) (*schema.BodySchema, error) {
+// mergeBlockBodySchemas returns a read-only view of block's body schema
+// combined with dependent body schema (if any) selected by the block
+func (d *Decoder) mergeBlockBodySchemas(block *hclsyntax.Block, blockSchema *schema.BlockSchema) *schema.LayeredBodySchema {
 	if len(blockSchema.DependentBody) == 0 {
-		return blockSchema.Body, nil
-	}
-
-	// cty.Type has private fields, so we have to declare custom copier
-	copystructure.Copiers = map[reflect.Type]copystructure.CopierFunc{
-		reflect.TypeOf(cty.NilType): ctyTypeCopier,
-	}
-
-	schemaCopy, err := copystructure.Copy(blockSchema.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	mergedSchema := schemaCopy.(*schema.BodySchema)
-	if mergedSchema.Attributes == nil {
-		mergedSchema.Attributes = make(map[string]*schema.AttributeSchema, 0)
-	}
-	if mergedSchema.Blocks == nil {
-		mergedSchema.Blocks = make(map[string]*schema.BlockSchema, 0)
+		return schema.NewLayeredBodySchema(blockSchema.Body)
 	}
 
 	dk := dependencyKeysFromBlock(block, blockSchema)
 
-	depSchema, ok := blockSchema.DependentBodySchema(dk)
-	if ok {
-		for name, attr := range depSchema.Attributes {
-			if _, exists := mergedSchema.Attributes[name]; !exists {
-				mergedSchema.Attributes[name] = attr
-			} else {
-				// Skip duplicate attribute
-				continue
-			}
-		}
-		for bType, block := range depSchema.Blocks {
-			if _, exists := mergedSchema.Blocks[bType]; !exists {
-				mergedSchema.Blocks[bType] = block
-			} else {
-				// Skip duplicate block type
-				continue
-			}
-		}
+	depSchema, ok := d.dependentBodySchema(blockSchema, dk)
+	if !ok {
+		return schema.NewLayeredBodySchema(blockSchema.Body)
 	}
 
-	return mergedSchema, nil
+	// attributes and blocks of the base body take precedence
+	// over duplicates in the dependent body
+	return schema.NewLayeredBodySchema(blockSchema.Body, depSchema)
 }
 
 func dependencyKeysFromBlock(block *hclsyntax.Block, blockSchema *schema.BlockSchema) schema.DependencyKeys {
@@ -211,6 +355,11 @@ func dependencyKeysFromBlock(block *hclsyntax.Block, blockSchema *schema.BlockSc
 		Attributes: []schema.AttributeDependent{},
 	}
 	for i, labelSchema := range blockSchema.Labels {
+		if i >= len(block.Labels) {
+			// labels not yet declared are left out, such that
+			// a less specific dependent body can be looked up
+			break
+		}
 		if labelSchema.IsDepKey {
 			dk.Labels = append(dk.Labels, schema.LabelDependent{
 				Index: i,
@@ -233,7 +382,7 @@ func dependencyKeysFromBlock(block *hclsyntax.Block, blockSchema *schema.BlockSc
 
 			st, ok := attr.Expr.(*hclsyntax.ScopeTraversalExpr)
 			if ok {
-				refVal, err := traversalToReference(st.AsTraversal())
+				refVal, err := lang.TraversalToReference(st.AsTraversal())
 				if err != nil {
 					// skip unparsable reference
 					continue
@@ -264,33 +413,6 @@ func dependencyKeysFromBlock(block *hclsyntax.Block, blockSchema *schema.BlockSc
 	return dk
 }
 
-func ctyTypeCopier(v interface{}) (interface{}, error) {
-	return v.(cty.Type), nil
-}
-
-func traversalToReference(traversal hcl.Traversal) (lang.Reference, error) {
-	r := lang.Reference{}
-	for _, tr := range traversal {
-		switch t := tr.(type) {
-		case *hcl.TraverseRoot:
-			r = append(r, lang.RootStep{
-				Name: t.Name,
-			})
-		case *hcl.TraverseAttr:
-			r = append(r, lang.AttrStep{
-				Name: t.Name,
-			})
-		case *hcl.TraverseIndex:
-			r = append(r, lang.IndexStep{
-				Key: t.Key,
-			})
-		default:
-			return r, fmt.Errorf("invalid traversal: %T", tr)
-		}
-	}
-	return r, nil
-}
-
 func stringPos(pos hcl.Pos) string {
 	return fmt.Sprintf("%d,%d", pos.Line, pos.Column)
 }
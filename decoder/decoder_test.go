@@ -4,7 +4,11 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
 	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
 )
 
 func TestDecoder_LoadFile_nilFile(t *testing.T) {
@@ -31,3 +35,273 @@ func TestDecoder_LoadFile_nilRootBody(t *testing.T) {
 		t.Fatalf("unexpected error: %s", diff)
 	}
 }
+
+func TestDecoder_AddSchema(t *testing.T) {
+	resourceBlock := func(labelValue string) *schema.BodySchema {
+		return &schema.BodySchema{
+			Blocks: map[string]*schema.BlockSchema{
+				"resource": {
+					DependentBody: map[schema.SchemaKey]*schema.BodySchema{
+						schema.NewSchemaKey(schema.DependencyKeys{
+							Labels: []schema.LabelDependent{
+								{Index: 0, Value: labelValue},
+							},
+						}): {
+							Attributes: map[string]*schema.AttributeSchema{
+								"name": {ValueType: cty.String, IsOptional: true},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	d := NewDecoder()
+	d.SetSchema(&schema.BodySchema{
+		Blocks: map[string]*schema.BlockSchema{
+			"resource": {
+				Labels: []*schema.LabelSchema{
+					{Name: "type", IsDepKey: true},
+					{Name: "name"},
+				},
+			},
+		},
+	})
+
+	f, _ := hclsyntax.ParseConfig([]byte(`resource "" "x" {
+}
+`), "test.tf", hcl.InitialPos)
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	labelsAtPos := func() []string {
+		candidates, err := d.CandidatesAtPos("test.tf", hcl.Pos{Line: 1, Column: 11, Byte: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		labels := make([]string, len(candidates.List))
+		for i, c := range candidates.List {
+			labels[i] = c.Label
+		}
+		return labels
+	}
+
+	err = d.AddSchema("aws", resourceBlock("aws_instance"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = d.AddSchema("google", resourceBlock("google_compute_instance"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"aws_instance", "google_compute_instance"}, labelsAtPos()); diff != "" {
+		t.Fatalf("unexpected candidates: %s", diff)
+	}
+
+	conflicting := resourceBlock("aws_instance")
+	conflicting.Blocks["resource"].Type = schema.BlockTypeList
+	conflicting.Blocks["resource"].Labels = []*schema.LabelSchema{
+		{Name: "kind"},
+	}
+	err = d.AddSchema("other", conflicting)
+	if err == nil {
+		t.Fatal("expected conflict error")
+	}
+	if diff := cmp.Diff("other: resource: conflicting labels", err.Error()); diff != "" {
+		t.Fatalf("unexpected error: %s", diff)
+	}
+
+	err = d.RemoveSchema("aws")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"google_compute_instance"}, labelsAtPos()); diff != "" {
+		t.Fatalf("unexpected candidates after removal: %s", diff)
+	}
+
+	err = d.RemoveSchema("aws")
+	if err == nil {
+		t.Fatal("expected error when removing unknown schema")
+	}
+}
+
+func TestDecoder_RemoveSchema_conflictingBase(t *testing.T) {
+	partial := func(attrType cty.Type) *schema.BodySchema {
+		return &schema.BodySchema{
+			Attributes: map[string]*schema.AttributeSchema{
+				"name": {ValueType: attrType, IsOptional: true},
+			},
+		}
+	}
+
+	d := NewDecoder()
+	err := d.AddSchema("other", &schema.BodySchema{
+		Attributes: map[string]*schema.AttributeSchema{
+			"other": {ValueType: cty.String, IsOptional: true},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = d.AddSchema("conflicting", partial(cty.Number))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// base schema takes precedence over the conflicting partial schema
+	d.SetSchema(partial(cty.String))
+
+	err = d.RemoveSchema("other")
+	if err != nil {
+		t.Fatalf("unexpected error when removing unrelated schema: %s", err)
+	}
+
+	err = d.AddSchema("unrelated", &schema.BodySchema{
+		Attributes: map[string]*schema.AttributeSchema{
+			"unrelated": {ValueType: cty.String, IsOptional: true},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error when adding unrelated schema: %s", err)
+	}
+
+	err = d.AddSchema("new_conflicting", partial(cty.Bool))
+	if err == nil {
+		t.Fatal("expected conflict error for added schema")
+	}
+
+	d.rootSchemaMu.RLock()
+	defer d.rootSchemaMu.RUnlock()
+	if !d.rootSchema.Attributes["name"].ValueType.Equals(cty.String) {
+		t.Fatalf("expected base attribute to take precedence, given: %s",
+			d.rootSchema.Attributes["name"].ValueType.FriendlyName())
+	}
+	if _, ok := d.rootSchema.Attributes["other"]; ok {
+		t.Fatal("expected removed schema to be excluded")
+	}
+}
+
+func TestDependencyKeysFromBlock_reference(t *testing.T) {
+	blockSchema := &schema.BlockSchema{
+		Labels: []*schema.LabelSchema{
+			{Name: "type", IsDepKey: true},
+			{Name: "name"},
+		},
+		Body: &schema.BodySchema{
+			Attributes: map[string]*schema.AttributeSchema{
+				"provider": {ValueType: cty.DynamicPseudoType, IsOptional: true, IsDepKey: true},
+			},
+		},
+	}
+
+	f, pDiags := hclsyntax.ParseConfig([]byte(`resource "aws_instance" "example" {
+  provider = aws.west
+}
+`), "test.tf", hcl.InitialPos)
+	if len(pDiags) > 0 {
+		t.Fatal(pDiags)
+	}
+	block := f.Body.(*hclsyntax.Body).Blocks[0]
+
+	// hclsyntax produces traversal steps as values (not pointers)
+	expectedRef := lang.Reference{
+		lang.RootStep{Name: "aws"},
+		lang.AttrStep{Name: "west"},
+	}
+	dk := dependencyKeysFromBlock(block, blockSchema)
+	if len(dk.Attributes) != 1 {
+		t.Fatalf("expected 1 attribute dependency, given: %d", len(dk.Attributes))
+	}
+	if dk.Attributes[0].Name != "provider" {
+		t.Fatalf("unexpected attribute name: %q", dk.Attributes[0].Name)
+	}
+	if diff := cmp.Diff(expectedRef, dk.Attributes[0].Expr.Reference); diff != "" {
+		t.Fatalf("unexpected reference: %s", diff)
+	}
+}
+
+func TestDependencyKeysFromBlock_missingLabels(t *testing.T) {
+	blockSchema := &schema.BlockSchema{
+		Labels: []*schema.LabelSchema{
+			{Name: "type", IsDepKey: true},
+			{Name: "kind", IsDepKey: true},
+		},
+		Body: &schema.BodySchema{},
+	}
+
+	f, _ := hclsyntax.ParseConfig([]byte(`connector "kafka" {
+}
+`), "test.tf", hcl.InitialPos)
+	block := f.Body.(*hclsyntax.Body).Blocks[0]
+
+	expectedLabels := []schema.LabelDependent{
+		{Index: 0, Value: "kafka"},
+	}
+	dk := dependencyKeysFromBlock(block, blockSchema)
+	if diff := cmp.Diff(expectedLabels, dk.Labels); diff != "" {
+		t.Fatalf("unexpected label dependencies: %s", diff)
+	}
+}
+
+func TestDecoder_CandidatesAtPos_missingDependencyKeyLabel(t *testing.T) {
+	d := NewDecoder()
+	d.SetSchema(&schema.BodySchema{
+		Blocks: map[string]*schema.BlockSchema{
+			"connector": {
+				Labels: []*schema.LabelSchema{
+					{Name: "type", IsDepKey: true},
+					{Name: "kind", IsDepKey: true},
+				},
+				Body: &schema.BodySchema{},
+				DependentBody: map[schema.SchemaKey]*schema.BodySchema{
+					schema.NewSchemaKey(schema.DependencyKeys{
+						Labels: []schema.LabelDependent{
+							{Index: 0, Value: "kafka"},
+						},
+					}): {
+						Attributes: map[string]*schema.AttributeSchema{
+							"brokers": {ValueType: cty.String, IsOptional: true},
+						},
+					},
+					schema.NewSchemaKey(schema.DependencyKeys{
+						Labels: []schema.LabelDependent{
+							{Index: 0, Value: "kafka"},
+							{Index: 1, Value: "sink"},
+						},
+					}): {
+						Attributes: map[string]*schema.AttributeSchema{
+							"brokers": {ValueType: cty.String, IsOptional: true},
+							"topic":   {ValueType: cty.String, IsOptional: true},
+						},
+					},
+				},
+			},
+		},
+	})
+
+	f, _ := hclsyntax.ParseConfig([]byte(`connector "kafka" {
+  
+}
+`), "test.tf", hcl.InitialPos)
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	candidates, err := d.CandidatesAtPos("test.tf", hcl.Pos{Line: 2, Column: 3, Byte: 22})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	labels := make([]string, 0)
+	for _, c := range candidates.List {
+		labels = append(labels, c.Label)
+	}
+	expectedLabels := []string{"brokers"}
+	if diff := cmp.Diff(expectedLabels, labels); diff != "" {
+		t.Fatalf("unexpected candidates: %s", diff)
+	}
+}
@@ -0,0 +1,160 @@
+package decoder
+
+import (
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// depKeyAttributeAtPos returns an attribute marked as IsDepKey
+// if the position is in its value (right-hand side)
+func depKeyAttributeAtPos(body *hclsyntax.Body, blockSchema *schema.BlockSchema, pos hcl.Pos) (*hclsyntax.Attribute, bool) {
+	if blockSchema.Body == nil {
+		return nil, false
+	}
+
+	for name, attr := range body.Attributes {
+		aSchema, ok := blockSchema.Body.Attributes[name]
+		if !ok || !aSchema.IsDepKey {
+			continue
+		}
+		if attr.Expr.Range().ContainsPos(pos) || isRightHandSidePos(attr, pos) {
+			return attr, true
+		}
+	}
+
+	return nil, false
+}
+
+// dependentAttributeAtPos returns the block (and its schema) whose attribute
+// marked as IsDepKey has its value at the position, such that values
+// declared by dependent bodies take precedence over traversals
+// (e.g. provider = aws.)
+func (d *Decoder) dependentAttributeAtPos(body *hclsyntax.Body, bodySchema *schema.LayeredBodySchema, pos hcl.Pos) (*hclsyntax.Block, *hclsyntax.Attribute, *schema.BlockSchema, bool) {
+	for _, block := range body.Blocks {
+		if block.Body == nil || !block.Body.Range().ContainsPos(pos) {
+			continue
+		}
+		bSchema, ok := bodySchema.Block(block.Type)
+		if !ok {
+			return nil, nil, nil, false
+		}
+		if attr, ok := depKeyAttributeAtPos(block.Body, bSchema, pos); ok {
+			return block, attr, bSchema, true
+		}
+		return d.dependentAttributeAtPos(block.Body, d.mergeBlockBodySchemas(block, bSchema), pos)
+	}
+	return nil, nil, nil, false
+}
+
+func (d *Decoder) attributeCandidatesFromDependentSchema(block *hclsyntax.Block, attr *hclsyntax.Attribute, blockSchema *schema.BlockSchema, pos hcl.Pos, req candidatesRequest) (lang.Candidates, error) {
+	editRng := valueRangeAtPos(attr.Expr, pos)
+	prefixRng := editRng
+	prefixRng.End = pos
+	prefix, _ := d.bytesFromRange(prefixRng)
+
+	ranked := make([]rankedCandidate, 0)
+	seen := make(map[string]bool, 0)
+
+	d.dependentBodyIndex(blockSchema).ForEach(func(depKeys schema.DependencyKeys, bodySchema *schema.BodySchema) bool {
+		if !labelsMatchBlock(depKeys.Labels, block) {
+			return true
+		}
+
+		for _, ad := range depKeys.Attributes {
+			if ad.Name != attr.Name {
+				continue
+			}
+
+			text, ok := expressionValueText(ad.Expr)
+			if !ok || seen[text] || !req.isRequested(text) {
+				continue
+			}
+			score, ok := fuzzyMatchScore(string(prefix), text)
+			if !ok {
+				continue
+			}
+			seen[text] = true
+
+			depBody := bodySchema
+			ranked = append(ranked, rankedCandidate{
+				label: text,
+				score: score + metadataScore(false, depBody.IsDeprecated),
+				candidate: func() lang.Candidate {
+					return lang.Candidate{
+						Label:        text,
+						Kind:         lang.ValueCandidateKind,
+						IsDeprecated: depBody.IsDeprecated,
+						TextEdit: lang.TextEdit{
+							NewText: text,
+							Snippet: text,
+							Range:   editRng,
+						},
+					}
+				},
+				details: func(c *lang.Candidate) {
+					c.Detail = depBody.Detail
+					c.Description = depBody.Description
+				},
+			})
+		}
+		return true
+	})
+
+	return rankCandidates(ranked, req), nil
+}
+
+// labelsMatchBlock returns true if all label dependencies
+// match the labels declared in the block
+func labelsMatchBlock(labels []schema.LabelDependent, block *hclsyntax.Block) bool {
+	for _, ld := range labels {
+		if ld.Index >= len(block.Labels) {
+			return false
+		}
+		if block.Labels[ld.Index] != ld.Value {
+			return false
+		}
+	}
+	return true
+}
+
+// expressionValueText returns the configuration representation
+// of a static value or a reference
+func expressionValueText(ev schema.ExpressionValue) (string, bool) {
+	if len(ev.Reference) > 0 {
+		b, err := ev.Reference.Marshal()
+		if err != nil {
+			return "", false
+		}
+		return string(b), true
+	}
+
+	if ev.Static.Type() == cty.NilType || !ev.Static.IsWhollyKnown() {
+		return "", false
+	}
+
+	return string(hclwrite.TokensForValue(ev.Static).Bytes()), true
+}
+
+// valueRangeAtPos returns range of the (possibly incomplete)
+// expression on the line of the given position
+func valueRangeAtPos(expr hclsyntax.Expression, pos hcl.Pos) hcl.Range {
+	rng := hcl.Range{
+		Filename: expr.Range().Filename,
+		Start:    pos,
+		End:      pos,
+	}
+
+	exprRng := expr.Range()
+	if exprRng.Start.Line == pos.Line && exprRng.Start.Byte <= pos.Byte {
+		rng.Start = exprRng.Start
+	}
+	if exprRng.End.Line == pos.Line && exprRng.End.Byte >= pos.Byte {
+		rng.End = exprRng.End
+	}
+
+	return rng
+}
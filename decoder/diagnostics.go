@@ -0,0 +1,170 @@
+package decoder
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// DiagnosticsInFile returns diagnostics for the config file
+// based on constraints described by the schema
+//
+// Schema is required in order to return any diagnostics and method will
+// return error if there isn't one.
+func (d *Decoder) DiagnosticsInFile(filename string) (hcl.Diagnostics, error) {
+	f, err := d.fileByName(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := d.bodyForFileAndPos(filename, f, hcl.InitialPos)
+	if err != nil {
+		return nil, err
+	}
+
+	d.rootSchemaMu.RLock()
+	defer d.rootSchemaMu.RUnlock()
+
+	if d.rootSchema == nil {
+		return hcl.Diagnostics{}, &NoSchemaError{}
+	}
+
+	diags := d.diagnosticsForBody(body, schema.NewLayeredBodySchema(d.rootSchema))
+
+	sort.SliceStable(diags, func(i, j int) bool {
+		return diags[i].Subject.Start.Byte < diags[j].Subject.Start.Byte
+	})
+
+	return diags, nil
+}
+
+func (d *Decoder) diagnosticsForBody(body *hclsyntax.Body, bodySchema *schema.LayeredBodySchema) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	if bodySchema.IsEmpty() {
+		return diags
+	}
+
+	for name, attr := range body.Attributes {
+		attrSchema, ok := bodySchema.AttributeOrAny(name)
+		if !ok {
+			// unknown attribute
+			continue
+		}
+
+		diags = append(diags, diagnosticsForAttribute(attr, attrSchema)...)
+	}
+
+	for _, block := range body.Blocks {
+		blockSchema, ok := bodySchema.Block(block.Type)
+		if !ok {
+			// unknown block
+			continue
+		}
+
+		mergedSchema := d.mergeBlockBodySchemas(block, blockSchema)
+		diags = append(diags, d.diagnosticsForBody(block.Body, mergedSchema)...)
+	}
+
+	return diags
+}
+
+func diagnosticsForAttribute(attr *hclsyntax.Attribute, attrSchema *schema.AttributeSchema) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	if len(attrSchema.MapKeys) > 0 {
+		diags = append(diags, mapKeyDiagnostics(attr, attrSchema)...)
+	}
+
+	if len(attrSchema.AllowedValues) > 0 {
+		diags = append(diags, allowedValueDiagnostics(attr, attrSchema)...)
+	}
+
+	return diags
+}
+
+func mapKeyDiagnostics(attr *hclsyntax.Attribute, attrSchema *schema.AttributeSchema) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	expr, ok := attr.Expr.(*hclsyntax.ObjectConsExpr)
+	if !ok {
+		// keys cannot be determined statically
+		return diags
+	}
+
+	declaredKeys := make(map[string]bool, 0)
+	for _, item := range expr.Items {
+		key, ok := objectConsKeyName(item.KeyExpr)
+		if !ok {
+			continue
+		}
+		declaredKeys[key] = true
+
+		if _, known := attrSchema.MapKeys[key]; !known && attrSchema.IsMapKeysExhaustive {
+			keyRng := item.KeyExpr.Range()
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Unknown key",
+				Detail:   fmt.Sprintf("%q is not a valid key of %s", key, attr.Name),
+				Subject:  &keyRng,
+			})
+		}
+	}
+
+	for _, key := range sortedMapKeyNames(attrSchema.MapKeys) {
+		if attrSchema.MapKeys[key].IsRequired && !declaredKeys[key] {
+			exprRng := expr.Range()
+			diags = append(diags, &hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Missing required key",
+				Detail:   fmt.Sprintf("%s is missing required key %q", attr.Name, key),
+				Subject:  &exprRng,
+			})
+		}
+	}
+
+	return diags
+}
+
+func allowedValueDiagnostics(attr *hclsyntax.Attribute, attrSchema *schema.AttributeSchema) hcl.Diagnostics {
+	diags := hcl.Diagnostics{}
+
+	value, ok := stringLiteralValue(attr.Expr)
+	if !ok {
+		// value cannot be determined statically
+		return diags
+	}
+
+	exprRng := attr.Expr.Range()
+
+	av, ok := attrSchema.AllowedValue(value)
+	if !ok {
+		allowed := make([]string, len(attrSchema.AllowedValues))
+		for i, av := range attrSchema.AllowedValues {
+			allowed[i] = fmt.Sprintf("%q", av.Value)
+		}
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid value",
+			Detail: fmt.Sprintf("%q is not a valid value of %s, expected one of: %s",
+				value, attr.Name, strings.Join(allowed, ", ")),
+			Subject: &exprRng,
+		})
+		return diags
+	}
+
+	if av.IsDeprecated {
+		diags = append(diags, &hcl.Diagnostic{
+			Severity: hcl.DiagWarning,
+			Summary:  "Deprecated value",
+			Detail:   fmt.Sprintf("%q is a deprecated value of %s", value, attr.Name),
+			Subject:  &exprRng,
+		})
+	}
+
+	return diags
+}
@@ -0,0 +1,172 @@
+package decoder
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestDecoder_DiagnosticsInFile_noSchema(t *testing.T) {
+	d := NewDecoder()
+	f, pDiags := hclsyntax.ParseConfig(testConfig, "test.tf", hcl.InitialPos)
+	if len(pDiags) > 0 {
+		t.Fatal(pDiags)
+	}
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = d.DiagnosticsInFile("test.tf")
+	noSchemaErr := &NoSchemaError{}
+	if !errors.As(err, &noSchemaErr) {
+		t.Fatal("expected NoSchemaError for no schema")
+	}
+}
+
+func TestDecoder_DiagnosticsInFile_mapKeys(t *testing.T) {
+	bodySchema := &schema.BodySchema{
+		Blocks: map[string]*schema.BlockSchema{
+			"resource": {
+				Body: &schema.BodySchema{
+					Attributes: map[string]*schema.AttributeSchema{
+						"tags": {
+							ValueType:  cty.Map(cty.String),
+							IsOptional: true,
+							MapKeys: map[string]*schema.MapKeySchema{
+								"Owner":      {IsRequired: true},
+								"CostCenter": {IsRequired: true},
+								"Name":       {},
+							},
+							IsMapKeysExhaustive: true,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	cfg := []byte(`resource {
+  tags = {
+    Owner = "me"
+    Team  = "platform"
+  }
+}
+`)
+
+	d := NewDecoder()
+	d.SetSchema(bodySchema)
+	f, pDiags := hclsyntax.ParseConfig(cfg, "test.tf", hcl.InitialPos)
+	if len(pDiags) > 0 {
+		t.Fatal(pDiags)
+	}
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diags, err := d.DiagnosticsInFile("test.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedDiags := hcl.Diagnostics{
+		{
+			Severity: hcl.DiagError,
+			Summary:  "Missing required key",
+			Detail:   `tags is missing required key "CostCenter"`,
+			Subject: &hcl.Range{
+				Filename: "test.tf",
+				Start:    hcl.Pos{Line: 2, Column: 10, Byte: 20},
+				End:      hcl.Pos{Line: 5, Column: 4, Byte: 65},
+			},
+		},
+		{
+			Severity: hcl.DiagError,
+			Summary:  "Unknown key",
+			Detail:   `"Team" is not a valid key of tags`,
+			Subject: &hcl.Range{
+				Filename: "test.tf",
+				Start:    hcl.Pos{Line: 4, Column: 5, Byte: 43},
+				End:      hcl.Pos{Line: 4, Column: 9, Byte: 47},
+			},
+		},
+	}
+	if diff := cmp.Diff(expectedDiags, diags); diff != "" {
+		t.Fatalf("unexpected diagnostics: %s", diff)
+	}
+}
+
+func TestDecoder_DiagnosticsInFile_allowedValues(t *testing.T) {
+	bodySchema := &schema.BodySchema{
+		Attributes: map[string]*schema.AttributeSchema{
+			"tier": {
+				ValueType:  cty.String,
+				IsOptional: true,
+				AllowedValues: []schema.AllowedValue{
+					{Value: "Basic"},
+					{Value: "Premium", IsDeprecated: true},
+				},
+			},
+			"sku": {
+				ValueType:  cty.String,
+				IsOptional: true,
+				AllowedValues: []schema.AllowedValue{
+					{Value: "Basic"},
+					{Value: "Premium", IsDeprecated: true},
+				},
+			},
+		},
+	}
+
+	cfg := []byte(`tier = "Unknown"
+sku = "Premium"
+`)
+
+	d := NewDecoder()
+	d.SetSchema(bodySchema)
+	f, pDiags := hclsyntax.ParseConfig(cfg, "test.tf", hcl.InitialPos)
+	if len(pDiags) > 0 {
+		t.Fatal(pDiags)
+	}
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diags, err := d.DiagnosticsInFile("test.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedDiags := hcl.Diagnostics{
+		{
+			Severity: hcl.DiagError,
+			Summary:  "Invalid value",
+			Detail:   `"Unknown" is not a valid value of tier, expected one of: "Basic", "Premium"`,
+			Subject: &hcl.Range{
+				Filename: "test.tf",
+				Start:    hcl.Pos{Line: 1, Column: 8, Byte: 7},
+				End:      hcl.Pos{Line: 1, Column: 17, Byte: 16},
+			},
+		},
+		{
+			Severity: hcl.DiagWarning,
+			Summary:  "Deprecated value",
+			Detail:   `"Premium" is a deprecated value of sku`,
+			Subject: &hcl.Range{
+				Filename: "test.tf",
+				Start:    hcl.Pos{Line: 2, Column: 7, Byte: 23},
+				End:      hcl.Pos{Line: 2, Column: 16, Byte: 32},
+			},
+		},
+	}
+	if diff := cmp.Diff(expectedDiags, diags); diff != "" {
+		t.Fatalf("unexpected diagnostics: %s", diff)
+	}
+}
@@ -47,3 +47,13 @@ type PositionalError struct {
 func (e *PositionalError) Error() string {
 	return fmt.Sprintf("%s (%s): %s", e.Filename, stringPos(e.Pos), e.Msg)
 }
+
+type CandidateNotFoundError struct {
+	Filename string
+	Pos      hcl.Pos
+	Label    string
+}
+
+func (e *CandidateNotFoundError) Error() string {
+	return fmt.Sprintf("%s (%s): candidate %q not found", e.Filename, stringPos(e.Pos), e.Label)
+}
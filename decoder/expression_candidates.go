@@ -0,0 +1,373 @@
+package decoder
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+type exprPosContext int
+
+const (
+	nilExprPosContext exprPosContext = iota
+	// expression, e.g. within interpolation or if directive
+	expressionContext
+	// keyword at the beginning of a directive
+	directiveKeywordContext
+)
+
+// exprPos describes position within an expression,
+// such as a template interpolation or directive
+type exprPos struct {
+	context exprPosContext
+	// editRng is range of the identifier at the position
+	// (or empty range at the position)
+	editRng hcl.Range
+	// afterDot indicates that the identifier follows a dot
+	// (i.e. it is a step of a traversal)
+	afterDot bool
+	// traversal preceding the dot, if it was recovered
+	traversal *partialTraversal
+	// beforeParen indicates that the identifier is followed
+	// by an opening parenthesis (e.g. of function arguments)
+	beforeParen bool
+}
+
+// tokenSeq represents an open sequence (interpolation, directive,
+// quoted string, heredoc or braces) within tokens
+type tokenSeq struct {
+	tokenType hclsyntax.TokenType
+	// open is the token opening the sequence
+	open hclsyntax.Token
+	// tokens within the sequence
+	tokens hclsyntax.Tokens
+}
+
+// expressionPosition returns position within an expression,
+// if the given position is in a template interpolation or directive,
+// or if it follows a dot of a traversal (e.g. "var.").
+//
+// Tokens are used instead of the AST, such that incomplete
+// expressions (which the parser cannot recover from) are supported.
+func expressionPosition(tokens hclsyntax.Tokens, filename string, pos hcl.Pos) (exprPos, bool) {
+	editRng := hcl.Range{
+		Filename: filename,
+		Start:    pos,
+		End:      pos,
+	}
+
+	// root sequence represents the body of the file
+	stack := []*tokenSeq{{tokenType: hclsyntax.TokenNil}}
+	for i, t := range tokens {
+		if t.Type == hclsyntax.TokenEOF {
+			// e.g. position at the end of file without trailing newline
+			break
+		}
+		if t.Range.End.Byte > pos.Byte {
+			if t.Type == hclsyntax.TokenIdent && t.Range.Start.Byte < pos.Byte {
+				// position in the middle of an identifier
+				editRng = t.Range
+			}
+			break
+		}
+		if t.Range.End.Byte == pos.Byte && t.Type == hclsyntax.TokenIdent {
+			// identifier right before the position
+			editRng = t.Range
+			break
+		}
+
+		switch t.Type {
+		case hclsyntax.TokenTemplateInterp, hclsyntax.TokenTemplateControl,
+			hclsyntax.TokenOQuote, hclsyntax.TokenOHeredoc, hclsyntax.TokenOBrace:
+			stack = append(stack, &tokenSeq{tokenType: t.Type, open: t})
+			continue
+		case hclsyntax.TokenTemplateSeqEnd, hclsyntax.TokenCQuote,
+			hclsyntax.TokenCHeredoc, hclsyntax.TokenCBrace:
+			if len(stack) > 1 {
+				closed := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				if t.Type == hclsyntax.TokenCQuote && closed.tokenType == hclsyntax.TokenOQuote {
+					keepIndexKey(stack[len(stack)-1], closed, t)
+				}
+			}
+			continue
+		}
+
+		seq := stack[len(stack)-1]
+		seq.tokens = append(seq.tokens, tokens[i])
+	}
+
+	seq := stack[len(stack)-1]
+	ePos := exprPos{
+		editRng: editRng,
+	}
+	next := sort.Search(len(tokens), func(i int) bool {
+		return tokens[i].Range.Start.Byte >= editRng.End.Byte
+	})
+	if next < len(tokens) && tokens[next].Type == hclsyntax.TokenOParen {
+		ePos.beforeParen = true
+	}
+	if len(seq.tokens) > 0 && seq.tokens[len(seq.tokens)-1].Type == hclsyntax.TokenDot {
+		ePos.afterDot = true
+		if traversal, ok := partialTraversalFromTokens(seq.tokens); ok {
+			ePos.traversal = traversal
+		}
+	}
+
+	switch seq.tokenType {
+	case hclsyntax.TokenTemplateInterp:
+		ePos.context = expressionContext
+	case hclsyntax.TokenTemplateControl:
+		ePos.context = directiveContext(seq.tokens)
+	case hclsyntax.TokenNil, hclsyntax.TokenOBrace:
+		// outside of templates only traversals are recognized
+		// as attribute names cannot contain dots
+		if ePos.traversal == nil {
+			return exprPos{}, false
+		}
+		ePos.context = expressionContext
+	default:
+		return exprPos{}, false
+	}
+
+	return ePos, true
+}
+
+// keepIndexKey appends the closed quoted string (including quotes)
+// to the parent sequence if it is a key of an index step,
+// such that traversals like var.map["key"]. can be recovered
+func keepIndexKey(parent, closed *tokenSeq, cQuote hclsyntax.Token) {
+	n := len(parent.tokens)
+	if n == 0 || parent.tokens[n-1].Type != hclsyntax.TokenOBrack {
+		return
+	}
+	parent.tokens = append(parent.tokens, closed.open)
+	parent.tokens = append(parent.tokens, closed.tokens...)
+	parent.tokens = append(parent.tokens, cQuote)
+}
+
+// directiveContext returns context of the position
+// following the given tokens of a directive
+func directiveContext(tokens hclsyntax.Tokens) exprPosContext {
+	if len(tokens) == 0 {
+		return directiveKeywordContext
+	}
+
+	keyword := tokens[0]
+	if keyword.Type != hclsyntax.TokenIdent {
+		return nilExprPosContext
+	}
+
+	switch string(keyword.Bytes) {
+	case "if":
+		return expressionContext
+	case "for":
+		// only the collection (after "in") is an expression
+		for _, t := range tokens[1:] {
+			if t.Type == hclsyntax.TokenIdent && string(t.Bytes) == "in" {
+				return expressionContext
+			}
+		}
+	}
+
+	return nilExprPosContext
+}
+
+var (
+	directiveKeywords = []string{"if", "else", "endif", "for", "endfor"}
+	exprKeywords      = []string{"true", "false", "null"}
+)
+
+func (d *Decoder) expressionPosCandidates(ePos exprPos, pos hcl.Pos, req candidatesRequest) lang.Candidates {
+	prefixRng := ePos.editRng
+	prefixRng.End = pos
+	prefix, _ := d.bytesFromRange(prefixRng)
+
+	switch ePos.context {
+	case directiveKeywordContext:
+		return keywordCandidates(directiveKeywords, string(prefix), ePos.editRng, req)
+	case expressionContext:
+		if ePos.traversal != nil {
+			return d.traversalStepCandidates(ePos.traversal, string(prefix), ePos.editRng, req)
+		}
+		if ePos.afterDot {
+			// traversal could not be recovered
+			return lang.ZeroCandidates()
+		}
+		return d.expressionCandidates(string(prefix), ePos.editRng, ePos.beforeParen, req)
+	}
+
+	return lang.ZeroCandidates()
+}
+
+func keywordCandidates(keywords []string, prefix string, editRng hcl.Range, req candidatesRequest) lang.Candidates {
+	ranked := make([]rankedCandidate, 0)
+	for _, keyword := range keywords {
+		keyword := keyword
+		score, ok := fuzzyMatchScore(prefix, keyword)
+		if !ok {
+			continue
+		}
+		ranked = append(ranked, rankedCandidate{
+			label: keyword,
+			score: score,
+			candidate: func() lang.Candidate {
+				return keywordToCandidate(keyword, editRng)
+			},
+		})
+	}
+	return rankCandidates(ranked, req)
+}
+
+func keywordToCandidate(keyword string, editRng hcl.Range) lang.Candidate {
+	return lang.Candidate{
+		Label: keyword,
+		Kind:  lang.KeywordCandidateKind,
+		TextEdit: lang.TextEdit{
+			NewText: keyword,
+			Snippet: keyword,
+			Range:   editRng,
+		},
+	}
+}
+
+// expressionCandidates returns candidates for variables and functions
+// of the eval context and keywords matching the prefix.
+// Parentheses are only inserted after function names
+// if they aren't there already (i.e. beforeParen is false).
+//
+// rootSchemaMu (guarding the eval context) is expected to be locked.
+func (d *Decoder) expressionCandidates(prefix string, editRng hcl.Range, beforeParen bool, req candidatesRequest) lang.Candidates {
+	ranked := make([]rankedCandidate, 0)
+
+	variables, functions := evalContextScope(d.evalCtx)
+	for _, name := range sortedVariableNames(variables) {
+		name := name
+		score, ok := fuzzyMatchScore(prefix, name)
+		if !ok {
+			continue
+		}
+		ranked = append(ranked, rankedCandidate{
+			label: name,
+			score: score,
+			candidate: func() lang.Candidate {
+				return lang.Candidate{
+					Label:  name,
+					Detail: variables[name].Type().FriendlyName(),
+					Kind:   lang.TraversalCandidateKind,
+					TextEdit: lang.TextEdit{
+						NewText: name,
+						Snippet: name,
+						Range:   editRng,
+					},
+				}
+			},
+		})
+	}
+
+	for _, name := range sortedFunctionNames(functions) {
+		name := name
+		score, ok := fuzzyMatchScore(prefix, name)
+		if !ok {
+			continue
+		}
+		ranked = append(ranked, rankedCandidate{
+			label: name,
+			score: score,
+			candidate: func() lang.Candidate {
+				textEdit := lang.TextEdit{
+					NewText: fmt.Sprintf("%s()", name),
+					Snippet: fmt.Sprintf("%s(${1})", name),
+					Range:   editRng,
+				}
+				if beforeParen {
+					textEdit.NewText, textEdit.Snippet = name, name
+				}
+
+				return lang.Candidate{
+					Label:    name,
+					Detail:   functionSignature(name, functions[name]),
+					Kind:     lang.FunctionCandidateKind,
+					TextEdit: textEdit,
+				}
+			},
+		})
+	}
+
+	for _, keyword := range exprKeywords {
+		keyword := keyword
+		score, ok := fuzzyMatchScore(prefix, keyword)
+		if !ok {
+			continue
+		}
+		ranked = append(ranked, rankedCandidate{
+			label: keyword,
+			score: score,
+			candidate: func() lang.Candidate {
+				return keywordToCandidate(keyword, editRng)
+			},
+		})
+	}
+
+	return rankCandidates(ranked, req)
+}
+
+// evalContextScope returns all variables and functions
+// available in the context, including its parents
+func evalContextScope(ctx *hcl.EvalContext) (map[string]cty.Value, map[string]function.Function) {
+	variables := make(map[string]cty.Value, 0)
+	functions := make(map[string]function.Function, 0)
+
+	for ; ctx != nil; ctx = ctx.Parent() {
+		for name, val := range ctx.Variables {
+			if _, ok := variables[name]; !ok {
+				// child context shadows its parent
+				variables[name] = val
+			}
+		}
+		for name, fn := range ctx.Functions {
+			if _, ok := functions[name]; !ok {
+				functions[name] = fn
+			}
+		}
+	}
+
+	return variables, functions
+}
+
+func sortedVariableNames(variables map[string]cty.Value) []string {
+	names := make([]string, 0, len(variables))
+	for name := range variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedFunctionNames(functions map[string]function.Function) []string {
+	names := make([]string, 0, len(functions))
+	for name := range functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// functionSignature returns signature of the function,
+// e.g. join(separator string, lists ...list of string)
+func functionSignature(name string, fn function.Function) string {
+	params := make([]string, 0)
+	for _, p := range fn.Params() {
+		params = append(params, fmt.Sprintf("%s %s", p.Name, p.Type.FriendlyName()))
+	}
+	if vp := fn.VarParam(); vp != nil {
+		params = append(params, fmt.Sprintf("%s ...%s", vp.Name, vp.Type.FriendlyName()))
+	}
+	return fmt.Sprintf("%s(%s)", name, strings.Join(params, ", "))
+}
@@ -0,0 +1,353 @@
+package decoder
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+)
+
+func TestDecoder_CandidatesAtPos_template(t *testing.T) {
+	bodySchema := &schema.BodySchema{
+		Attributes: map[string]*schema.AttributeSchema{
+			"name": {ValueType: cty.String, IsOptional: true},
+		},
+	}
+	evalCtx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"env": cty.StringVal("prod"),
+			"var": cty.ObjectVal(map[string]cty.Value{
+				"region": cty.StringVal("eu-west-1"),
+			}),
+		},
+		Functions: map[string]function.Function{
+			"upper": stdlib.UpperFunc,
+			"join":  stdlib.JoinFunc,
+		},
+	}
+
+	testCases := []struct {
+		name           string
+		cfg            string
+		pos            hcl.Pos
+		expectedLabels []string
+		expectedRange  hcl.Range
+	}{
+		{
+			"empty interpolation",
+			`name = "foo-${}-bar"
+`,
+			hcl.Pos{Line: 1, Column: 15, Byte: 14},
+			[]string{"env", "false", "join", "null", "true", "upper", "var"},
+			hcl.Range{
+				Filename: "test.tf",
+				Start:    hcl.Pos{Line: 1, Column: 15, Byte: 14},
+				End:      hcl.Pos{Line: 1, Column: 15, Byte: 14},
+			},
+		},
+		{
+			"interpolation with prefix",
+			`name = "foo-${up}-bar"
+`,
+			hcl.Pos{Line: 1, Column: 17, Byte: 16},
+			[]string{"upper"},
+			hcl.Range{
+				Filename: "test.tf",
+				Start:    hcl.Pos{Line: 1, Column: 15, Byte: 14},
+				End:      hcl.Pos{Line: 1, Column: 17, Byte: 16},
+			},
+		},
+		{
+			"interpolation in the middle of identifier",
+			`name = "${enx}"
+`,
+			hcl.Pos{Line: 1, Column: 13, Byte: 12},
+			[]string{"env"},
+			hcl.Range{
+				Filename: "test.tf",
+				Start:    hcl.Pos{Line: 1, Column: 11, Byte: 10},
+				End:      hcl.Pos{Line: 1, Column: 14, Byte: 13},
+			},
+		},
+		{
+			"heredoc interpolation",
+			`name = <<EOT
+hello ${va}
+EOT
+`,
+			hcl.Pos{Line: 2, Column: 11, Byte: 23},
+			[]string{"var"},
+			hcl.Range{
+				Filename: "test.tf",
+				Start:    hcl.Pos{Line: 2, Column: 9, Byte: 21},
+				End:      hcl.Pos{Line: 2, Column: 11, Byte: 23},
+			},
+		},
+		{
+			"directive keyword",
+			`name = "%{ en }"
+`,
+			hcl.Pos{Line: 1, Column: 14, Byte: 13},
+			[]string{"endfor", "endif"},
+			hcl.Range{
+				Filename: "test.tf",
+				Start:    hcl.Pos{Line: 1, Column: 12, Byte: 11},
+				End:      hcl.Pos{Line: 1, Column: 14, Byte: 13},
+			},
+		},
+		{
+			"if directive condition",
+			`name = "%{ if en }x%{ endif }"
+`,
+			hcl.Pos{Line: 1, Column: 17, Byte: 16},
+			[]string{"env"},
+			hcl.Range{
+				Filename: "test.tf",
+				Start:    hcl.Pos{Line: 1, Column: 15, Byte: 14},
+				End:      hcl.Pos{Line: 1, Column: 17, Byte: 16},
+			},
+		},
+		{
+			"for directive collection",
+			`name = "%{ for v in va }x%{ endfor }"
+`,
+			hcl.Pos{Line: 1, Column: 23, Byte: 22},
+			[]string{"var"},
+			hcl.Range{
+				Filename: "test.tf",
+				Start:    hcl.Pos{Line: 1, Column: 21, Byte: 20},
+				End:      hcl.Pos{Line: 1, Column: 23, Byte: 22},
+			},
+		},
+		{
+			"for directive iterator",
+			`name = "%{ for v in var }x%{ endfor }"
+`,
+			hcl.Pos{Line: 1, Column: 17, Byte: 16},
+			[]string{},
+			hcl.Range{},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(bodySchema)
+			d.SetEvalContext(evalCtx)
+
+			f, _ := hclsyntax.ParseConfig([]byte(tc.cfg), "test.tf", hcl.InitialPos)
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			candidates, err := d.CandidatesAtPos("test.tf", tc.pos)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			labels := make([]string, 0)
+			for _, c := range candidates.List {
+				labels = append(labels, c.Label)
+				if diff := cmp.Diff(tc.expectedRange, c.TextEdit.Range); diff != "" {
+					t.Fatalf("unexpected range for %q: %s", c.Label, diff)
+				}
+			}
+			if diff := cmp.Diff(tc.expectedLabels, labels); diff != "" {
+				t.Fatalf("unexpected candidates: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecoder_CandidatesAtPos_templateCandidateDetails(t *testing.T) {
+	d := NewDecoder()
+	d.SetSchema(&schema.BodySchema{})
+	d.SetEvalContext(&hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"env": cty.StringVal("prod"),
+		},
+		Functions: map[string]function.Function{
+			"join": stdlib.JoinFunc,
+		},
+	})
+
+	f, _ := hclsyntax.ParseConfig([]byte(`name = "${}"
+`), "test.tf", hcl.InitialPos)
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	candidates, err := d.CandidatesAtPos("test.tf", hcl.Pos{Line: 1, Column: 11, Byte: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rng := hcl.Range{
+		Filename: "test.tf",
+		Start:    hcl.Pos{Line: 1, Column: 11, Byte: 10},
+		End:      hcl.Pos{Line: 1, Column: 11, Byte: 10},
+	}
+	expectedCandidates := lang.CompleteCandidates([]lang.Candidate{
+		{
+			Label:  "env",
+			Detail: "string",
+			Kind:   lang.TraversalCandidateKind,
+			TextEdit: lang.TextEdit{
+				NewText: "env",
+				Snippet: "env",
+				Range:   rng,
+			},
+			SortText:   "0",
+			FilterText: "env",
+		},
+		{
+			Label: "false",
+			Kind:  lang.KeywordCandidateKind,
+			TextEdit: lang.TextEdit{
+				NewText: "false",
+				Snippet: "false",
+				Range:   rng,
+			},
+			SortText:   "1",
+			FilterText: "false",
+		},
+		{
+			Label:  "join",
+			Detail: "join(separator string, lists ...list of string)",
+			Kind:   lang.FunctionCandidateKind,
+			TextEdit: lang.TextEdit{
+				NewText: "join()",
+				Snippet: "join(${1})",
+				Range:   rng,
+			},
+			SortText:   "2",
+			FilterText: "join",
+		},
+		{
+			Label: "null",
+			Kind:  lang.KeywordCandidateKind,
+			TextEdit: lang.TextEdit{
+				NewText: "null",
+				Snippet: "null",
+				Range:   rng,
+			},
+			SortText:   "3",
+			FilterText: "null",
+		},
+		{
+			Label: "true",
+			Kind:  lang.KeywordCandidateKind,
+			TextEdit: lang.TextEdit{
+				NewText: "true",
+				Snippet: "true",
+				Range:   rng,
+			},
+			SortText:   "4",
+			FilterText: "true",
+		},
+	})
+	if diff := cmp.Diff(expectedCandidates, candidates); diff != "" {
+		t.Fatalf("unexpected candidates: %s", diff)
+	}
+}
+
+func TestDecoder_CandidatesAtPos_templateFunctionBeforeParen(t *testing.T) {
+	testCases := []struct {
+		name             string
+		cfg              string
+		pos              hcl.Pos
+		expectedTextEdit lang.TextEdit
+	}{
+		{
+			"end of identifier",
+			`name = "${jo(x)}"
+`,
+			hcl.Pos{Line: 1, Column: 13, Byte: 12},
+			lang.TextEdit{
+				NewText: "join",
+				Snippet: "join",
+				Range: hcl.Range{
+					Filename: "test.tf",
+					Start:    hcl.Pos{Line: 1, Column: 11, Byte: 10},
+					End:      hcl.Pos{Line: 1, Column: 13, Byte: 12},
+				},
+			},
+		},
+		{
+			"middle of identifier",
+			`name = "${join(x)}"
+`,
+			hcl.Pos{Line: 1, Column: 13, Byte: 12},
+			lang.TextEdit{
+				NewText: "join",
+				Snippet: "join",
+				Range: hcl.Range{
+					Filename: "test.tf",
+					Start:    hcl.Pos{Line: 1, Column: 11, Byte: 10},
+					End:      hcl.Pos{Line: 1, Column: 15, Byte: 14},
+				},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(&schema.BodySchema{})
+			d.SetEvalContext(&hcl.EvalContext{
+				Functions: map[string]function.Function{
+					"join": stdlib.JoinFunc,
+				},
+			})
+
+			f, _ := hclsyntax.ParseConfig([]byte(tc.cfg), "test.tf", hcl.InitialPos)
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			candidates, err := d.CandidatesAtPos("test.tf", tc.pos)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(candidates.List) != 1 {
+				t.Fatalf("expected 1 candidate, given: %#v", candidates.List)
+			}
+			if diff := cmp.Diff(tc.expectedTextEdit, candidates.List[0].TextEdit); diff != "" {
+				t.Fatalf("unexpected text edit: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecoder_CandidatesAtPos_templateNoSchema(t *testing.T) {
+	d := NewDecoder()
+	d.SetEvalContext(&hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"env": cty.StringVal("prod"),
+		},
+	})
+
+	f, _ := hclsyntax.ParseConfig([]byte(`name = "${}"
+`), "test.tf", hcl.InitialPos)
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = d.CandidatesAtPos("test.tf", hcl.Pos{Line: 1, Column: 11, Byte: 10})
+	noSchemaErr := &NoSchemaError{}
+	if !errors.As(err, &noSchemaErr) {
+		t.Fatal("expected NoSchemaError for no schema")
+	}
+}
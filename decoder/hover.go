@@ -28,7 +28,7 @@ func (d *Decoder) HoverAtPos(filename string, pos hcl.Pos) (*lang.HoverData, err
 		return nil, &NoSchemaError{}
 	}
 
-	data, err := d.hoverAtPos(rootBody, d.rootSchema, pos)
+	data, err := d.hoverAtPos(rootBody, schema.NewLayeredBodySchema(d.rootSchema), pos)
 	if err != nil {
 		return nil, err
 	}
@@ -36,8 +36,8 @@ func (d *Decoder) HoverAtPos(filename string, pos hcl.Pos) (*lang.HoverData, err
 	return data, nil
 }
 
-func (d *Decoder) hoverAtPos(body *hclsyntax.Body, bodySchema *schema.BodySchema, pos hcl.Pos) (*lang.HoverData, error) {
-	if bodySchema == nil {
+func (d *Decoder) hoverAtPos(body *hclsyntax.Body, bodySchema *schema.LayeredBodySchema, pos hcl.Pos) (*lang.HoverData, error) {
+	if bodySchema.IsEmpty() {
 		return nil, nil
 	}
 
@@ -45,7 +45,7 @@ func (d *Decoder) hoverAtPos(body *hclsyntax.Body, bodySchema *schema.BodySchema
 
 	for name, attr := range body.Attributes {
 		if attr.Range().ContainsPos(pos) {
-			aSchema, ok := bodySchema.Attributes[attr.Name]
+			aSchema, ok := bodySchema.Attribute(attr.Name)
 			if !ok {
 				return nil, &PositionalError{
 					Filename: filename,
@@ -54,6 +54,19 @@ func (d *Decoder) hoverAtPos(body *hclsyntax.Body, bodySchema *schema.BodySchema
 				}
 			}
 
+			if attr.Expr.Range().ContainsPos(pos) {
+				value, ok := stringLiteralValue(attr.Expr)
+				if ok {
+					av, ok := aSchema.AllowedValue(value)
+					if ok {
+						return &lang.HoverData{
+							Content: hoverContentForAllowedValue(av),
+							Range:   attr.Expr.Range(),
+						}, nil
+					}
+				}
+			}
+
 			return &lang.HoverData{
 				Content: hoverContentForAttribute(name, aSchema),
 				Range:   attr.Range(),
@@ -63,7 +76,7 @@ func (d *Decoder) hoverAtPos(body *hclsyntax.Body, bodySchema *schema.BodySchema
 
 	for _, block := range body.Blocks {
 		if block.Range().ContainsPos(pos) {
-			bSchema, ok := bodySchema.Blocks[block.Type]
+			bSchema, ok := bodySchema.Block(block.Type)
 			if !ok {
 				return nil, &PositionalError{
 					Filename: filename,
@@ -105,10 +118,7 @@ func (d *Decoder) hoverAtPos(body *hclsyntax.Body, bodySchema *schema.BodySchema
 			}
 
 			if block.Body != nil && block.Body.Range().ContainsPos(pos) {
-				mergedSchema, err := mergeBlockBodySchemas(block, bSchema)
-				if err != nil {
-					return nil, err
-				}
+				mergedSchema := d.mergeBlockBodySchemas(block, bSchema)
 
 				return d.hoverAtPos(block.Body, mergedSchema, pos)
 			}
@@ -129,7 +139,7 @@ func (d *Decoder) hoverContentForLabel(i int, block *hclsyntax.Block, bSchema *s
 
 	if labelSchema.IsDepKey {
 		dk := dependencyKeysFromBlock(block, bSchema)
-		bs, ok := bSchema.DependentBodySchema(dk)
+		bs, ok := d.dependentBodySchema(bSchema, dk)
 		if ok {
 			content := fmt.Sprintf("`%s`", value)
 			if bs.Detail != "" {
@@ -179,6 +189,20 @@ func hoverContentForAttribute(name string, schema *schema.AttributeSchema) lang.
 	}
 }
 
+func hoverContentForAllowedValue(av schema.AllowedValue) lang.MarkupContent {
+	value := fmt.Sprintf("`%q`", av.Value)
+	if av.IsDeprecated {
+		value += " _deprecated_"
+	}
+	if av.Description.Value != "" {
+		value += fmt.Sprintf("\n\n%s", av.Description.Value)
+	}
+	return lang.MarkupContent{
+		Kind:  lang.MarkdownKind,
+		Value: value,
+	}
+}
+
 func hoverContentForBlock(bType string, schema *schema.BlockSchema) lang.MarkupContent {
 	value := fmt.Sprintf("**%s** _%s_", bType, detailForBlock(schema))
 	if schema.Description.Value != "" {
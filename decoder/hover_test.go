@@ -388,3 +388,48 @@ func TestDecoder_HoverAtPos_basic(t *testing.T) {
 		})
 	}
 }
+
+func TestDecoder_HoverAtPos_allowedValue(t *testing.T) {
+	bodySchema := &schema.BodySchema{
+		Attributes: map[string]*schema.AttributeSchema{
+			"tier": {
+				ValueType:  cty.String,
+				IsOptional: true,
+				AllowedValues: []schema.AllowedValue{
+					{Value: "Basic"},
+					{
+						Value:        "Premium",
+						Description:  lang.Markdown("Premium tier with _geo-replication_"),
+						IsDeprecated: true,
+					},
+				},
+			},
+		},
+	}
+	testConfig := []byte(`tier = "Premium"
+`)
+	d := NewDecoder()
+	d.SetSchema(bodySchema)
+
+	f, _ := hclsyntax.ParseConfig(testConfig, "test.tf", hcl.InitialPos)
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := d.HoverAtPos("test.tf", hcl.Pos{Line: 1, Column: 11, Byte: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedData := &lang.HoverData{
+		Content: lang.Markdown("`\"Premium\"` _deprecated_\n\nPremium tier with _geo-replication_"),
+		Range: hcl.Range{
+			Filename: "test.tf",
+			Start:    hcl.Pos{Line: 1, Column: 8, Byte: 7},
+			End:      hcl.Pos{Line: 1, Column: 17, Byte: 16},
+		},
+	}
+	if diff := cmp.Diff(expectedData, data, ctydebug.CmpOptions); diff != "" {
+		t.Fatalf("hover data mismatch: %s", diff)
+	}
+}
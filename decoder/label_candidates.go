@@ -1,66 +1,256 @@
 package decoder
 
 import (
-	"encoding/json"
-	"sort"
+	"bytes"
+	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/hashicorp/hcl-lang/lang"
 	"github.com/hashicorp/hcl-lang/schema"
 	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 )
 
-func (d *Decoder) labelCandidatesFromDependentSchema(idx int, db map[schema.SchemaKey]*schema.BodySchema, prefixRng, editRng hcl.Range) (lang.Candidates, error) {
-	candidates := lang.NewCandidates()
-	count := 0
-
+// labelCandidatesFromDependentSchema returns candidates for the label
+// at the given index, based on label values of dependent bodies.
+//
+// Values starting with the prefix are looked up in the index first
+// and all values are only scored via fuzzy matching when none
+// of them starts with the prefix, as there can be many
+// (e.g. thousands of resource types).
+func (d *Decoder) labelCandidatesFromDependentSchema(idx int, block *hclsyntax.Block, blockSchema *schema.BlockSchema, prefixRng, editRng hcl.Range, req candidatesRequest) (lang.Candidates, error) {
 	prefix, _ := d.bytesFromRange(prefixRng)
 
-	for schemaKey, bodySchema := range db {
-		depKeys, err := decodeSchemaKey(schemaKey)
-		if err != nil {
-			// key undecodable
-			continue
+	ranked := make([]rankedCandidate, 0)
+
+	// the index yields values in order, with duplicates (values
+	// of more specific bodies) next to each other, so only
+	// the last candidate needs to be compared
+	lastKeyCount, lastDeprecated := 0, false
+	// number of values which are known to rank before any
+	// values yet to be looked up (with no prefix)
+	rankedFirst := 0
+
+	collect := func(lv schema.LabelValue) bool {
+		if !req.isRequested(lv.Value) || !otherLabelsMatch(idx, lv.Keys.Labels, block) {
+			return true
+		}
+		score, ok := fuzzyMatchScore(string(prefix), lv.Value)
+		if !ok {
+			return true
 		}
 
-		for _, label := range depKeys.Labels {
-			if label.Index == idx {
-				if uint(count) >= d.maxCandidates {
-					// reached maximum no of candidates
-					return candidates, nil
-				}
-				if len(prefix) > 0 && !strings.HasPrefix(label.Value, string(prefix)) {
-					continue
+		keyCount := len(lv.Keys.Labels) + len(lv.Keys.Attributes)
+		isDuplicate := len(ranked) > 0 && ranked[len(ranked)-1].label == lv.Value
+		if isDuplicate && keyCount >= lastKeyCount {
+			// prefer the least specific body to describe the value
+			return true
+		}
+		if !isDuplicate && len(ranked) > 0 && !lastDeprecated {
+			rankedFirst++
+		}
+
+		value, depBody := lv.Value, lv.Body
+		rc := rankedCandidate{
+			label: value,
+			score: score + metadataScore(false, depBody.IsDeprecated),
+			candidate: func() lang.Candidate {
+				textEdit, triggerSuggest := d.labelTextEdit(idx, block, blockSchema, value, editRng)
+
+				return lang.Candidate{
+					Label:          value,
+					Kind:           lang.LabelCandidateKind,
+					IsDeprecated:   depBody.IsDeprecated,
+					TextEdit:       textEdit,
+					TriggerSuggest: triggerSuggest,
 				}
-				candidates.List = append(candidates.List, lang.Candidate{
-					Label:        label.Value,
-					Kind:         lang.LabelCandidateKind,
-					IsDeprecated: bodySchema.IsDeprecated,
-					TextEdit: lang.TextEdit{
-						NewText: label.Value,
-						Snippet: label.Value,
-						Range:   editRng,
-					},
-					// TODO: AdditionalTextEdits (required fields if body is empty)
-					Detail:      bodySchema.Detail,
-					Description: bodySchema.Description,
-				})
-			}
+			},
+			details: func(c *lang.Candidate) {
+				c.AdditionalTextEdits = d.requiredFieldsTextEdits(block, blockSchema, depBody)
+				c.Detail = depBody.Detail
+				c.Description = depBody.Description
+			},
+		}
+
+		lastKeyCount, lastDeprecated = keyCount, depBody.IsDeprecated
+		if isDuplicate {
+			ranked[len(ranked)-1] = rc
+			return true
 		}
+		ranked = append(ranked, rc)
+
+		// with no prefix, all values score equally and only deprecated
+		// ones rank last, i.e. values are looked up in the order
+		// of ranking, so there is no need to look up values beyond
+		// the requested page (and the one proving there are more)
+		return len(prefix) > 0 || !req.isFull(rankedFirst)
 	}
 
-	candidates.IsComplete = true
+	lookupPrefix := string(prefix)
+	if req.label != "" {
+		// only the requested value needs to be looked up
+		lookupPrefix = req.label
+	}
 
-	// TODO: sort by more metadata, such as IsDeprecated
-	sort.Slice(candidates.List, func(i, j int) bool {
-		return candidates.List[i].Label < candidates.List[j].Label
-	})
+	depIndex := d.dependentBodyIndex(blockSchema)
+	depIndex.LabelValues(idx, lookupPrefix, collect)
+	if len(ranked) == 0 && len(prefix) > 0 && req.label == "" {
+		depIndex.LabelValues(idx, "", collect)
+	}
+
+	return rankCandidates(ranked, req), nil
+}
+
+// otherLabelsMatch returns true if all label dependencies other than
+// the one at the given index match labels already declared in the block.
+// Labels which are empty (not yet typed) match any value.
+func otherLabelsMatch(idx int, labels []schema.LabelDependent, block *hclsyntax.Block) bool {
+	if block == nil {
+		return true
+	}
+	for _, ld := range labels {
+		if ld.Index == idx || ld.Index >= len(block.Labels) {
+			continue
+		}
+		if block.Labels[ld.Index] != "" && block.Labels[ld.Index] != ld.Value {
+			return false
+		}
+	}
+	return true
+}
+
+// labelTextEdit returns edit inserting the label value at the given index.
+//
+// If the label is immediately followed by another dependency key label,
+// the edit spans up to that label, such that the cursor ends up there
+// after insertion and suggestion can be triggered for it.
+func (d *Decoder) labelTextEdit(idx int, block *hclsyntax.Block, blockSchema *schema.BlockSchema, value string, editRng hcl.Range) (lang.TextEdit, bool) {
+	textEdit := lang.TextEdit{
+		NewText: value,
+		Snippet: value,
+		Range:   editRng,
+	}
+
+	nextIdx := idx + 1
+	if block == nil || nextIdx >= len(blockSchema.Labels) || nextIdx >= len(block.LabelRanges) {
+		return textEdit, false
+	}
+	if !blockSchema.Labels[nextIdx].IsDepKey {
+		return textEdit, false
+	}
 
-	return candidates, nil
+	nextRng := block.LabelRanges[nextIdx]
+	nextLabel, err := d.bytesFromRange(nextRng)
+	if err != nil || len(nextLabel) < 2 || nextLabel[0] != '"' || nextLabel[len(nextLabel)-1] != '"' {
+		// only quoted labels are supported
+		return textEdit, false
+	}
+
+	// end the edit right before the closing quote of the next label
+	rng := editRng
+	rng.End = hcl.Pos{
+		Line:   nextRng.End.Line,
+		Column: nextRng.End.Column - 1,
+		Byte:   nextRng.End.Byte - 1,
+	}
+
+	nextValue := block.Labels[nextIdx]
+	textEdit.Range = rng
+	textEdit.NewText = fmt.Sprintf(`%s" "%s`, value, nextValue)
+	if nextValue == "" {
+		textEdit.Snippet = fmt.Sprintf(`%s" "${1}`, value)
+	} else {
+		textEdit.Snippet = fmt.Sprintf(`%s" "${1:%s}`, value, escapeSnippetPlaceholder(nextValue))
+	}
+
+	return textEdit, true
+}
+
+func escapeSnippetPlaceholder(value string) string {
+	r := strings.NewReplacer(
+		`\`, `\\`,
+		`}`, `\}`,
+		`$`, `\$`,
+	)
+	return r.Replace(value)
+}
+
+// requiredFieldsTextEdits returns edits inserting required attributes
+// and blocks of the dependent body which are not yet declared in the block
+func (d *Decoder) requiredFieldsTextEdits(block *hclsyntax.Block, blockSchema *schema.BlockSchema, depBody *schema.BodySchema) []lang.TextEdit {
+	if block == nil || block.Body == nil {
+		return nil
+	}
+
+	blockIndent := d.lineIndentAtPos(block.TypeRange.Filename, block.TypeRange.Start)
+	bodySchema := schema.NewLayeredBodySchema(blockSchema.Body, depBody)
+
+	snippet, _ := snippetForRequiredBody(bodySchema, block.Body, 1, blockIndent+"  ",
+		[]*schema.BlockSchema{blockSchema})
+	text := snippetToText(snippet)
+	if text == "" {
+		return nil
+	}
+
+	newText := "\n" + strings.TrimSuffix(text, "\n")
+	if block.OpenBraceRange.End.Line == block.CloseBraceRange.Start.Line {
+		newText += "\n" + blockIndent
+	}
+
+	return []lang.TextEdit{
+		{
+			Range: hcl.Range{
+				Filename: block.OpenBraceRange.Filename,
+				Start:    block.OpenBraceRange.End,
+				End:      block.OpenBraceRange.End,
+			},
+			NewText: newText,
+			Snippet: newText,
+		},
+	}
+}
+
+// lineIndentAtPos returns leading whitespace
+// of the line (up to the given position)
+func (d *Decoder) lineIndentAtPos(filename string, pos hcl.Pos) string {
+	b, err := d.bytesForFile(filename)
+	if err != nil || pos.Byte > len(b) {
+		return ""
+	}
+
+	lineStart := bytes.LastIndexByte(b[:pos.Byte], '\n') + 1
+	line := b[lineStart:pos.Byte]
+	indentLen := len(line) - len(bytes.TrimLeft(line, " \t"))
+
+	return string(line[:indentLen])
 }
 
-func decodeSchemaKey(key schema.SchemaKey) (schema.DependencyKeys, error) {
-	var dk schema.DependencyKeys
-	err := json.Unmarshal([]byte(key), &dk)
-	return dk, err
+var (
+	snippetDefaultRegexp     = regexp.MustCompile(`\$\{\d+:([^}]*)\}`)
+	snippetChoiceRegexp      = regexp.MustCompile(`\$\{\d+\|((?:[^,|\\]|\\.)*)(?:,(?:[^|\\]|\\.)*)?\|\}`)
+	snippetPlaceholderRegexp = regexp.MustCompile(`\$\{\d+\}`)
+	snippetEscapeRegexp      = regexp.MustCompile(`\\(.)`)
+)
+
+// snippetToText turns snippet into plain text, replacing placeholders
+// with their default values (or first choice) and dropping
+// lines which only consisted of an empty placeholder
+func snippetToText(snippet string) string {
+	text := snippetDefaultRegexp.ReplaceAllString(snippet, "$1")
+	text = snippetChoiceRegexp.ReplaceAllStringFunc(text, func(choice string) string {
+		m := snippetChoiceRegexp.FindStringSubmatch(choice)
+		return snippetEscapeRegexp.ReplaceAllString(m[1], "$1")
+	})
+
+	lines := strings.SplitAfter(text, "\n")
+	var sb strings.Builder
+	for _, line := range lines {
+		if snippetPlaceholderRegexp.MatchString(line) &&
+			strings.TrimSpace(snippetPlaceholderRegexp.ReplaceAllString(line, "")) == "" {
+			continue
+		}
+		sb.WriteString(snippetPlaceholderRegexp.ReplaceAllString(line, ""))
+	}
+	return sb.String()
 }
@@ -0,0 +1,352 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+func TestDecoder_CandidatesAtPos_multipleDepKeyLabels(t *testing.T) {
+	connectorSchema := &schema.BlockSchema{
+		Labels: []*schema.LabelSchema{
+			{Name: "type", IsDepKey: true},
+			{Name: "kind", IsDepKey: true},
+			{Name: "name"},
+		},
+		DependentBody: map[schema.SchemaKey]*schema.BodySchema{
+			schema.NewSchemaKey(schema.DependencyKeys{
+				Labels: []schema.LabelDependent{
+					{Index: 0, Value: "kafka"},
+					{Index: 1, Value: "sink"},
+				},
+			}): {Detail: "Kafka sink"},
+			schema.NewSchemaKey(schema.DependencyKeys{
+				Labels: []schema.LabelDependent{
+					{Index: 0, Value: "s3"},
+					{Index: 1, Value: "source"},
+				},
+			}): {Detail: "S3 source"},
+		},
+	}
+	bodySchema := &schema.BodySchema{
+		Blocks: map[string]*schema.BlockSchema{
+			"connector": connectorSchema,
+		},
+	}
+
+	testCases := []struct {
+		name               string
+		cfg                string
+		pos                hcl.Pos
+		expectedCandidates lang.Candidates
+	}{
+		{
+			"first label followed by dependency key label",
+			`connector "" "" "example" {
+}
+`,
+			hcl.Pos{Line: 1, Column: 12, Byte: 11},
+			lang.CompleteCandidates([]lang.Candidate{
+				{
+					Label:  "kafka",
+					Detail: "Kafka sink",
+					Kind:   lang.LabelCandidateKind,
+					TextEdit: lang.TextEdit{
+						Range: hcl.Range{
+							Filename: "test.tf",
+							Start:    hcl.Pos{Line: 1, Column: 12, Byte: 11},
+							End:      hcl.Pos{Line: 1, Column: 15, Byte: 14},
+						},
+						NewText: `kafka" "`,
+						Snippet: `kafka" "${1}`,
+					},
+					TriggerSuggest: true,
+					SortText:       "0",
+					FilterText:     "kafka",
+				},
+				{
+					Label:  "s3",
+					Detail: "S3 source",
+					Kind:   lang.LabelCandidateKind,
+					TextEdit: lang.TextEdit{
+						Range: hcl.Range{
+							Filename: "test.tf",
+							Start:    hcl.Pos{Line: 1, Column: 12, Byte: 11},
+							End:      hcl.Pos{Line: 1, Column: 15, Byte: 14},
+						},
+						NewText: `s3" "`,
+						Snippet: `s3" "${1}`,
+					},
+					TriggerSuggest: true,
+					SortText:       "1",
+					FilterText:     "s3",
+				},
+			}),
+		},
+		{
+			"second label filtered by first label",
+			`connector "s3" "" "example" {
+}
+`,
+			hcl.Pos{Line: 1, Column: 17, Byte: 16},
+			lang.CompleteCandidates([]lang.Candidate{
+				{
+					Label:  "source",
+					Detail: "S3 source",
+					Kind:   lang.LabelCandidateKind,
+					TextEdit: lang.TextEdit{
+						Range: hcl.Range{
+							Filename: "test.tf",
+							Start:    hcl.Pos{Line: 1, Column: 17, Byte: 16},
+							End:      hcl.Pos{Line: 1, Column: 17, Byte: 16},
+						},
+						NewText: "source",
+						Snippet: "source",
+					},
+					SortText:   "0",
+					FilterText: "source",
+				},
+			}),
+		},
+		{
+			"first label preserving second label",
+			`connector "ka" "sink" "example" {
+}
+`,
+			hcl.Pos{Line: 1, Column: 14, Byte: 13},
+			lang.CompleteCandidates([]lang.Candidate{
+				{
+					Label:  "kafka",
+					Detail: "Kafka sink",
+					Kind:   lang.LabelCandidateKind,
+					TextEdit: lang.TextEdit{
+						Range: hcl.Range{
+							Filename: "test.tf",
+							Start:    hcl.Pos{Line: 1, Column: 12, Byte: 11},
+							End:      hcl.Pos{Line: 1, Column: 21, Byte: 20},
+						},
+						NewText: `kafka" "sink`,
+						Snippet: `kafka" "${1:sink}`,
+					},
+					TriggerSuggest: true,
+					SortText:       "0",
+					FilterText:     "kafka",
+				},
+			}),
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(bodySchema)
+
+			f, pDiags := hclsyntax.ParseConfig([]byte(tc.cfg), "test.tf", hcl.InitialPos)
+			if len(pDiags) > 0 {
+				t.Fatal(pDiags)
+			}
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			candidates, err := d.CandidatesAtPos("test.tf", tc.pos)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expectedCandidates, candidates); diff != "" {
+				t.Fatalf("unexpected candidates: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecoder_CandidatesAtPos_labelsFilteredByOtherLabels(t *testing.T) {
+	connectorSchema := &schema.BlockSchema{
+		Labels: []*schema.LabelSchema{
+			{Name: "type", IsDepKey: true},
+			{Name: "kind", IsDepKey: true},
+			{Name: "name"},
+		},
+		DependentBody: map[schema.SchemaKey]*schema.BodySchema{
+			schema.NewSchemaKey(schema.DependencyKeys{
+				Labels: []schema.LabelDependent{
+					{Index: 0, Value: "kafka"},
+				},
+			}): {Detail: "Kafka"},
+			schema.NewSchemaKey(schema.DependencyKeys{
+				Labels: []schema.LabelDependent{
+					{Index: 0, Value: "kafka"},
+					{Index: 1, Value: "sink"},
+				},
+			}): {Detail: "Kafka sink"},
+			schema.NewSchemaKey(schema.DependencyKeys{
+				Labels: []schema.LabelDependent{
+					{Index: 0, Value: "kafka"},
+					{Index: 1, Value: "source"},
+				},
+			}): {Detail: "Kafka source"},
+			schema.NewSchemaKey(schema.DependencyKeys{
+				Labels: []schema.LabelDependent{
+					{Index: 0, Value: "s3"},
+					{Index: 1, Value: "sink"},
+				},
+			}): {Detail: "S3 sink"},
+		},
+	}
+	bodySchema := &schema.BodySchema{
+		Blocks: map[string]*schema.BlockSchema{
+			"connector": connectorSchema,
+		},
+	}
+
+	testCases := []struct {
+		name            string
+		cfg             string
+		pos             hcl.Pos
+		expectedDetails map[string]string
+	}{
+		{
+			"first label with no other labels",
+			`connector "" "" "example" {}`,
+			hcl.Pos{Line: 1, Column: 12, Byte: 11},
+			map[string]string{
+				"kafka": "Kafka",
+				"s3":    "S3 sink",
+			},
+		},
+		{
+			"first label filtered by second label",
+			`connector "" "source" "example" {}`,
+			hcl.Pos{Line: 1, Column: 12, Byte: 11},
+			map[string]string{
+				"kafka": "Kafka",
+			},
+		},
+		{
+			"second label with no other labels",
+			`connector "" "" "example" {}`,
+			hcl.Pos{Line: 1, Column: 15, Byte: 14},
+			map[string]string{
+				"sink":   "Kafka sink",
+				"source": "Kafka source",
+			},
+		},
+		{
+			"second label filtered by first label",
+			`connector "s3" "" "example" {}`,
+			hcl.Pos{Line: 1, Column: 17, Byte: 16},
+			map[string]string{
+				"sink": "S3 sink",
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(bodySchema)
+
+			f, pDiags := hclsyntax.ParseConfig([]byte(tc.cfg), "test.tf", hcl.InitialPos)
+			if len(pDiags) > 0 {
+				t.Fatal(pDiags)
+			}
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			candidates, err := d.CandidatesAtPos("test.tf", tc.pos)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			details := make(map[string]string, 0)
+			for _, c := range candidates.List {
+				if _, ok := details[c.Label]; ok {
+					t.Fatalf("duplicate candidate: %q", c.Label)
+				}
+				details[c.Label] = c.Detail
+			}
+			if diff := cmp.Diff(tc.expectedDetails, details); diff != "" {
+				t.Fatalf("unexpected candidates: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecoder_CandidatesAtPos_labelsPrefixFirst(t *testing.T) {
+	resourceSchema := &schema.BlockSchema{
+		Labels: []*schema.LabelSchema{
+			{Name: "type", IsDepKey: true},
+			{Name: "name"},
+		},
+		DependentBody: map[schema.SchemaKey]*schema.BodySchema{},
+	}
+	for _, value := range []string{"aws_instance", "aws_ami", "azurerm_vm", "google_aws_bridge"} {
+		resourceSchema.DependentBody[schema.NewSchemaKey(schema.DependencyKeys{
+			Labels: []schema.LabelDependent{
+				{Index: 0, Value: value},
+			},
+		})] = &schema.BodySchema{}
+	}
+	bodySchema := &schema.BodySchema{
+		Blocks: map[string]*schema.BlockSchema{
+			"resource": resourceSchema,
+		},
+	}
+
+	testCases := []struct {
+		name           string
+		cfg            string
+		pos            hcl.Pos
+		expectedLabels []string
+	}{
+		{
+			"prefix matches only",
+			`resource "aws" "example" {}`,
+			hcl.Pos{Line: 1, Column: 14, Byte: 13},
+			[]string{"aws_ami", "aws_instance"},
+		},
+		{
+			"fuzzy matches without prefix match",
+			`resource "ainst" "example" {}`,
+			hcl.Pos{Line: 1, Column: 16, Byte: 15},
+			[]string{"aws_instance"},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(bodySchema)
+
+			f, pDiags := hclsyntax.ParseConfig([]byte(tc.cfg), "test.tf", hcl.InitialPos)
+			if len(pDiags) > 0 {
+				t.Fatal(pDiags)
+			}
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			candidates, err := d.CandidatesAtPos("test.tf", tc.pos)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			labels := make([]string, 0)
+			for _, c := range candidates.List {
+				labels = append(labels, c.Label)
+			}
+			if diff := cmp.Diff(tc.expectedLabels, labels); diff != "" {
+				t.Fatalf("unexpected candidates: %s", diff)
+			}
+		})
+	}
+}
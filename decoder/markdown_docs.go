@@ -0,0 +1,225 @@
+package decoder
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl-lang/schema"
+)
+
+// maxHeadingLevel represents the deepest heading level supported by Markdown
+const maxHeadingLevel = 6
+
+// MarkdownDocs returns reference documentation generated from the schema,
+// in the form of one Markdown page per top-level block type
+// (keyed by block type).
+//
+// Each page describes labels, attributes, nested blocks and variants
+// of the block body as defined by DependentBody.
+func (d *Decoder) MarkdownDocs() (map[string]string, error) {
+	d.rootSchemaMu.RLock()
+	defer d.rootSchemaMu.RUnlock()
+
+	if d.rootSchema == nil {
+		return nil, &NoSchemaError{}
+	}
+
+	pages := make(map[string]string, len(d.rootSchema.Blocks))
+	for bType, block := range d.rootSchema.Blocks {
+		var sb strings.Builder
+		d.writeBlockDocs(&sb, 1, bType, block, map[*schema.BlockSchema]string{})
+		pages[bType] = sb.String()
+	}
+
+	return pages, nil
+}
+
+// writeBlockDocs writes docs of the block and its body.
+//
+// parents maps schemas of the enclosing blocks to their types,
+// such that recursive blocks only refer to the block
+// which is already being documented.
+func (d *Decoder) writeBlockDocs(sb *strings.Builder, level int, bType string, block *schema.BlockSchema, parents map[*schema.BlockSchema]string) {
+	writeHeading(sb, level, fmt.Sprintf("`%s` Block", bType))
+	if parentType, ok := parents[block]; ok {
+		fmt.Fprintf(sb, "_Recursive, see the `%s` block above_\n\n", parentType)
+		return
+	}
+	parents[block] = bType
+	defer delete(parents, block)
+
+	fmt.Fprintf(sb, "_%s_\n\n", detailForBlock(block))
+
+	if block.IsDeprecated {
+		sb.WriteString("**Deprecated**\n\n")
+	}
+	if block.Description.Value != "" {
+		fmt.Fprintf(sb, "%s\n\n", block.Description.Value)
+	}
+
+	if len(block.Labels) > 0 {
+		writeHeading(sb, level+1, "Labels")
+		sb.WriteString("| Index | Name | Description |\n")
+		sb.WriteString("|-------|------|-------------|\n")
+		for i, label := range block.Labels {
+			fmt.Fprintf(sb, "| %d | `%s` | %s |\n", i,
+				label.Name, escapeTableCell(label.Description.Value))
+		}
+		sb.WriteString("\n")
+	}
+
+	if block.Body != nil {
+		d.writeBodyDocs(sb, level+1, block.Body, parents)
+	}
+
+	keys := make([]string, 0, len(block.DependentBody))
+	for key, depBody := range block.DependentBody {
+		if depBody == nil {
+			continue
+		}
+		keys = append(keys, string(key))
+	}
+	sort.Strings(keys)
+
+	if len(keys) > 0 {
+		writeHeading(sb, level+1, "Variants")
+	}
+	for _, key := range keys {
+		depBody := block.DependentBody[schema.SchemaKey(key)]
+		writeHeading(sb, level+2, fmt.Sprintf("`%s`", variantTitle(bType, block, schema.SchemaKey(key))))
+
+		if depBody.Detail != "" {
+			fmt.Fprintf(sb, "_%s_\n\n", depBody.Detail)
+		}
+		d.writeBodyDocs(sb, level+3, depBody, parents)
+	}
+}
+
+func (d *Decoder) writeBodyDocs(sb *strings.Builder, level int, body *schema.BodySchema, parents map[*schema.BlockSchema]string) {
+	if body.IsDeprecated {
+		sb.WriteString("**Deprecated**\n\n")
+	}
+	if body.Description.Value != "" {
+		fmt.Fprintf(sb, "%s\n\n", body.Description.Value)
+	}
+	if body.DocsLink != nil {
+		u, err := d.docsURL(body.DocsLink.URL, "markdownDocs")
+		if err == nil {
+			title := body.DocsLink.Tooltip
+			if title == "" {
+				title = "Documentation"
+			}
+			fmt.Fprintf(sb, "[%s](%s)\n\n", title, u.String())
+		}
+	}
+
+	names := make([]string, 0, len(body.Attributes))
+	for name := range body.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if len(names) > 0 || body.AnyAttribute != nil {
+		writeHeading(sb, level, "Attributes")
+		sb.WriteString("| Name | Type | Required | Deprecated | Description |\n")
+		sb.WriteString("|------|------|----------|------------|-------------|\n")
+		for _, name := range names {
+			writeAttributeRow(sb, fmt.Sprintf("`%s`", name), body.Attributes[name])
+		}
+		if body.AnyAttribute != nil {
+			writeAttributeRow(sb, "_any_", body.AnyAttribute)
+		}
+		sb.WriteString("\n")
+	}
+
+	bTypes := make([]string, 0, len(body.Blocks))
+	for bType := range body.Blocks {
+		bTypes = append(bTypes, bType)
+	}
+	sort.Strings(bTypes)
+
+	if len(bTypes) > 0 {
+		writeHeading(sb, level, "Blocks")
+	}
+	for _, bType := range bTypes {
+		if body.Blocks[bType] == nil {
+			continue
+		}
+		d.writeBlockDocs(sb, level+1, bType, body.Blocks[bType], parents)
+	}
+}
+
+func writeAttributeRow(sb *strings.Builder, name string, attr *schema.AttributeSchema) {
+	fmt.Fprintf(sb, "| %s | %s | %s | %s | %s |\n",
+		name,
+		escapeTableCell(typeNameForAttribute(attr)),
+		yesOrEmpty(attr.IsRequired),
+		yesOrEmpty(attr.IsDeprecated),
+		escapeTableCell(attr.Description.Value))
+}
+
+func writeHeading(sb *strings.Builder, level int, title string) {
+	if level > maxHeadingLevel {
+		level = maxHeadingLevel
+	}
+	fmt.Fprintf(sb, "%s %s\n\n", strings.Repeat("#", level), title)
+}
+
+// variantTitle returns human-readable representation of the dependent body
+// key, e.g. resource "aws_instance" (provider = aws.west)
+func variantTitle(bType string, block *schema.BlockSchema, key schema.SchemaKey) string {
+	var dks schema.DependencyKeys
+	err := json.Unmarshal([]byte(key), &dks)
+	if err != nil {
+		return string(key)
+	}
+
+	title := bType
+	labels := make([]string, len(block.Labels))
+	for _, ld := range dks.Labels {
+		if ld.Index < len(labels) {
+			labels[ld.Index] = ld.Value
+		}
+	}
+	for i, label := range labels {
+		if label == "" {
+			if i >= len(block.Labels) || block.Labels[i].Name == "" {
+				label = "*"
+			} else {
+				label = block.Labels[i].Name
+			}
+			title += fmt.Sprintf(" <%s>", label)
+			continue
+		}
+		title += fmt.Sprintf(" %q", label)
+	}
+
+	attrs := make([]string, 0, len(dks.Attributes))
+	for _, ad := range dks.Attributes {
+		value, ok := expressionValueText(ad.Expr)
+		if !ok {
+			continue
+		}
+		attrs = append(attrs, fmt.Sprintf("%s = %s", ad.Name, value))
+	}
+	if len(attrs) > 0 {
+		title += fmt.Sprintf(" (%s)", strings.Join(attrs, ", "))
+	}
+
+	return title
+}
+
+func escapeTableCell(value string) string {
+	value = strings.ReplaceAll(value, "|", `\|`)
+	value = strings.ReplaceAll(value, "\r\n", "<br>")
+	return strings.ReplaceAll(value, "\n", "<br>")
+}
+
+func yesOrEmpty(value bool) string {
+	if value {
+		return "yes"
+	}
+	return ""
+}
@@ -0,0 +1,246 @@
+package decoder
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestDecoder_MarkdownDocs_noSchema(t *testing.T) {
+	d := NewDecoder()
+
+	_, err := d.MarkdownDocs()
+	noSchemaErr := &NoSchemaError{}
+	if !errors.As(err, &noSchemaErr) {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+}
+
+func TestDecoder_MarkdownDocs(t *testing.T) {
+	resourceSchema := &schema.BlockSchema{
+		Type:        schema.BlockTypeList,
+		Description: lang.Markdown("Each resource block describes one or more infrastructure objects"),
+		Labels: []*schema.LabelSchema{
+			{
+				Name:        "type",
+				Description: lang.PlainText("Resource type"),
+				IsDepKey:    true,
+			},
+			{
+				Name:        "name",
+				Description: lang.PlainText("Reference name"),
+			},
+		},
+		Body: &schema.BodySchema{
+			Attributes: map[string]*schema.AttributeSchema{
+				"count": {
+					ValueType:   cty.Number,
+					IsOptional:  true,
+					Description: lang.PlainText("Number of instances | copies"),
+				},
+			},
+			Blocks: map[string]*schema.BlockSchema{
+				"lifecycle": {
+					Type:     schema.BlockTypeObject,
+					MaxItems: 1,
+					Body: &schema.BodySchema{
+						Attributes: map[string]*schema.AttributeSchema{
+							"create_before_destroy": {
+								ValueType:  cty.Bool,
+								IsOptional: true,
+							},
+						},
+					},
+				},
+			},
+		},
+		DependentBody: map[schema.SchemaKey]*schema.BodySchema{
+			schema.NewSchemaKey(schema.DependencyKeys{
+				Labels: []schema.LabelDependent{
+					{Index: 0, Value: "aws_instance"},
+				},
+			}): {
+				Detail: "hashicorp/aws",
+				DocsLink: &schema.DocsLink{
+					URL: "https://example.com/aws_instance",
+				},
+				Attributes: map[string]*schema.AttributeSchema{
+					"ami": {
+						ValueType:  cty.String,
+						IsRequired: true,
+					},
+					"legacy": {
+						ValueTypes:   schema.ValueTypes{cty.String, cty.Number},
+						IsOptional:   true,
+						IsDeprecated: true,
+					},
+				},
+			},
+			schema.NewSchemaKey(schema.DependencyKeys{
+				Labels: []schema.LabelDependent{
+					{Index: 0, Value: "aws_instance"},
+				},
+				Attributes: []schema.AttributeDependent{
+					{
+						Name: "provider",
+						Expr: schema.ExpressionValue{
+							Reference: lang.Reference{
+								lang.RootStep{Name: "aws"},
+								lang.AttrStep{Name: "west"},
+							},
+						},
+					},
+				},
+			}): {
+				Description: lang.PlainText("AWS instance in the west region"),
+			},
+		},
+	}
+
+	d := NewDecoder()
+	d.SetSchema(&schema.BodySchema{
+		Blocks: map[string]*schema.BlockSchema{
+			"resource": resourceSchema,
+			"locals": {
+				IsDeprecated: true,
+				Body: &schema.BodySchema{
+					AnyAttribute: &schema.AttributeSchema{
+						ValueType:  cty.DynamicPseudoType,
+						IsOptional: true,
+					},
+				},
+			},
+		},
+	})
+
+	pages, err := d.MarkdownDocs()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedPages := map[string]string{
+		"locals": "# `locals` Block\n\n" +
+			"_Block_\n\n" +
+			"**Deprecated**\n\n" +
+			"## Attributes\n\n" +
+			"| Name | Type | Required | Deprecated | Description |\n" +
+			"|------|------|----------|------------|-------------|\n" +
+			"| _any_ | dynamic |  |  |  |\n\n",
+		"resource": "# `resource` Block\n\n" +
+			"_Block, list_\n\n" +
+			"Each resource block describes one or more infrastructure objects\n\n" +
+			"## Labels\n\n" +
+			"| Index | Name | Description |\n" +
+			"|-------|------|-------------|\n" +
+			"| 0 | `type` | Resource type |\n" +
+			"| 1 | `name` | Reference name |\n\n" +
+			"## Attributes\n\n" +
+			"| Name | Type | Required | Deprecated | Description |\n" +
+			"|------|------|----------|------------|-------------|\n" +
+			"| `count` | number |  |  | Number of instances \\| copies |\n\n" +
+			"## Blocks\n\n" +
+			"### `lifecycle` Block\n\n" +
+			"_Block, object, max: 1_\n\n" +
+			"#### Attributes\n\n" +
+			"| Name | Type | Required | Deprecated | Description |\n" +
+			"|------|------|----------|------------|-------------|\n" +
+			"| `create_before_destroy` | bool |  |  |  |\n\n" +
+			"## Variants\n\n" +
+			"### `resource \"aws_instance\" <name> (provider = aws.west)`\n\n" +
+			"AWS instance in the west region\n\n" +
+			"### `resource \"aws_instance\" <name>`\n\n" +
+			"_hashicorp/aws_\n\n" +
+			"[Documentation](https://example.com/aws_instance)\n\n" +
+			"#### Attributes\n\n" +
+			"| Name | Type | Required | Deprecated | Description |\n" +
+			"|------|------|----------|------------|-------------|\n" +
+			"| `ami` | string | yes |  |  |\n" +
+			"| `legacy` | string or number |  | yes |  |\n\n",
+	}
+
+	if diff := cmp.Diff(expectedPages, pages); diff != "" {
+		t.Fatalf("unexpected pages: %s", diff)
+	}
+}
+
+func TestDecoder_MarkdownDocs_nilDependentBody(t *testing.T) {
+	d := NewDecoder()
+	d.SetSchema(&schema.BodySchema{
+		Blocks: map[string]*schema.BlockSchema{
+			"resource": {
+				Labels: []*schema.LabelSchema{
+					{Name: "type", IsDepKey: true},
+				},
+				DependentBody: map[schema.SchemaKey]*schema.BodySchema{
+					schema.NewSchemaKey(schema.DependencyKeys{
+						Labels: []schema.LabelDependent{
+							{Index: 0, Value: "aws_instance"},
+						},
+					}): nil,
+				},
+			},
+		},
+	})
+
+	docs, err := d.MarkdownDocs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := docs["resource"]; !ok {
+		t.Fatalf("expected docs for resource, given: %#v", docs)
+	}
+}
+
+func TestDecoder_MarkdownDocs_recursiveBlock(t *testing.T) {
+	nodeSchema := &schema.BlockSchema{
+		Body: &schema.BodySchema{
+			Attributes: map[string]*schema.AttributeSchema{
+				"name": {ValueType: cty.String, IsOptional: true},
+			},
+		},
+	}
+	nodeSchema.Body.Blocks = map[string]*schema.BlockSchema{
+		"node": nodeSchema,
+	}
+
+	d := NewDecoder()
+	d.SetSchema(&schema.BodySchema{
+		Blocks: map[string]*schema.BlockSchema{
+			"tree": {
+				Body: &schema.BodySchema{
+					Blocks: map[string]*schema.BlockSchema{
+						"node": nodeSchema,
+					},
+				},
+			},
+		},
+	})
+
+	pages, err := d.MarkdownDocs()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedPages := map[string]string{
+		"tree": "# `tree` Block\n\n" +
+			"_Block_\n\n" +
+			"## Blocks\n\n" +
+			"### `node` Block\n\n" +
+			"_Block_\n\n" +
+			"#### Attributes\n\n" +
+			"| Name | Type | Required | Deprecated | Description |\n" +
+			"|------|------|----------|------------|-------------|\n" +
+			"| `name` | string |  |  |  |\n\n" +
+			"#### Blocks\n\n" +
+			"##### `node` Block\n\n" +
+			"_Recursive, see the `node` block above_\n\n",
+	}
+
+	if diff := cmp.Diff(expectedPages, pages); diff != "" {
+		t.Fatalf("unexpected pages: %s", diff)
+	}
+}
@@ -0,0 +1,223 @@
+package decoder
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// objectConsCandidates returns candidates for keys of an object
+// or a map (with well-known keys) declared via object constructor
+func (d *Decoder) objectConsCandidates(expr *hclsyntax.ObjectConsExpr, consType cty.Type, mapKeys map[string]*schema.MapKeySchema, pos hcl.Pos, req candidatesRequest) (lang.Candidates, error) {
+	filename := expr.Range().Filename
+
+	rng := hcl.Range{
+		Filename: filename,
+		Start:    pos,
+		End:      pos,
+	}
+	prefixRng := rng
+
+	declaredKeys := make(map[string]bool, 0)
+	for _, item := range expr.Items {
+		key, ok := objectConsKeyName(item.KeyExpr)
+		if ok {
+			declaredKeys[key] = true
+		}
+
+		if item.ValueExpr.Range().ContainsPos(pos) {
+			nestedExpr, ok := item.ValueExpr.(*hclsyntax.ObjectConsExpr)
+			if ok && isPosInsideObjectCons(nestedExpr, pos) {
+				nestedType := consElementType(consType, key)
+				if isConsType(nestedType) {
+					return d.objectConsCandidates(nestedExpr, nestedType, nil, pos, req)
+				}
+			}
+
+			return lang.ZeroCandidates(), &PositionalError{
+				Filename: filename,
+				Pos:      pos,
+				Msg:      fmt.Sprintf("%s: no candidates for object attribute value", key),
+			}
+		}
+
+		keyRng := item.KeyExpr.Range()
+		if keyRng.ContainsPos(pos) || posEqual(keyRng.End, pos) {
+			rng, prefixRng = keyRng, keyRng
+			prefixRng.End = pos
+		}
+	}
+
+	if posEqual(rng.Start, pos) && posEqual(rng.End, pos) {
+		tokenRng, err := nameTokenRangeAtPos(req.tokens, pos)
+		if err != nil {
+			// e.g. key being typed within quotes
+			tokenRng, err = quotedKeyRangeAtPos(req.tokens, pos)
+		}
+		if err == nil {
+			rng, prefixRng = tokenRng, tokenRng
+			prefixRng.End = pos
+		}
+	}
+
+	prefix, _ := d.bytesFromRange(prefixRng)
+	// quoted keys are replaced as a whole (including quotes)
+	// and only the text within quotes is used as prefix
+	isQuoted := len(prefix) > 0 && prefix[0] == '"'
+	if isQuoted {
+		prefix = prefix[1:]
+	}
+
+	candidates := lang.NewCandidates()
+	count := 0
+
+	var names []string
+	if consType.IsObjectType() {
+		names = sortedObjectAttrNames(consType)
+	} else {
+		names = sortedMapKeyNames(mapKeys)
+	}
+
+	for _, name := range names {
+		if declaredKeys[name] || !req.isRequested(name) {
+			continue
+		}
+		if len(prefix) > 0 && !strings.HasPrefix(name, string(prefix)) {
+			continue
+		}
+		if req.isFull(count) {
+			return candidates, nil
+		}
+		count++
+		if count <= req.offset {
+			continue
+		}
+
+		var candidate lang.Candidate
+		if consType.IsObjectType() {
+			candidate = objectAttributeToCandidate(name, consType.AttributeType(name), rng, isQuoted)
+		} else {
+			candidate = mapKeyToCandidate(name, mapKeys[name], consType.ElementType(), rng, isQuoted)
+		}
+		if isQuoted {
+			// clients filter by text from the start of the edit range
+			candidate.FilterText = candidate.TextEdit.NewText
+		}
+		candidates.List = append(candidates.List, candidate)
+	}
+
+	candidates.IsComplete = true
+
+	return candidates, nil
+}
+
+func objectAttributeToCandidate(name string, attrType cty.Type, rng hcl.Range, quoted bool) lang.Candidate {
+	keyName := name
+	if quoted {
+		keyName = fmt.Sprintf("%q", name)
+	}
+
+	return lang.Candidate{
+		Label:  name,
+		Detail: attrType.FriendlyName(),
+		Kind:   lang.AttributeCandidateKind,
+		TextEdit: lang.TextEdit{
+			NewText: keyName,
+			Snippet: fmt.Sprintf("%s = %s", keyName, snippetForAttrValue(1, attrType)),
+			Range:   rng,
+		},
+	}
+}
+
+// mapKeyToCandidate returns candidate for the map key, which is quoted
+// if requested or if it is not a valid identifier
+func mapKeyToCandidate(key string, keySchema *schema.MapKeySchema, elemType cty.Type, rng hcl.Range, quoted bool) lang.Candidate {
+	detail := "Optional"
+	if keySchema.IsRequired {
+		detail = "Required"
+	}
+	detail += fmt.Sprintf(", %s", elemType.FriendlyName())
+
+	keyName := key
+	if quoted || !hclsyntax.ValidIdentifier(key) {
+		keyName = fmt.Sprintf("%q", key)
+	}
+
+	return lang.Candidate{
+		Label:        key,
+		Detail:       detail,
+		Description:  keySchema.Description,
+		IsDeprecated: keySchema.IsDeprecated,
+		Kind:         lang.AttributeCandidateKind,
+		TextEdit: lang.TextEdit{
+			NewText: keyName,
+			Snippet: fmt.Sprintf("%s = %s", keyName, snippetForAttrValue(1, elemType)),
+			Range:   rng,
+		},
+	}
+}
+
+func sortedMapKeyNames(keys map[string]*schema.MapKeySchema) []string {
+	names := make([]string, len(keys))
+	i := 0
+	for name := range keys {
+		names[i] = name
+		i++
+	}
+	sort.Strings(names)
+	return names
+}
+
+// consTypeForAttribute returns the object or map type which the attribute
+// value is expected to be of (if any), or cty.NilType otherwise.
+func consTypeForAttribute(attr *schema.AttributeSchema) cty.Type {
+	if len(attr.ValueTypes) > 0 {
+		for _, vt := range attr.ValueTypes {
+			if isConsType(vt) {
+				return vt
+			}
+		}
+		return cty.NilType
+	}
+
+	if isConsType(attr.ValueType) {
+		return attr.ValueType
+	}
+	return cty.NilType
+}
+
+func isConsType(t cty.Type) bool {
+	return t.IsObjectType() || t.IsMapType()
+}
+
+func consElementType(consType cty.Type, key string) cty.Type {
+	if consType.IsObjectType() {
+		if consType.HasAttribute(key) {
+			return consType.AttributeType(key)
+		}
+		return cty.NilType
+	}
+	if consType.IsMapType() {
+		return consType.ElementType()
+	}
+	return cty.NilType
+}
+
+func objectConsKeyName(expr hclsyntax.Expression) (string, bool) {
+	val, diags := expr.Value(nil)
+	if diags.HasErrors() || val.IsNull() || !val.IsKnown() || val.Type() != cty.String {
+		return "", false
+	}
+	return val.AsString(), true
+}
+
+func isPosInsideObjectCons(expr *hclsyntax.ObjectConsExpr, pos hcl.Pos) bool {
+	return expr.Range().ContainsPos(pos) &&
+		!expr.OpenRange.ContainsPos(pos)
+}
@@ -0,0 +1,181 @@
+package decoder
+
+import (
+	"sort"
+
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// targetNode represents a step of addresses of declared blocks,
+// e.g. "aws_instance" with blocks keyed by name as children
+type targetNode struct {
+	children map[string]*targetNode
+	// val represents the block (if the address ends here)
+	val cty.Value
+}
+
+// referenceTarget returns value representing blocks declared
+// in top-level bodies of all loaded files, whose schema
+// has an Address starting with the given name, e.g. object
+// of all aws_instance resources keyed by their names.
+//
+// Values of blocks are unknown and only their types are derived
+// from the block schema (including dependent body).
+//
+// rootSchemaMu is expected to be locked.
+func (d *Decoder) referenceTarget(rootName string) (cty.Value, bool) {
+	if d.rootSchema == nil {
+		return cty.NilVal, false
+	}
+
+	filenames := d.Filenames()
+	sort.Strings(filenames)
+
+	root := &targetNode{}
+	found := false
+	for _, filename := range filenames {
+		f, err := d.fileByName(filename)
+		if err != nil {
+			continue
+		}
+		body, ok := f.Body.(*hclsyntax.Body)
+		if !ok {
+			continue
+		}
+
+		for _, block := range body.Blocks {
+			blockSchema, ok := d.rootSchema.Blocks[block.Type]
+			if !ok || blockSchema.Address == nil {
+				continue
+			}
+			addr, ok := blockAddress(block, blockSchema.Address)
+			if !ok || addr[0] != rootName {
+				continue
+			}
+
+			bodySchema := d.mergeBlockBodySchemas(block, blockSchema)
+			val := cty.UnknownVal(bodySchemaType(bodySchema, []*schema.BlockSchema{blockSchema}))
+			if root.insert(addr[1:], val) {
+				found = true
+			}
+		}
+	}
+
+	if !found {
+		return cty.NilVal, false
+	}
+	return root.value(), true
+}
+
+// blockAddress returns steps of the address of the declared block,
+// e.g. ["aws_instance", "web"] for resource "aws_instance" "web"
+func blockAddress(block *hclsyntax.Block, addr *schema.BlockAddrSchema) ([]string, bool) {
+	if len(addr.Steps) == 0 {
+		return nil, false
+	}
+
+	steps := make([]string, len(addr.Steps))
+	for i, step := range addr.Steps {
+		switch s := step.(type) {
+		case schema.StaticStep:
+			steps[i] = s.Name
+		case schema.LabelStep:
+			if s.Index < 0 || s.Index >= len(block.Labels) {
+				return nil, false
+			}
+			steps[i] = block.Labels[s.Index]
+		}
+		if !hclsyntax.ValidIdentifier(steps[i]) {
+			// e.g. label not yet typed
+			return nil, false
+		}
+	}
+	return steps, true
+}
+
+// insert adds the value under the given steps and returns false
+// if it conflicts with a value already inserted (e.g. duplicate block)
+func (n *targetNode) insert(steps []string, val cty.Value) bool {
+	if len(steps) == 0 {
+		if n.val != cty.NilVal || len(n.children) > 0 {
+			return false
+		}
+		n.val = val
+		return true
+	}
+	if n.val != cty.NilVal {
+		return false
+	}
+
+	if n.children == nil {
+		n.children = make(map[string]*targetNode, 0)
+	}
+	child, ok := n.children[steps[0]]
+	if !ok {
+		child = &targetNode{}
+		n.children[steps[0]] = child
+	}
+	if !child.insert(steps[1:], val) {
+		if !ok {
+			delete(n.children, steps[0])
+		}
+		return false
+	}
+	return true
+}
+
+func (n *targetNode) value() cty.Value {
+	if n.val != cty.NilVal {
+		return n.val
+	}
+	attrs := make(map[string]cty.Value, len(n.children))
+	for name, child := range n.children {
+		attrs[name] = child.value()
+	}
+	return cty.ObjectVal(attrs)
+}
+
+// bodySchemaType returns object type of the body, with attributes
+// and nested blocks (represented according to their BlockType).
+//
+// parents holds schemas of the enclosing blocks, such that
+// recursive blocks are left out.
+func bodySchemaType(bs *schema.LayeredBodySchema, parents []*schema.BlockSchema) cty.Type {
+	attrTypes := make(map[string]cty.Type, 0)
+
+	for _, name := range bs.AttributeNames() {
+		attr, _ := bs.Attribute(name)
+		attrTypes[name] = attributeSchemaType(attr)
+	}
+
+	for _, bType := range bs.BlockTypes() {
+		block, _ := bs.Block(bType)
+		if block == nil || isBlockSchemaIn(block, parents) {
+			continue
+		}
+		objType := bodySchemaType(schema.NewLayeredBodySchema(block.Body), append(parents, block))
+
+		switch block.Type {
+		case schema.BlockTypeObject:
+			attrTypes[bType] = objType
+		case schema.BlockTypeSet:
+			attrTypes[bType] = cty.Set(objType)
+		case schema.BlockTypeMap:
+			attrTypes[bType] = cty.Map(objType)
+		default:
+			attrTypes[bType] = cty.List(objType)
+		}
+	}
+
+	return cty.Object(attrTypes)
+}
+
+func attributeSchemaType(attr *schema.AttributeSchema) cty.Type {
+	if attr.ValueType == cty.NilType {
+		// e.g. one of multiple ValueTypes
+		return cty.DynamicPseudoType
+	}
+	return attr.ValueType
+}
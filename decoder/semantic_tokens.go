@@ -22,11 +22,14 @@ func (d *Decoder) SemanticTokensInFile(filename string) ([]lang.SemanticToken, e
 		return nil, err
 	}
 
+	d.rootSchemaMu.RLock()
+	defer d.rootSchemaMu.RUnlock()
+
 	if d.rootSchema == nil {
 		return []lang.SemanticToken{}, nil
 	}
 
-	tokens := tokensForBody(body, d.rootSchema, false)
+	tokens := d.tokensForBody(body, d.rootSchema, false)
 
 	sort.Slice(tokens, func(i, j int) bool {
 		return tokens[i].Range.Start.Byte < tokens[j].Range.Start.Byte
@@ -35,7 +38,7 @@ func (d *Decoder) SemanticTokensInFile(filename string) ([]lang.SemanticToken, e
 	return tokens, nil
 }
 
-func tokensForBody(body *hclsyntax.Body, bodySchema *schema.BodySchema, isDependent bool) []lang.SemanticToken {
+func (d *Decoder) tokensForBody(body *hclsyntax.Body, bodySchema *schema.BodySchema, isDependent bool) []lang.SemanticToken {
 	tokens := make([]lang.SemanticToken, 0)
 
 	if bodySchema == nil {
@@ -109,13 +112,13 @@ func tokensForBody(body *hclsyntax.Body, bodySchema *schema.BodySchema, isDepend
 		}
 
 		if block.Body != nil {
-			tokens = append(tokens, tokensForBody(block.Body, blockSchema.Body, false)...)
+			tokens = append(tokens, d.tokensForBody(block.Body, blockSchema.Body, false)...)
 		}
 
 		dk := dependencyKeysFromBlock(block, blockSchema)
-		depSchema, ok := blockSchema.DependentBodySchema(dk)
+		depSchema, ok := d.dependentBodySchema(blockSchema, dk)
 		if ok {
-			tokens = append(tokens, tokensForBody(block.Body, depSchema, true)...)
+			tokens = append(tokens, d.tokensForBody(block.Body, depSchema, true)...)
 		}
 	}
 
@@ -0,0 +1,297 @@
+package decoder
+
+import (
+	"sort"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// partialTraversal represents a traversal recovered from tokens
+// preceding a dot, e.g. var.config in "var.config."
+type partialTraversal struct {
+	rootName string
+	steps    []traversalStep
+	// dotRng is range of the trailing dot
+	dotRng hcl.Range
+}
+
+// traversalStep represents an attribute access (.name),
+// an index (.0 or [0]) or a splat (.* or [*])
+type traversalStep struct {
+	name  string
+	key   cty.Value
+	splat bool
+}
+
+// partialTraversalFromTokens recovers traversal from tokens
+// ending with a dot, e.g. var.list[0].config.
+//
+// Only number and (non-template) string literals
+// are supported as index keys.
+func partialTraversalFromTokens(tokens hclsyntax.Tokens) (*partialTraversal, bool) {
+	last := len(tokens) - 1
+	if last < 1 || tokens[last].Type != hclsyntax.TokenDot {
+		return nil, false
+	}
+
+	pt := &partialTraversal{
+		dotRng: tokens[last].Range,
+	}
+	// steps are collected in reverse order
+	steps := make([]traversalStep, 0)
+
+	for p := last - 1; p >= 0; {
+		t := tokens[p]
+		afterDot := p > 0 && tokens[p-1].Type == hclsyntax.TokenDot
+
+		switch t.Type {
+		case hclsyntax.TokenIdent:
+			if afterDot {
+				steps = append(steps, traversalStep{name: string(t.Bytes)})
+				p -= 2
+				continue
+			}
+
+			pt.rootName = string(t.Bytes)
+			for i, j := 0, len(steps)-1; i < j; i, j = i+1, j-1 {
+				steps[i], steps[j] = steps[j], steps[i]
+			}
+			pt.steps = steps
+			return pt, true
+		case hclsyntax.TokenStar, hclsyntax.TokenNumberLit:
+			if !afterDot {
+				return nil, false
+			}
+			step, ok := traversalStepFromToken(t)
+			if !ok {
+				return nil, false
+			}
+			steps = append(steps, step)
+			p -= 2
+			continue
+		case hclsyntax.TokenCBrack:
+			if p >= 4 && tokens[p-1].Type == hclsyntax.TokenCQuote {
+				// string key, e.g. ["key"]
+				if tokens[p-4].Type != hclsyntax.TokenOBrack {
+					return nil, false
+				}
+				key, ok := stringKeyFromTokens(tokens[p-3 : p])
+				if !ok {
+					return nil, false
+				}
+				steps = append(steps, traversalStep{key: key})
+				p -= 5
+				continue
+			}
+			if p < 2 || tokens[p-2].Type != hclsyntax.TokenOBrack {
+				return nil, false
+			}
+			step, ok := traversalStepFromToken(tokens[p-1])
+			if !ok {
+				return nil, false
+			}
+			steps = append(steps, step)
+			p -= 3
+			continue
+		}
+
+		return nil, false
+	}
+
+	return nil, false
+}
+
+// traversalStepFromToken returns splat or index step
+// represented by the token (within brackets or after a dot)
+func traversalStepFromToken(t hclsyntax.Token) (traversalStep, bool) {
+	switch t.Type {
+	case hclsyntax.TokenStar:
+		return traversalStep{splat: true}, true
+	case hclsyntax.TokenNumberLit:
+		key, err := cty.ParseNumberVal(string(t.Bytes))
+		if err != nil {
+			return traversalStep{}, false
+		}
+		return traversalStep{key: key}, true
+	}
+	return traversalStep{}, false
+}
+
+// stringKeyFromTokens returns value of the quoted string
+// represented by the (opening quote, literal, closing quote) tokens
+func stringKeyFromTokens(tokens hclsyntax.Tokens) (cty.Value, bool) {
+	if len(tokens) != 3 || tokens[0].Type != hclsyntax.TokenOQuote ||
+		tokens[1].Type != hclsyntax.TokenQuotedLit || tokens[2].Type != hclsyntax.TokenCQuote {
+		return cty.NilVal, false
+	}
+
+	src := make([]byte, 0)
+	for _, t := range tokens {
+		src = append(src, t.Bytes...)
+	}
+	// the literal may contain escape sequences
+	expr, diags := hclsyntax.ParseExpression(src, "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return cty.NilVal, false
+	}
+	key, diags := expr.Value(nil)
+	if diags.HasErrors() || !key.Type().Equals(cty.String) {
+		return cty.NilVal, false
+	}
+	return key, true
+}
+
+// traverseStep returns value resulting from applying the step.
+//
+// Splat is represented by the (first) element, such that
+// any following steps apply to the elements.
+func traverseStep(val cty.Value, step traversalStep) (cty.Value, bool) {
+	if step.splat {
+		return splatElement(val), true
+	}
+
+	var diags hcl.Diagnostics
+	if step.key != cty.NilVal {
+		val, diags = hcl.Index(val, step.key, nil)
+	} else {
+		val, diags = hcl.GetAttr(val, step.name, nil)
+	}
+	return val, !diags.HasErrors()
+}
+
+func splatElement(val cty.Value) cty.Value {
+	ty := val.Type()
+	switch {
+	case ty.IsListType(), ty.IsSetType():
+		if val.IsKnown() && !val.IsNull() && val.LengthInt() > 0 {
+			it := val.ElementIterator()
+			it.Next()
+			_, elem := it.Element()
+			return elem
+		}
+		return cty.UnknownVal(ty.ElementType())
+	case ty.IsTupleType():
+		elemTypes := ty.TupleElementTypes()
+		if len(elemTypes) == 0 {
+			return cty.DynamicVal
+		}
+		if val.IsKnown() && !val.IsNull() {
+			return val.Index(cty.NumberIntVal(0))
+		}
+		return cty.UnknownVal(elemTypes[0])
+	}
+	// splat of a single value is the value itself
+	return val
+}
+
+// traversalStepCandidates returns candidates for the step following
+// the traversal, based on the type (and value) of the variable
+// of the eval context it refers to, or of blocks declared
+// in the configuration whose schema has an Address
+// (e.g. resource "aws_instance" "web" as aws_instance.web).
+//
+// Variables of the eval context take precedence over declared blocks.
+//
+// rootSchemaMu (guarding the eval context) is expected to be locked.
+func (d *Decoder) traversalStepCandidates(pt *partialTraversal, prefix string, editRng hcl.Range, req candidatesRequest) lang.Candidates {
+	variables, _ := evalContextScope(d.evalCtx)
+	val, ok := variables[pt.rootName]
+	if !ok {
+		val, ok = d.referenceTarget(pt.rootName)
+	}
+	if !ok {
+		return lang.ZeroCandidates()
+	}
+	for _, step := range pt.steps {
+		val, ok = traverseStep(val, step)
+		if !ok {
+			return lang.ZeroCandidates()
+		}
+	}
+
+	ranked := make([]rankedCandidate, 0)
+	addAttribute := func(name string, ty cty.Type) {
+		score, ok := fuzzyMatchScore(prefix, name)
+		if !ok {
+			return
+		}
+		ranked = append(ranked, rankedCandidate{
+			label: name,
+			score: score,
+			candidate: func() lang.Candidate {
+				return lang.Candidate{
+					Label:  name,
+					Detail: ty.FriendlyName(),
+					Kind:   lang.TraversalCandidateKind,
+					TextEdit: lang.TextEdit{
+						NewText: name,
+						Snippet: name,
+						Range:   editRng,
+					},
+				}
+			},
+		})
+	}
+
+	ty := val.Type()
+	switch {
+	case ty.IsObjectType():
+		for _, name := range sortedObjectAttrNames(ty) {
+			addAttribute(name, ty.AttributeType(name))
+		}
+	case ty.IsMapType():
+		for _, key := range knownMapKeys(val) {
+			addAttribute(key, ty.ElementType())
+		}
+	case ty.IsListType(), ty.IsSetType(), ty.IsTupleType():
+		if score, ok := fuzzyMatchScore(prefix, "[*]"); ok {
+			ranked = append(ranked, rankedCandidate{
+				label: "[*]",
+				score: score,
+				candidate: func() lang.Candidate {
+					return splatCandidate(ty, pt.dotRng, editRng)
+				},
+			})
+		}
+	}
+
+	return rankCandidates(ranked, req)
+}
+
+// splatCandidate returns candidate replacing the dot
+// (and any identifier after it) with a splat
+func splatCandidate(ty cty.Type, dotRng, editRng hcl.Range) lang.Candidate {
+	rng := hcl.RangeBetween(dotRng, editRng)
+	return lang.Candidate{
+		Label:  "[*]",
+		Detail: ty.FriendlyName(),
+		Kind:   lang.TraversalCandidateKind,
+		TextEdit: lang.TextEdit{
+			NewText: "[*]",
+			Snippet: "[*]",
+			Range:   rng,
+		},
+		// clients filter by text from the start of the edit range
+		FilterText: ".[*]",
+	}
+}
+
+// knownMapKeys returns sorted keys of the map which are
+// valid identifiers, i.e. can be accessed via a dot
+func knownMapKeys(val cty.Value) []string {
+	keys := make([]string, 0)
+	if !val.IsKnown() || val.IsNull() {
+		return keys
+	}
+	for it := val.ElementIterator(); it.Next(); {
+		k, _ := it.Element()
+		if hclsyntax.ValidIdentifier(k.AsString()) {
+			keys = append(keys, k.AsString())
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,467 @@
+package decoder
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestPartialTraversalFromTokens(t *testing.T) {
+	testCases := []struct {
+		src           string
+		expectedOk    bool
+		expectedRoot  string
+		expectedSteps []string
+	}{
+		{"var.", true, "var", []string{}},
+		{"var.config.", true, "var", []string{"config"}},
+		{"x = var.list[0].", true, "var", []string{"list", "[0]"}},
+		{"var.list[*].name.", true, "var", []string{"list", "[*]", "name"}},
+		{"var.list.*.", true, "var", []string{"list", "[*]"}},
+		{"var.list.0.", true, "var", []string{"list", "[0]"}},
+		{"upper(var.", true, "var", []string{}},
+		{"upper().", false, "", nil},
+		{`var.map["key"].`, true, "var", []string{"map", `["key"]`}},
+		{`var.map["k\"ey"][0].`, true, "var", []string{"map", `["k\"ey"]`, "[0]"}},
+		{`var.map["${var.key}"].`, false, "", nil},
+		{"var", false, "", nil},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.src), func(t *testing.T) {
+			tokens, _ := hclsyntax.LexExpression([]byte(tc.src), "test.tf", hcl.InitialPos)
+			// strip EOF
+			tokens = tokens[:len(tokens)-1]
+
+			pt, ok := partialTraversalFromTokens(tokens)
+			if ok != tc.expectedOk {
+				t.Fatalf("expected ok: %t, given: %t", tc.expectedOk, ok)
+			}
+			if !ok {
+				return
+			}
+
+			steps := make([]string, 0)
+			for _, step := range pt.steps {
+				switch {
+				case step.splat:
+					steps = append(steps, "[*]")
+				case step.key != cty.NilVal && step.key.Type() == cty.String:
+					steps = append(steps, fmt.Sprintf("[%q]", step.key.AsString()))
+				case step.key != cty.NilVal:
+					steps = append(steps, fmt.Sprintf("[%s]", step.key.AsBigFloat().String()))
+				default:
+					steps = append(steps, step.name)
+				}
+			}
+			if pt.rootName != tc.expectedRoot {
+				t.Fatalf("expected root %q, given: %q", tc.expectedRoot, pt.rootName)
+			}
+			if diff := cmp.Diff(tc.expectedSteps, steps); diff != "" {
+				t.Fatalf("unexpected steps: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecoder_CandidatesAtPos_traversalSteps(t *testing.T) {
+	bodySchema := &schema.BodySchema{
+		Attributes: map[string]*schema.AttributeSchema{
+			"name": {ValueType: cty.String, IsOptional: true},
+		},
+		Blocks: map[string]*schema.BlockSchema{
+			"resource": {
+				Body: &schema.BodySchema{
+					Attributes: map[string]*schema.AttributeSchema{
+						"ami": {ValueType: cty.String, IsOptional: true},
+					},
+				},
+			},
+		},
+	}
+	evalCtx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"var": cty.ObjectVal(map[string]cty.Value{
+				"region": cty.StringVal("eu-west-1"),
+				"config": cty.ObjectVal(map[string]cty.Value{
+					"enabled": cty.True,
+					"size":    cty.NumberIntVal(1),
+				}),
+				"subnets": cty.ListVal([]cty.Value{
+					cty.ObjectVal(map[string]cty.Value{
+						"id": cty.StringVal("subnet-1"),
+					}),
+				}),
+				"tags": cty.MapVal(map[string]cty.Value{
+					"Name":        cty.StringVal("example"),
+					"invalid key": cty.StringVal("example"),
+				}),
+			}),
+			"aws_instance": cty.ObjectVal(map[string]cty.Value{
+				"web": cty.UnknownVal(cty.Object(map[string]cty.Type{
+					"ami": cty.String,
+				})),
+			}),
+		},
+	}
+
+	testCases := []struct {
+		name           string
+		cfg            string
+		pos            hcl.Pos
+		expectedLabels []string
+	}{
+		{
+			"root attributes",
+			`name = var.
+`,
+			hcl.Pos{Line: 1, Column: 12, Byte: 11},
+			[]string{"config", "region", "subnets", "tags"},
+		},
+		{
+			"nested object attributes",
+			`name = var.config.
+`,
+			hcl.Pos{Line: 1, Column: 19, Byte: 18},
+			[]string{"enabled", "size"},
+		},
+		{
+			"partial attribute name",
+			`name = var.con
+`,
+			hcl.Pos{Line: 1, Column: 15, Byte: 14},
+			[]string{"config"},
+		},
+		{
+			"attribute in nested block",
+			`resource {
+  ami = aws_instance.
+}
+`,
+			hcl.Pos{Line: 2, Column: 22, Byte: 32},
+			[]string{"web"},
+		},
+		{
+			"unknown object",
+			`name = aws_instance.web.
+`,
+			hcl.Pos{Line: 1, Column: 25, Byte: 24},
+			[]string{"ami"},
+		},
+		{
+			"template interpolation",
+			`name = "prefix-${var.config.}"
+`,
+			hcl.Pos{Line: 1, Column: 29, Byte: 28},
+			[]string{"enabled", "size"},
+		},
+		{
+			"list splat",
+			`name = var.subnets.
+`,
+			hcl.Pos{Line: 1, Column: 20, Byte: 19},
+			[]string{"[*]"},
+		},
+		{
+			"list splat element",
+			`name = var.subnets[*].
+`,
+			hcl.Pos{Line: 1, Column: 23, Byte: 22},
+			[]string{"id"},
+		},
+		{
+			"list index element",
+			`name = var.subnets[0].
+`,
+			hcl.Pos{Line: 1, Column: 23, Byte: 22},
+			[]string{"id"},
+		},
+		{
+			"string index",
+			`name = var["config"].
+`,
+			hcl.Pos{Line: 1, Column: 22, Byte: 21},
+			[]string{"enabled", "size"},
+		},
+		{
+			"string index in template",
+			`name = "${var.subnets[0]["id"]}-${var["config"].}"
+`,
+			hcl.Pos{Line: 1, Column: 49, Byte: 48},
+			[]string{"enabled", "size"},
+		},
+		{
+			"map keys",
+			`name = var.tags.
+`,
+			hcl.Pos{Line: 1, Column: 17, Byte: 16},
+			[]string{"Name"},
+		},
+		{
+			"unknown variable",
+			`name = local.
+`,
+			hcl.Pos{Line: 1, Column: 14, Byte: 13},
+			[]string{},
+		},
+		{
+			"unknown attribute",
+			`name = var.unknown.
+`,
+			hcl.Pos{Line: 1, Column: 20, Byte: 19},
+			[]string{},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(bodySchema)
+			d.SetEvalContext(evalCtx)
+
+			f, _ := hclsyntax.ParseConfig([]byte(tc.cfg), "test.tf", hcl.InitialPos)
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			candidates, err := d.CandidatesAtPos("test.tf", tc.pos)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			labels := make([]string, 0)
+			for _, c := range candidates.List {
+				labels = append(labels, c.Label)
+			}
+			if diff := cmp.Diff(tc.expectedLabels, labels); diff != "" {
+				t.Fatalf("unexpected candidates: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDecoder_CandidatesAtPos_traversalSplat(t *testing.T) {
+	d := NewDecoder()
+	d.SetSchema(&schema.BodySchema{})
+	d.SetEvalContext(&hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"names": cty.ListVal([]cty.Value{cty.StringVal("a")}),
+		},
+	})
+
+	f, _ := hclsyntax.ParseConfig([]byte(`name = names.
+`), "test.tf", hcl.InitialPos)
+	err := d.LoadFile("test.tf", f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	candidates, err := d.CandidatesAtPos("test.tf", hcl.Pos{Line: 1, Column: 14, Byte: 13})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedCandidates := lang.CompleteCandidates([]lang.Candidate{
+		{
+			Label:  "[*]",
+			Detail: "list of string",
+			Kind:   lang.TraversalCandidateKind,
+			TextEdit: lang.TextEdit{
+				Range: hcl.Range{
+					Filename: "test.tf",
+					Start:    hcl.Pos{Line: 1, Column: 13, Byte: 12},
+					End:      hcl.Pos{Line: 1, Column: 14, Byte: 13},
+				},
+				NewText: "[*]",
+				Snippet: "[*]",
+			},
+			SortText:   "0",
+			FilterText: ".[*]",
+		},
+	})
+	if diff := cmp.Diff(expectedCandidates, candidates); diff != "" {
+		t.Fatalf("unexpected candidates: %s", diff)
+	}
+}
+
+func TestDecoder_CandidatesAtPos_traversalDeclaredBlocks(t *testing.T) {
+	typeKey := func(value string) schema.SchemaKey {
+		return schema.NewSchemaKey(schema.DependencyKeys{
+			Labels: []schema.LabelDependent{
+				{Index: 0, Value: value},
+			},
+		})
+	}
+	bodySchema := &schema.BodySchema{
+		Attributes: map[string]*schema.AttributeSchema{
+			"name": {ValueType: cty.String, IsOptional: true},
+		},
+		Blocks: map[string]*schema.BlockSchema{
+			"resource": {
+				Labels: []*schema.LabelSchema{
+					{Name: "type", IsDepKey: true},
+					{Name: "name"},
+				},
+				Address: &schema.BlockAddrSchema{
+					Steps: []schema.AddrStep{
+						schema.LabelStep{Index: 0},
+						schema.LabelStep{Index: 1},
+					},
+				},
+				Body: &schema.BodySchema{
+					Attributes: map[string]*schema.AttributeSchema{
+						"count": {ValueType: cty.Number, IsOptional: true},
+					},
+				},
+				DependentBody: map[schema.SchemaKey]*schema.BodySchema{
+					typeKey("aws_instance"): {
+						Attributes: map[string]*schema.AttributeSchema{
+							"ami": {ValueType: cty.String, IsRequired: true},
+							"id":  {ValueType: cty.String, IsComputed: true},
+						},
+						Blocks: map[string]*schema.BlockSchema{
+							"ebs": {
+								Type: schema.BlockTypeList,
+								Body: &schema.BodySchema{
+									Attributes: map[string]*schema.AttributeSchema{
+										"size": {ValueType: cty.Number, IsOptional: true},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"data": {
+				Labels: []*schema.LabelSchema{
+					{Name: "type", IsDepKey: true},
+					{Name: "name"},
+				},
+				Address: &schema.BlockAddrSchema{
+					Steps: []schema.AddrStep{
+						schema.StaticStep{Name: "data"},
+						schema.LabelStep{Index: 0},
+						schema.LabelStep{Index: 1},
+					},
+				},
+				DependentBody: map[schema.SchemaKey]*schema.BodySchema{
+					typeKey("aws_ami"): {
+						Attributes: map[string]*schema.AttributeSchema{
+							"owners": {ValueType: cty.List(cty.String), IsOptional: true},
+						},
+					},
+				},
+			},
+			"provider": {
+				Labels: []*schema.LabelSchema{
+					{Name: "name"},
+				},
+				Body: &schema.BodySchema{},
+			},
+		},
+	}
+	declarations := `resource "aws_instance" "web" {
+  ami = "ami-123"
+}
+resource "aws_instance" "db" {
+}
+resource "aws_instance" "" {
+}
+data "aws_ami" "ubuntu" {
+}
+provider "aws" {
+}
+`
+
+	testCases := []struct {
+		name           string
+		cfg            string
+		pos            hcl.Pos
+		expectedLabels []string
+	}{
+		{
+			"block names",
+			`name = aws_instance.`,
+			hcl.Pos{Line: 12, Column: 21, Byte: 183},
+			[]string{"db", "web"},
+		},
+		{
+			"block attributes",
+			`name = aws_instance.web.`,
+			hcl.Pos{Line: 12, Column: 25, Byte: 187},
+			[]string{"ami", "count", "ebs", "id"},
+		},
+		{
+			"nested block attributes",
+			`name = aws_instance.web.ebs[0].`,
+			hcl.Pos{Line: 12, Column: 32, Byte: 194},
+			[]string{"size"},
+		},
+		{
+			"static address step",
+			`name = data.`,
+			hcl.Pos{Line: 12, Column: 13, Byte: 175},
+			[]string{"aws_ami"},
+		},
+		{
+			"static address step attributes",
+			`name = data.aws_ami.ubuntu.`,
+			hcl.Pos{Line: 12, Column: 28, Byte: 190},
+			[]string{"owners"},
+		},
+		{
+			"block without address",
+			`name = aws.`,
+			hcl.Pos{Line: 12, Column: 12, Byte: 174},
+			[]string{},
+		},
+		{
+			"eval context precedence",
+			`name = data.`,
+			hcl.Pos{Line: 12, Column: 13, Byte: 175},
+			[]string{"region"},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			d := NewDecoder()
+			d.SetSchema(bodySchema)
+			if tc.name == "eval context precedence" {
+				d.SetEvalContext(&hcl.EvalContext{
+					Variables: map[string]cty.Value{
+						"data": cty.ObjectVal(map[string]cty.Value{
+							"region": cty.StringVal("eu-west-1"),
+						}),
+					},
+				})
+			}
+
+			f, _ := hclsyntax.ParseConfig([]byte(declarations+tc.cfg), "test.tf", hcl.InitialPos)
+			err := d.LoadFile("test.tf", f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			candidates, err := d.CandidatesAtPos("test.tf", tc.pos)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			labels := make([]string, 0)
+			for _, c := range candidates.List {
+				labels = append(labels, c.Label)
+			}
+			if diff := cmp.Diff(tc.expectedLabels, labels); diff != "" {
+				t.Fatalf("unexpected candidates: %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package decoder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+func (d *Decoder) allowedValueCandidates(attr *schema.AttributeSchema, prefixRng, editRng hcl.Range, req candidatesRequest) lang.Candidates {
+	candidates := lang.NewCandidates()
+	count := 0
+
+	prefix, _ := d.bytesFromRange(prefixRng)
+
+	for _, av := range attr.AllowedValues {
+		if len(prefix) > 0 && !strings.HasPrefix(av.Value, string(prefix)) {
+			continue
+		}
+		if !req.isRequested(av.Value) {
+			continue
+		}
+		if req.isFull(count) {
+			return candidates
+		}
+		count++
+		if count <= req.offset {
+			continue
+		}
+
+		candidates.List = append(candidates.List, lang.Candidate{
+			Label:        av.Value,
+			Detail:       "string",
+			Description:  av.Description,
+			IsDeprecated: av.IsDeprecated,
+			Kind:         lang.ValueCandidateKind,
+			TextEdit: lang.TextEdit{
+				NewText: av.Value,
+				Snippet: av.Value,
+				Range:   editRng,
+			},
+		})
+	}
+
+	candidates.IsComplete = true
+
+	return candidates
+}
+
+// snippetForAllowedValues returns a choice snippet
+// representing all allowed values
+func snippetForAllowedValues(placeholder uint, values []schema.AllowedValue) string {
+	choices := make([]string, len(values))
+	for i, av := range values {
+		choices[i] = escapeSnippetChoice(av.Value)
+	}
+	return fmt.Sprintf(`"${%d|%s|}"`, placeholder, strings.Join(choices, ","))
+}
+
+func escapeSnippetChoice(value string) string {
+	r := strings.NewReplacer(
+		`\`, `\\`,
+		`,`, `\,`,
+		`|`, `\|`,
+		`$`, `\$`,
+	)
+	return r.Replace(value)
+}
+
+// isPosInsideStringLiteral returns true if the position
+// is between quotes of a string literal
+func isPosInsideStringLiteral(expr hclsyntax.Expression, pos hcl.Pos) bool {
+	te, ok := expr.(*hclsyntax.TemplateExpr)
+	if !ok || !te.IsStringLiteral() {
+		return false
+	}
+
+	return te.Range().ContainsPos(pos) && pos.Byte > te.Range().Start.Byte
+}
+
+// stringLiteralValue returns value of the expression
+// if it represents a static string literal
+func stringLiteralValue(expr hclsyntax.Expression) (string, bool) {
+	te, ok := expr.(*hclsyntax.TemplateExpr)
+	if !ok || !te.IsStringLiteral() {
+		return "", false
+	}
+
+	val, diags := te.Value(nil)
+	if diags.HasErrors() || val.IsNull() || !val.IsKnown() {
+		return "", false
+	}
+	return val.AsString(), true
+}
@@ -6,7 +6,7 @@ require (
 	github.com/google/go-cmp v0.3.1
 	github.com/hashicorp/go-multierror v1.1.0
 	github.com/hashicorp/hcl/v2 v2.6.0
-	github.com/mitchellh/copystructure v1.0.0
+	github.com/hashicorp/terraform-json v0.8.0
 	github.com/zclconf/go-cty v1.6.1
 	github.com/zclconf/go-cty-debug v0.0.0-20191215020915-b22d67c1ba0b
 	golang.org/x/tools v0.0.0-20180917221912-90fa682c2a6e
@@ -0,0 +1,227 @@
+// Package gohclschema derives schema understood by the decoder
+// from Go structs annotated with gohcl's struct tags.
+package gohclschema
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/gocty"
+)
+
+// DescriptionTag is the name of the struct tag which can accompany
+// the hcl tag to provide description of an attribute, block or a label,
+// e.g.
+//
+//	Name string `hcl:"name" description:"Name of the app"`
+const DescriptionTag = "description"
+
+var (
+	exprType  = reflect.TypeOf((*hcl.Expression)(nil)).Elem()
+	valueType = reflect.TypeOf(cty.Value{})
+)
+
+// BodySchema produces BodySchema derived from the type of the given value,
+// which must be a struct value or a pointer to one.
+//
+// Requiredness follows the decoding logic of gohcl, i.e. non-pointer
+// attributes and blocks are required unless tagged as optional.
+// Field tagged as "remain" is represented as AnyAttribute
+// if there are no other attributes in the body.
+func BodySchema(val interface{}) (*schema.BodySchema, error) {
+	ty := reflect.TypeOf(val)
+	if ty == nil {
+		return nil, fmt.Errorf("given value must be struct, not nil")
+	}
+	if ty.Kind() == reflect.Ptr {
+		ty = ty.Elem()
+	}
+	if ty.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("given value must be struct, not %T", val)
+	}
+
+	return bodySchemaForType(ty, []reflect.Type{})
+}
+
+type fieldTag struct {
+	Name string
+	Kind string
+}
+
+func parseFieldTag(field reflect.StructField) (fieldTag, bool) {
+	tag, ok := field.Tag.Lookup("hcl")
+	if !ok || tag == "" {
+		return fieldTag{}, false
+	}
+
+	comma := strings.Index(tag, ",")
+	if comma == -1 {
+		return fieldTag{Name: tag, Kind: "attr"}, true
+	}
+	return fieldTag{Name: tag[:comma], Kind: tag[comma+1:]}, true
+}
+
+func bodySchemaForType(ty reflect.Type, parents []reflect.Type) (*schema.BodySchema, error) {
+	for _, parent := range parents {
+		if parent == ty {
+			return nil, fmt.Errorf("recursive type %s is not supported", ty.String())
+		}
+	}
+	parents = append(parents, ty)
+
+	bs := schema.NewBodySchema()
+	var remainType reflect.Type
+
+	for i := 0; i < ty.NumField(); i++ {
+		field := ty.Field(i)
+		tag, ok := parseFieldTag(field)
+		if !ok {
+			continue
+		}
+
+		switch tag.Kind {
+		case "attr", "optional":
+			attr, err := attributeSchemaForField(field, tag.Kind == "optional")
+			if err != nil {
+				return nil, err
+			}
+			bs.Attributes[tag.Name] = attr
+		case "block":
+			block, err := blockSchemaForField(field, parents)
+			if err != nil {
+				return nil, err
+			}
+			bs.Blocks[tag.Name] = block
+		case "remain":
+			if remainType != nil {
+				return nil, fmt.Errorf("%s: only one 'remain' tag is permitted", ty.String())
+			}
+			remainType = field.Type
+		case "label", "body":
+			// labels are read by the parent block
+			// and body is not represented in schema
+		default:
+			return nil, fmt.Errorf("invalid hcl field tag kind %q on %s %q",
+				tag.Kind, field.Type.String(), field.Name)
+		}
+	}
+
+	if remainType != nil && len(bs.Attributes) == 0 {
+		bs.AnyAttribute = &schema.AttributeSchema{
+			ValueType:  remainValueType(remainType),
+			IsOptional: true,
+		}
+	}
+
+	return bs, nil
+}
+
+func attributeSchemaForField(field reflect.StructField, optional bool) (*schema.AttributeSchema, error) {
+	attr := &schema.AttributeSchema{
+		Description: description(field),
+	}
+
+	switch {
+	case field.Type.AssignableTo(exprType):
+		// absence of an expression is indicated via null value
+		// so such attributes are never required
+		attr.IsOptional = true
+	case field.Type.Kind() != reflect.Ptr && !optional:
+		attr.IsRequired = true
+	default:
+		attr.IsOptional = true
+	}
+
+	vt, err := impliedType(field.Type)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", field.Name, err)
+	}
+	attr.ValueType = vt
+
+	return attr, nil
+}
+
+func blockSchemaForField(field reflect.StructField, parents []reflect.Type) (*schema.BlockSchema, error) {
+	block := &schema.BlockSchema{
+		Description: description(field),
+	}
+
+	fty := field.Type
+	switch fty.Kind() {
+	case reflect.Slice:
+		block.Type = schema.BlockTypeList
+		fty = fty.Elem()
+		if fty.Kind() == reflect.Ptr {
+			fty = fty.Elem()
+		}
+	case reflect.Ptr:
+		block.Type = schema.BlockTypeObject
+		block.MaxItems = 1
+		fty = fty.Elem()
+	default:
+		block.Type = schema.BlockTypeObject
+		block.MinItems = 1
+		block.MaxItems = 1
+	}
+
+	if fty.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("hcl 'block' tag kind cannot be applied to %s field %s: struct required",
+			field.Type.String(), field.Name)
+	}
+
+	for i := 0; i < fty.NumField(); i++ {
+		labelField := fty.Field(i)
+		tag, ok := parseFieldTag(labelField)
+		if !ok || tag.Kind != "label" {
+			continue
+		}
+		block.Labels = append(block.Labels, &schema.LabelSchema{
+			Name:        tag.Name,
+			Description: description(labelField),
+		})
+	}
+
+	body, err := bodySchemaForType(fty, parents)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", field.Name, err)
+	}
+	block.Body = body
+
+	return block, nil
+}
+
+func impliedType(ty reflect.Type) (cty.Type, error) {
+	if ty.AssignableTo(exprType) || ty == valueType {
+		return cty.DynamicPseudoType, nil
+	}
+	if ty.Kind() == reflect.Interface {
+		// gohcl cannot decode into interface types
+		// (and zero value of an interface carries no type)
+		return cty.NilType, fmt.Errorf("no cty.Type for %s", ty)
+	}
+
+	return gocty.ImpliedType(reflect.Zero(ty).Interface())
+}
+
+func remainValueType(ty reflect.Type) cty.Type {
+	if ty.Kind() == reflect.Map && ty.Key().Kind() == reflect.String {
+		vt, err := impliedType(ty.Elem())
+		if err == nil {
+			return vt
+		}
+	}
+	return cty.DynamicPseudoType
+}
+
+func description(field reflect.StructField) lang.MarkupContent {
+	desc := field.Tag.Get(DescriptionTag)
+	if desc == "" {
+		return lang.MarkupContent{}
+	}
+	return lang.PlainText(desc)
+}
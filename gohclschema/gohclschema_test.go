@@ -0,0 +1,231 @@
+package gohclschema
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty-debug/ctydebug"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var cmpOpts = cmp.Options{ctydebug.CmpOptions, cmpopts.EquateEmpty()}
+
+type testConfig struct {
+	Project string         `hcl:"project" description:"Name of the project"`
+	Labels  *[]string      `hcl:"labels"`
+	Runner  map[string]int `hcl:"runner,optional"`
+	Expr    hcl.Expression `hcl:"expr"`
+	Ignored string
+
+	Apps   []*testApp    `hcl:"app,block" description:"Application"`
+	Build  testBuild     `hcl:"build,block"`
+	Deploy *testDeploy   `hcl:"deploy,block"`
+	Env    *testEnv      `hcl:"env,block"`
+	Remain hcl.Body      `hcl:",remain"`
+	Body   hcl.Body      `hcl:",body"`
+	Hooks  []testHookRef `hcl:"hook,block"`
+}
+
+type testApp struct {
+	Name string `hcl:"name,label" description:"Name of the app"`
+	Path string `hcl:"path,optional"`
+}
+
+type testBuild struct {
+	Kind  string `hcl:"kind,label"`
+	Image string `hcl:"image"`
+}
+
+type testDeploy struct {
+	Count *int `hcl:"count"`
+}
+
+type testEnv struct {
+	Vars map[string]string `hcl:",remain"`
+}
+
+type testHookRef struct {
+	When  string    `hcl:"when,label"`
+	Stage string    `hcl:"stage,label"`
+	Value cty.Value `hcl:"value"`
+}
+
+func TestBodySchema(t *testing.T) {
+	expectedSchema := &schema.BodySchema{
+		Attributes: map[string]*schema.AttributeSchema{
+			"project": {
+				ValueType:   cty.String,
+				IsRequired:  true,
+				Description: lang.PlainText("Name of the project"),
+			},
+			"labels": {
+				ValueType:  cty.List(cty.String),
+				IsOptional: true,
+			},
+			"runner": {
+				ValueType:  cty.Map(cty.Number),
+				IsOptional: true,
+			},
+			"expr": {
+				ValueType:  cty.DynamicPseudoType,
+				IsOptional: true,
+			},
+		},
+		Blocks: map[string]*schema.BlockSchema{
+			"app": {
+				Type:        schema.BlockTypeList,
+				Description: lang.PlainText("Application"),
+				Labels: []*schema.LabelSchema{
+					{
+						Name:        "name",
+						Description: lang.PlainText("Name of the app"),
+					},
+				},
+				Body: &schema.BodySchema{
+					Attributes: map[string]*schema.AttributeSchema{
+						"path": {ValueType: cty.String, IsOptional: true},
+					},
+				},
+			},
+			"build": {
+				Type:     schema.BlockTypeObject,
+				MinItems: 1,
+				MaxItems: 1,
+				Labels: []*schema.LabelSchema{
+					{Name: "kind"},
+				},
+				Body: &schema.BodySchema{
+					Attributes: map[string]*schema.AttributeSchema{
+						"image": {ValueType: cty.String, IsRequired: true},
+					},
+				},
+			},
+			"deploy": {
+				Type:     schema.BlockTypeObject,
+				MaxItems: 1,
+				Body: &schema.BodySchema{
+					Attributes: map[string]*schema.AttributeSchema{
+						"count": {ValueType: cty.Number, IsOptional: true},
+					},
+				},
+			},
+			"env": {
+				Type:     schema.BlockTypeObject,
+				MaxItems: 1,
+				Body: &schema.BodySchema{
+					AnyAttribute: &schema.AttributeSchema{
+						ValueType:  cty.String,
+						IsOptional: true,
+					},
+				},
+			},
+			"hook": {
+				Type: schema.BlockTypeList,
+				Labels: []*schema.LabelSchema{
+					{Name: "when"},
+					{Name: "stage"},
+				},
+				Body: &schema.BodySchema{
+					Attributes: map[string]*schema.AttributeSchema{
+						"value": {ValueType: cty.DynamicPseudoType, IsRequired: true},
+					},
+				},
+			},
+		},
+	}
+
+	bs, err := BodySchema(&testConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(expectedSchema, bs, cmpOpts); diff != "" {
+		t.Fatalf("unexpected schema: %s", diff)
+	}
+
+	err = bs.Validate()
+	if err != nil {
+		t.Fatalf("expected valid schema: %s", err)
+	}
+}
+
+type testRecursive struct {
+	Child *testRecursive `hcl:"child,block"`
+}
+
+type testInvalidKind struct {
+	Name string `hcl:"name,unknown"`
+}
+
+type testInvalidBlock struct {
+	Name string `hcl:"name,block"`
+}
+
+type testUnsupportedType struct {
+	Ch chan int `hcl:"ch"`
+}
+
+type testInterfaceType struct {
+	Foo interface{} `hcl:"foo"`
+}
+
+func TestBodySchema_invalid(t *testing.T) {
+	testCases := []struct {
+		name          string
+		val           interface{}
+		expectedError string
+	}{
+		{
+			"nil",
+			nil,
+			"given value must be struct, not nil",
+		},
+		{
+			"non-struct",
+			"foo",
+			"given value must be struct, not string",
+		},
+		{
+			"recursive",
+			testRecursive{},
+			"Child: recursive type gohclschema.testRecursive is not supported",
+		},
+		{
+			"invalid tag kind",
+			testInvalidKind{},
+			`invalid hcl field tag kind "unknown" on string "Name"`,
+		},
+		{
+			"non-struct block",
+			testInvalidBlock{},
+			"hcl 'block' tag kind cannot be applied to string field Name: struct required",
+		},
+		{
+			"unsupported type",
+			testUnsupportedType{},
+			"Ch: no cty.Type for chan int",
+		},
+		{
+			"interface type",
+			testInterfaceType{},
+			"Foo: no cty.Type for interface {}",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			_, err := BodySchema(tc.val)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if diff := cmp.Diff(tc.expectedError, err.Error()); diff != "" {
+				t.Fatalf("unexpected error: %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+// Package hcldecschema converts hcldec.Spec trees
+// (as used by e.g. Packer) into schema understood by the decoder.
+package hcldecschema
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// BodySchema converts the given spec (typically hcldec.ObjectSpec)
+// describing a body into BodySchema.
+//
+// Specs which do not correspond to any configuration
+// (such as LiteralSpec) are ignored, wrapping specs
+// (such as DefaultSpec or ValidateSpec) are represented
+// by the spec they wrap and BlockLabelSpecs nested
+// in block specs are represented as labels of the block.
+func BodySchema(spec hcldec.Spec) (*schema.BodySchema, error) {
+	bs := schema.NewBodySchema()
+	err := addSpec(bs, spec)
+	if err != nil {
+		return nil, err
+	}
+	return bs, nil
+}
+
+func addSpec(bs *schema.BodySchema, spec hcldec.Spec) error {
+	switch s := spec.(type) {
+	case nil:
+		return nil
+	case hcldec.ObjectSpec:
+		for _, child := range s {
+			err := addSpec(bs, child)
+			if err != nil {
+				return err
+			}
+		}
+	case hcldec.TupleSpec:
+		for _, child := range s {
+			err := addSpec(bs, child)
+			if err != nil {
+				return err
+			}
+		}
+	case *hcldec.AttrSpec:
+		valueType := s.Type
+		if valueType == cty.NilType {
+			valueType = cty.DynamicPseudoType
+		}
+		return addAttribute(bs, s.Name, &schema.AttributeSchema{
+			ValueType:  valueType,
+			IsRequired: s.Required,
+			IsOptional: !s.Required,
+		})
+	case *hcldec.BlockSpec:
+		block := &schema.BlockSchema{
+			Type:     schema.BlockTypeObject,
+			MaxItems: 1,
+		}
+		if s.Required {
+			block.MinItems = 1
+		}
+		return addBlock(bs, s.TypeName, block, s.Nested)
+	case *hcldec.BlockListSpec:
+		return addBlock(bs, s.TypeName, &schema.BlockSchema{
+			Type:     schema.BlockTypeList,
+			MinItems: itemCount(s.MinItems),
+			MaxItems: itemCount(s.MaxItems),
+		}, s.Nested)
+	case *hcldec.BlockTupleSpec:
+		return addBlock(bs, s.TypeName, &schema.BlockSchema{
+			Type:     schema.BlockTypeTuple,
+			MinItems: itemCount(s.MinItems),
+			MaxItems: itemCount(s.MaxItems),
+		}, s.Nested)
+	case *hcldec.BlockSetSpec:
+		return addBlock(bs, s.TypeName, &schema.BlockSchema{
+			Type:     schema.BlockTypeSet,
+			MinItems: itemCount(s.MinItems),
+			MaxItems: itemCount(s.MaxItems),
+		}, s.Nested)
+	case *hcldec.BlockMapSpec:
+		return addBlock(bs, s.TypeName, &schema.BlockSchema{
+			Type:   schema.BlockTypeMap,
+			Labels: labelSchemas(s.LabelNames),
+		}, s.Nested)
+	case *hcldec.BlockObjectSpec:
+		return addBlock(bs, s.TypeName, &schema.BlockSchema{
+			Type:   schema.BlockTypeObject,
+			Labels: labelSchemas(s.LabelNames),
+		}, s.Nested)
+	case *hcldec.BlockAttrsSpec:
+		elemType := s.ElementType
+		if elemType == cty.NilType {
+			elemType = cty.DynamicPseudoType
+		}
+		block := &schema.BlockSchema{
+			Type:     schema.BlockTypeObject,
+			MaxItems: 1,
+			Body: &schema.BodySchema{
+				AnyAttribute: &schema.AttributeSchema{
+					ValueType:  elemType,
+					IsOptional: true,
+				},
+			},
+		}
+		if s.Required {
+			block.MinItems = 1
+		}
+		return addBlock(bs, s.TypeName, block, nil)
+	case *hcldec.DefaultSpec:
+		return addSpec(bs, s.Primary)
+	case *hcldec.TransformExprSpec:
+		return addSpec(bs, s.Wrapped)
+	case *hcldec.TransformFuncSpec:
+		return addSpec(bs, s.Wrapped)
+	case *hcldec.ValidateSpec:
+		return addSpec(bs, s.Wrapped)
+	case *hcldec.LiteralSpec, *hcldec.ExprSpec:
+		// not represented in configuration
+		return nil
+	case *hcldec.BlockLabelSpec:
+		// represented as label of the enclosing block (see addBlock)
+		return nil
+	default:
+		return fmt.Errorf("unsupported spec type: %T", spec)
+	}
+
+	return nil
+}
+
+func addAttribute(bs *schema.BodySchema, name string, attr *schema.AttributeSchema) error {
+	if _, ok := bs.Attributes[name]; ok {
+		return fmt.Errorf("attribute %q is declared more than once", name)
+	}
+	if _, ok := bs.Blocks[name]; ok {
+		return fmt.Errorf("%q is declared as both attribute and block", name)
+	}
+	bs.Attributes[name] = attr
+	return nil
+}
+
+func addBlock(bs *schema.BodySchema, bType string, block *schema.BlockSchema, nested hcldec.Spec) error {
+	if _, ok := bs.Blocks[bType]; ok {
+		return fmt.Errorf("block %q is declared more than once", bType)
+	}
+	if _, ok := bs.Attributes[bType]; ok {
+		return fmt.Errorf("%q is declared as both attribute and block", bType)
+	}
+
+	if block.Body == nil {
+		body, err := BodySchema(nested)
+		if err != nil {
+			return fmt.Errorf("%s: %w", bType, err)
+		}
+		block.Body = body
+	}
+
+	// labels declared by the nested spec follow
+	// any labels declared by the block spec itself
+	if names := labelSpecNames(nested); len(names) > 0 {
+		block.Labels = append(block.Labels, labelSchemas(names)...)
+	}
+
+	bs.Blocks[bType] = block
+	return nil
+}
+
+func labelSchemas(names []string) []*schema.LabelSchema {
+	labels := make([]*schema.LabelSchema, len(names))
+	for i, name := range names {
+		labels[i] = &schema.LabelSchema{Name: name}
+	}
+	return labels
+}
+
+// labelSpecNames returns names of BlockLabelSpecs within
+// the spec (describing the same body), ordered by their index
+func labelSpecNames(spec hcldec.Spec) []string {
+	names := make(map[int]string, 0)
+	maxIdx := -1
+
+	var visit func(spec hcldec.Spec)
+	visit = func(spec hcldec.Spec) {
+		switch s := spec.(type) {
+		case hcldec.ObjectSpec:
+			for _, child := range s {
+				visit(child)
+			}
+		case hcldec.TupleSpec:
+			for _, child := range s {
+				visit(child)
+			}
+		case *hcldec.DefaultSpec:
+			visit(s.Primary)
+			visit(s.Default)
+		case *hcldec.TransformExprSpec:
+			visit(s.Wrapped)
+		case *hcldec.TransformFuncSpec:
+			visit(s.Wrapped)
+		case *hcldec.ValidateSpec:
+			visit(s.Wrapped)
+		case *hcldec.BlockLabelSpec:
+			names[s.Index] = s.Name
+			if s.Index > maxIdx {
+				maxIdx = s.Index
+			}
+		}
+	}
+	visit(spec)
+
+	if maxIdx < 0 {
+		return nil
+	}
+	labelNames := make([]string, maxIdx+1)
+	for i := range labelNames {
+		name, ok := names[i]
+		if !ok {
+			// indices are expected to be consecutive
+			name = fmt.Sprintf("missing%02d", i)
+		}
+		labelNames[i] = name
+	}
+	return labelNames
+}
+
+func itemCount(count int) uint64 {
+	if count < 0 {
+		return 0
+	}
+	return uint64(count)
+}
@@ -0,0 +1,330 @@
+package hcldecschema
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/zclconf/go-cty-debug/ctydebug"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var cmpOpts = cmp.Options{ctydebug.CmpOptions, cmpopts.EquateEmpty()}
+
+func TestBodySchema(t *testing.T) {
+	testCases := []struct {
+		name           string
+		spec           hcldec.Spec
+		expectedSchema *schema.BodySchema
+	}{
+		{
+			"empty object",
+			hcldec.ObjectSpec{},
+			&schema.BodySchema{},
+		},
+		{
+			"attributes",
+			hcldec.ObjectSpec{
+				"name": &hcldec.AttrSpec{
+					Name:     "name",
+					Type:     cty.String,
+					Required: true,
+				},
+				"tags": &hcldec.AttrSpec{
+					Name: "tags",
+					Type: cty.Map(cty.String),
+				},
+				"any": &hcldec.AttrSpec{
+					Name: "any",
+				},
+			},
+			&schema.BodySchema{
+				Attributes: map[string]*schema.AttributeSchema{
+					"name": {ValueType: cty.String, IsRequired: true},
+					"tags": {ValueType: cty.Map(cty.String), IsOptional: true},
+					"any":  {ValueType: cty.DynamicPseudoType, IsOptional: true},
+				},
+			},
+		},
+		{
+			"attribute keyed differently from its name",
+			hcldec.ObjectSpec{
+				"image_name": &hcldec.AttrSpec{
+					Name: "image",
+					Type: cty.String,
+				},
+			},
+			&schema.BodySchema{
+				Attributes: map[string]*schema.AttributeSchema{
+					"image": {ValueType: cty.String, IsOptional: true},
+				},
+			},
+		},
+		{
+			"blocks",
+			hcldec.ObjectSpec{
+				"single": &hcldec.BlockSpec{
+					TypeName: "single",
+					Required: true,
+					Nested: hcldec.ObjectSpec{
+						"foo": &hcldec.AttrSpec{Name: "foo", Type: cty.Bool},
+					},
+				},
+				"list": &hcldec.BlockListSpec{
+					TypeName: "list",
+					MinItems: 1,
+					MaxItems: 3,
+				},
+				"set": &hcldec.BlockSetSpec{
+					TypeName: "set",
+					MaxItems: 2,
+				},
+				"tuple": &hcldec.BlockTupleSpec{
+					TypeName: "tuple",
+				},
+				"map": &hcldec.BlockMapSpec{
+					TypeName:   "map",
+					LabelNames: []string{"type", "name"},
+					Nested: hcldec.ObjectSpec{
+						"bar": &hcldec.AttrSpec{Name: "bar", Type: cty.Number},
+					},
+				},
+				"object": &hcldec.BlockObjectSpec{
+					TypeName:   "object",
+					LabelNames: []string{"key"},
+				},
+				"attrs": &hcldec.BlockAttrsSpec{
+					TypeName:    "attrs",
+					ElementType: cty.String,
+					Required:    true,
+				},
+			},
+			&schema.BodySchema{
+				Blocks: map[string]*schema.BlockSchema{
+					"single": {
+						Type:     schema.BlockTypeObject,
+						MinItems: 1,
+						MaxItems: 1,
+						Body: &schema.BodySchema{
+							Attributes: map[string]*schema.AttributeSchema{
+								"foo": {ValueType: cty.Bool, IsOptional: true},
+							},
+						},
+					},
+					"list": {
+						Type:     schema.BlockTypeList,
+						MinItems: 1,
+						MaxItems: 3,
+						Body:     &schema.BodySchema{},
+					},
+					"set": {
+						Type:     schema.BlockTypeSet,
+						MaxItems: 2,
+						Body:     &schema.BodySchema{},
+					},
+					"tuple": {
+						Type: schema.BlockTypeTuple,
+						Body: &schema.BodySchema{},
+					},
+					"map": {
+						Type: schema.BlockTypeMap,
+						Labels: []*schema.LabelSchema{
+							{Name: "type"},
+							{Name: "name"},
+						},
+						Body: &schema.BodySchema{
+							Attributes: map[string]*schema.AttributeSchema{
+								"bar": {ValueType: cty.Number, IsOptional: true},
+							},
+						},
+					},
+					"object": {
+						Type: schema.BlockTypeObject,
+						Labels: []*schema.LabelSchema{
+							{Name: "key"},
+						},
+						Body: &schema.BodySchema{},
+					},
+					"attrs": {
+						Type:     schema.BlockTypeObject,
+						MinItems: 1,
+						MaxItems: 1,
+						Body: &schema.BodySchema{
+							AnyAttribute: &schema.AttributeSchema{
+								ValueType:  cty.String,
+								IsOptional: true,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			"wrapping and ignored specs",
+			hcldec.ObjectSpec{
+				"default": &hcldec.DefaultSpec{
+					Primary: &hcldec.AttrSpec{Name: "region", Type: cty.String},
+					Default: &hcldec.LiteralSpec{Value: cty.StringVal("us-east-1")},
+				},
+				"validated": &hcldec.ValidateSpec{
+					Wrapped: &hcldec.AttrSpec{Name: "count", Type: cty.Number},
+					Func: func(cty.Value) hcl.Diagnostics {
+						return nil
+					},
+				},
+				"literal": &hcldec.LiteralSpec{Value: cty.True},
+				"label":   &hcldec.BlockLabelSpec{Index: 0, Name: "name"},
+				"tuple": hcldec.TupleSpec{
+					&hcldec.AttrSpec{Name: "first", Type: cty.String},
+				},
+			},
+			&schema.BodySchema{
+				Attributes: map[string]*schema.AttributeSchema{
+					"region": {ValueType: cty.String, IsOptional: true},
+					"count":  {ValueType: cty.Number, IsOptional: true},
+					"first":  {ValueType: cty.String, IsOptional: true},
+				},
+			},
+		},
+		{
+			"block labels",
+			hcldec.ObjectSpec{
+				"source": &hcldec.BlockListSpec{
+					TypeName: "source",
+					Nested: hcldec.ObjectSpec{
+						"name": &hcldec.BlockLabelSpec{Index: 1, Name: "name"},
+						"type": &hcldec.BlockLabelSpec{Index: 0, Name: "type"},
+						"communicator": &hcldec.DefaultSpec{
+							Primary: &hcldec.AttrSpec{Name: "communicator", Type: cty.String},
+							Default: &hcldec.LiteralSpec{Value: cty.StringVal("ssh")},
+						},
+					},
+				},
+				"build": &hcldec.BlockSpec{
+					TypeName: "build",
+					Nested: &hcldec.ValidateSpec{
+						Wrapped: &hcldec.BlockLabelSpec{Index: 0, Name: "name"},
+						Func: func(cty.Value) hcl.Diagnostics {
+							return nil
+						},
+					},
+				},
+				"provisioner": &hcldec.BlockMapSpec{
+					TypeName:   "provisioner",
+					LabelNames: []string{"type"},
+					Nested: hcldec.ObjectSpec{
+						"name": &hcldec.BlockLabelSpec{Index: 0, Name: "name"},
+					},
+				},
+			},
+			&schema.BodySchema{
+				Blocks: map[string]*schema.BlockSchema{
+					"source": {
+						Type: schema.BlockTypeList,
+						Labels: []*schema.LabelSchema{
+							{Name: "type"},
+							{Name: "name"},
+						},
+						Body: &schema.BodySchema{
+							Attributes: map[string]*schema.AttributeSchema{
+								"communicator": {ValueType: cty.String, IsOptional: true},
+							},
+						},
+					},
+					"build": {
+						Type:     schema.BlockTypeObject,
+						MaxItems: 1,
+						Labels: []*schema.LabelSchema{
+							{Name: "name"},
+						},
+						Body: &schema.BodySchema{},
+					},
+					"provisioner": {
+						Type: schema.BlockTypeMap,
+						Labels: []*schema.LabelSchema{
+							{Name: "type"},
+							{Name: "name"},
+						},
+						Body: &schema.BodySchema{},
+					},
+				},
+			},
+		},
+		{
+			"single attribute spec",
+			&hcldec.AttrSpec{Name: "foo", Type: cty.String, Required: true},
+			&schema.BodySchema{
+				Attributes: map[string]*schema.AttributeSchema{
+					"foo": {ValueType: cty.String, IsRequired: true},
+				},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			bs, err := BodySchema(tc.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(tc.expectedSchema, bs, cmpOpts); diff != "" {
+				t.Fatalf("unexpected schema: %s", diff)
+			}
+		})
+	}
+}
+
+func TestBodySchema_invalid(t *testing.T) {
+	testCases := []struct {
+		name          string
+		spec          hcldec.Spec
+		expectedError string
+	}{
+		{
+			"duplicate attribute",
+			hcldec.TupleSpec{
+				&hcldec.AttrSpec{Name: "foo", Type: cty.String},
+				&hcldec.AttrSpec{Name: "foo", Type: cty.Number},
+			},
+			`attribute "foo" is declared more than once`,
+		},
+		{
+			"attribute and block",
+			hcldec.TupleSpec{
+				&hcldec.AttrSpec{Name: "foo", Type: cty.String},
+				&hcldec.BlockSpec{TypeName: "foo"},
+			},
+			`"foo" is declared as both attribute and block`,
+		},
+		{
+			"nested error",
+			hcldec.ObjectSpec{
+				"outer": &hcldec.BlockListSpec{
+					TypeName: "outer",
+					Nested: hcldec.TupleSpec{
+						&hcldec.BlockSpec{TypeName: "inner"},
+						&hcldec.BlockSpec{TypeName: "inner"},
+					},
+				},
+			},
+			`outer: block "inner" is declared more than once`,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			_, err := BodySchema(tc.spec)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if diff := cmp.Diff(tc.expectedError, err.Error()); diff != "" {
+				t.Fatalf("unexpected error: %s", diff)
+			}
+		})
+	}
+}
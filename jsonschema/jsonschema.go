@@ -0,0 +1,440 @@
+// Package jsonschema exports schema understood by the decoder
+// as JSON Schema (draft-07) describing the HCL JSON syntax,
+// so that tools which only understand JSON Schema can validate
+// configuration written in JSON.
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Draft represents the JSON Schema version of exported documents
+const Draft = "http://json-schema.org/draft-07/schema#"
+
+// Schema represents a JSON Schema document (or a subschema)
+type Schema struct {
+	SchemaURI   string `json:"$schema,omitempty"`
+	Ref         string `json:"$ref,omitempty"`
+	Type        string `json:"type,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"`
+
+	Items    interface{} `json:"items,omitempty"`
+	MinItems *uint64     `json:"minItems,omitempty"`
+	MaxItems *uint64     `json:"maxItems,omitempty"`
+
+	Enum  []interface{} `json:"enum,omitempty"`
+	AnyOf []*Schema     `json:"anyOf,omitempty"`
+	AllOf []*Schema     `json:"allOf,omitempty"`
+	If    *Schema       `json:"if,omitempty"`
+	Then  *Schema       `json:"then,omitempty"`
+
+	Definitions map[string]*Schema `json:"definitions,omitempty"`
+}
+
+// Export produces JSON Schema document describing configuration
+// in HCL JSON syntax which conforms to the given body schema.
+//
+// Blocks are represented according to their BlockType, i.e. list,
+// set and tuple as array, object as a single object and map as object
+// keyed by label. Labels of blocks are represented as nested object keys
+// and dependent bodies keyed by labels are represented via if/then.
+// Dependent bodies keyed by attributes are not represented.
+//
+// Recursive blocks (i.e. blocks nested within a block of the same schema)
+// are represented as $ref to the schema of the enclosing block,
+// which is moved to definitions.
+func Export(bs *schema.BodySchema) (*Schema, error) {
+	e := &exporter{
+		parents:     make(map[*schema.BlockSchema]string, 0),
+		referenced:  make(map[*schema.BlockSchema]bool, 0),
+		definitions: make(map[string]*Schema, 0),
+	}
+	s, err := e.bodySchema(schema.NewLayeredBodySchema(bs))
+	if err != nil {
+		return nil, err
+	}
+	s.SchemaURI = Draft
+	if len(e.definitions) > 0 {
+		s.Definitions = e.definitions
+	}
+	return s, nil
+}
+
+// exporter keeps track of blocks being exported,
+// such that recursive blocks can be referenced
+type exporter struct {
+	// parents maps schemas of the enclosing blocks
+	// to names of their (potential) definitions
+	parents map[*schema.BlockSchema]string
+	// referenced marks enclosing blocks referenced by nested blocks
+	referenced  map[*schema.BlockSchema]bool
+	definitions map[string]*Schema
+}
+
+func (e *exporter) bodySchema(bs *schema.LayeredBodySchema) (*Schema, error) {
+	s := &Schema{
+		Type:       "object",
+		Properties: make(map[string]*Schema, 0),
+	}
+	required := make([]string, 0)
+
+	for _, name := range bs.AttributeNames() {
+		attr, _ := bs.Attribute(name)
+		as, err := attributeSchema(attr)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		s.Properties[name] = as
+		if attr.IsRequired {
+			required = append(required, name)
+		}
+	}
+
+	for _, bType := range bs.BlockTypes() {
+		block, _ := bs.Block(bType)
+		bSchema, err := e.blockSchema(bType, block)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", bType, err)
+		}
+		s.Properties[bType] = bSchema
+		if block.MinItems > 0 {
+			required = append(required, bType)
+		}
+	}
+
+	if attr := bs.AnyAttribute(); attr != nil {
+		as, err := attributeSchema(attr)
+		if err != nil {
+			return nil, err
+		}
+		s.AdditionalProperties = as
+	} else {
+		s.AdditionalProperties = false
+	}
+
+	if len(required) > 0 {
+		sort.Strings(required)
+		s.Required = required
+	}
+
+	return s, nil
+}
+
+func (e *exporter) blockSchema(bType string, block *schema.BlockSchema) (*Schema, error) {
+	if name, ok := e.parents[block]; ok {
+		e.referenced[block] = true
+		return &Schema{Ref: definitionRef(name)}, nil
+	}
+
+	name := e.definitionName(bType)
+	e.parents[block] = name
+	defer delete(e.parents, block)
+
+	s, err := e.labelSchema(block, 0, []schema.LabelDependent{})
+	if err != nil {
+		return nil, err
+	}
+	if block.Description.Value != "" {
+		s.Description = block.Description.Value
+	}
+
+	if e.referenced[block] {
+		delete(e.referenced, block)
+		e.definitions[name] = s
+		return &Schema{Ref: definitionRef(name)}, nil
+	}
+	return s, nil
+}
+
+// definitionName returns name for definition of the block type
+// which is used by neither existing definitions nor enclosing blocks
+func (e *exporter) definitionName(bType string) string {
+	isUsed := func(name string) bool {
+		if _, ok := e.definitions[name]; ok {
+			return true
+		}
+		for _, parentName := range e.parents {
+			if parentName == name {
+				return true
+			}
+		}
+		return false
+	}
+
+	name := bType
+	for i := 2; isUsed(name); i++ {
+		name = fmt.Sprintf("%s_%d", bType, i)
+	}
+	return name
+}
+
+func definitionRef(name string) string {
+	return "#/definitions/" + name
+}
+
+// labelSchema returns schema of the label on the given index
+// (represented as object keys), or of the block body
+// if there are no more labels
+func (e *exporter) labelSchema(block *schema.BlockSchema, idx int, labels []schema.LabelDependent) (*Schema, error) {
+	if idx >= len(block.Labels) {
+		return e.blockBodySchema(block, labels)
+	}
+
+	next, err := e.labelSchema(block, idx+1, labels)
+	if err != nil {
+		return nil, err
+	}
+	s := &Schema{
+		Type:                 "object",
+		AdditionalProperties: next,
+	}
+
+	if !block.Labels[idx].IsDepKey {
+		return s, nil
+	}
+
+	for _, value := range dependentLabelValues(block, idx, labels) {
+		depLabels := append(labels[:len(labels):len(labels)], schema.LabelDependent{
+			Index: idx,
+			Value: value,
+		})
+		depSchema, err := e.labelSchema(block, idx+1, depLabels)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", value, err)
+		}
+
+		// dependent values are listed in properties to exclude them
+		// from additionalProperties, the actual schema is applied via if/then
+		if s.Properties == nil {
+			s.Properties = make(map[string]*Schema, 0)
+		}
+		s.Properties[value] = &Schema{}
+
+		s.AllOf = append(s.AllOf, &Schema{
+			If: &Schema{
+				Required: []string{value},
+			},
+			Then: &Schema{
+				Properties: map[string]*Schema{
+					value: depSchema,
+				},
+			},
+		})
+	}
+
+	return s, nil
+}
+
+func (e *exporter) blockBodySchema(block *schema.BlockSchema, labels []schema.LabelDependent) (*Schema, error) {
+	depBody, _ := block.DependentBodySchema(schema.DependencyKeys{
+		Labels: labels,
+	})
+
+	body, err := e.bodySchema(schema.NewLayeredBodySchema(block.Body, depBody))
+	if err != nil {
+		return nil, err
+	}
+
+	switch block.Type {
+	case schema.BlockTypeList, schema.BlockTypeSet, schema.BlockTypeTuple:
+		s := &Schema{
+			Type:  "array",
+			Items: body,
+		}
+		if block.MinItems > 0 {
+			minItems := block.MinItems
+			s.MinItems = &minItems
+		}
+		if block.MaxItems > 0 {
+			maxItems := block.MaxItems
+			s.MaxItems = &maxItems
+		}
+		return s, nil
+	case schema.BlockTypeMap:
+		if len(block.Labels) == 0 {
+			return &Schema{
+				Type:                 "object",
+				AdditionalProperties: body,
+			}, nil
+		}
+	}
+
+	return body, nil
+}
+
+// dependentLabelValues returns sorted values of the label on the given
+// index which have a dependent body, given values of preceding labels
+func dependentLabelValues(block *schema.BlockSchema, idx int, labels []schema.LabelDependent) []string {
+	seen := make(map[string]bool, 0)
+	values := make([]string, 0)
+
+	for key := range block.DependentBody {
+		var dks schema.DependencyKeys
+		err := json.Unmarshal([]byte(key), &dks)
+		if err != nil || len(dks.Attributes) > 0 {
+			continue
+		}
+
+		value, ok := "", false
+		matches := true
+		for _, ld := range dks.Labels {
+			if ld.Index == idx {
+				value, ok = ld.Value, true
+				continue
+			}
+			if ld.Index < idx && !containsLabel(labels, ld) {
+				matches = false
+			}
+		}
+		if !ok || !matches || seen[value] {
+			continue
+		}
+
+		seen[value] = true
+		values = append(values, value)
+	}
+
+	sort.Strings(values)
+	return values
+}
+
+func containsLabel(labels []schema.LabelDependent, ld schema.LabelDependent) bool {
+	for _, l := range labels {
+		if l == ld {
+			return true
+		}
+	}
+	return false
+}
+
+func attributeSchema(attr *schema.AttributeSchema) (*Schema, error) {
+	var s *Schema
+	if len(attr.ValueTypes) > 0 {
+		s = &Schema{}
+		for _, vt := range attr.ValueTypes {
+			ts, err := typeSchema(vt)
+			if err != nil {
+				return nil, err
+			}
+			s.AnyOf = append(s.AnyOf, ts)
+		}
+	} else {
+		ts, err := typeSchema(attr.ValueType)
+		if err != nil {
+			return nil, err
+		}
+		s = ts
+	}
+
+	s.Description = attr.Description.Value
+
+	if len(attr.AllowedValues) > 0 {
+		s.Enum = make([]interface{}, len(attr.AllowedValues))
+		for i, av := range attr.AllowedValues {
+			s.Enum[i] = av.Value
+		}
+	}
+
+	if len(attr.MapKeys) > 0 && s.Type == "object" {
+		s.Properties = make(map[string]*Schema, len(attr.MapKeys))
+		required := make([]string, 0)
+		for key, mk := range attr.MapKeys {
+			ks := &Schema{}
+			if additional, ok := s.AdditionalProperties.(*Schema); ok {
+				copied := *additional
+				ks = &copied
+			}
+			if mk != nil {
+				ks.Description = mk.Description.Value
+				if mk.IsRequired {
+					required = append(required, key)
+				}
+			}
+			s.Properties[key] = ks
+		}
+		if len(required) > 0 {
+			sort.Strings(required)
+			s.Required = required
+		}
+		if attr.IsMapKeysExhaustive {
+			s.AdditionalProperties = false
+		}
+	}
+
+	return s, nil
+}
+
+func typeSchema(t cty.Type) (*Schema, error) {
+	switch {
+	case t == cty.NilType:
+		return nil, fmt.Errorf("missing type")
+	case t == cty.DynamicPseudoType:
+		return &Schema{}, nil
+	case t == cty.String:
+		return &Schema{Type: "string"}, nil
+	case t == cty.Number:
+		return &Schema{Type: "number"}, nil
+	case t == cty.Bool:
+		return &Schema{Type: "boolean"}, nil
+	case t.IsListType(), t.IsSetType():
+		items, err := typeSchema(t.ElementType())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "array", Items: items}, nil
+	case t.IsMapType():
+		elem, err := typeSchema(t.ElementType())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "object", AdditionalProperties: elem}, nil
+	case t.IsTupleType():
+		elemTypes := t.TupleElementTypes()
+		items := make([]*Schema, len(elemTypes))
+		for i, et := range elemTypes {
+			es, err := typeSchema(et)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = es
+		}
+		count := uint64(len(elemTypes))
+		return &Schema{
+			Type:     "array",
+			Items:    items,
+			MinItems: &count,
+			MaxItems: &count,
+		}, nil
+	case t.IsObjectType():
+		s := &Schema{
+			Type:                 "object",
+			Properties:           make(map[string]*Schema, 0),
+			AdditionalProperties: false,
+		}
+		required := make([]string, 0)
+		for name, at := range t.AttributeTypes() {
+			as, err := typeSchema(at)
+			if err != nil {
+				return nil, err
+			}
+			s.Properties[name] = as
+			required = append(required, name)
+		}
+		if len(required) > 0 {
+			sort.Strings(required)
+			s.Required = required
+		}
+		return s, nil
+	}
+
+	return nil, fmt.Errorf("unsupported type: %s", t.FriendlyName())
+}
@@ -0,0 +1,241 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var testBodySchema = &schema.BodySchema{
+	Attributes: map[string]*schema.AttributeSchema{
+		"name": {
+			ValueType:   cty.String,
+			IsRequired:  true,
+			Description: lang.PlainText("Name of the project"),
+		},
+		"tier": {
+			ValueType:  cty.String,
+			IsOptional: true,
+			AllowedValues: []schema.AllowedValue{
+				{Value: "Standard"},
+				{Value: "Premium"},
+			},
+		},
+		"tags": {
+			ValueType:  cty.Map(cty.String),
+			IsOptional: true,
+			MapKeys: map[string]*schema.MapKeySchema{
+				"Owner": {IsRequired: true, Description: lang.PlainText("Owner of the project")},
+			},
+			IsMapKeysExhaustive: true,
+		},
+		"port": {
+			ValueTypes: schema.ValueTypes{cty.Number, cty.String},
+			IsOptional: true,
+		},
+	},
+	Blocks: map[string]*schema.BlockSchema{
+		"lifecycle": {
+			Type:     schema.BlockTypeObject,
+			MaxItems: 1,
+			Body: &schema.BodySchema{
+				Attributes: map[string]*schema.AttributeSchema{
+					"create_before_destroy": {ValueType: cty.Bool, IsOptional: true},
+				},
+			},
+		},
+		"setting": {
+			Type: schema.BlockTypeMap,
+			Labels: []*schema.LabelSchema{
+				{Name: "key"},
+			},
+			Body: &schema.BodySchema{
+				AnyAttribute: &schema.AttributeSchema{ValueType: cty.String, IsOptional: true},
+			},
+		},
+		"resource": {
+			Type:        schema.BlockTypeList,
+			Description: lang.PlainText("Resource block"),
+			MinItems:    1,
+			Labels: []*schema.LabelSchema{
+				{Name: "type", IsDepKey: true},
+				{Name: "name"},
+			},
+			Body: &schema.BodySchema{
+				Attributes: map[string]*schema.AttributeSchema{
+					"count": {ValueType: cty.Number, IsOptional: true},
+				},
+			},
+			DependentBody: map[schema.SchemaKey]*schema.BodySchema{
+				schema.NewSchemaKey(schema.DependencyKeys{
+					Labels: []schema.LabelDependent{
+						{Index: 0, Value: "aws_instance"},
+					},
+				}): {
+					Attributes: map[string]*schema.AttributeSchema{
+						"ami": {ValueType: cty.String, IsRequired: true},
+						"ebs": {
+							ValueType: cty.Object(map[string]cty.Type{
+								"size": cty.Number,
+							}),
+							IsOptional: true,
+						},
+					},
+				},
+			},
+		},
+	},
+}
+
+func TestExport(t *testing.T) {
+	s, err := Export(testBodySchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected, err := ioutil.ReadFile(filepath.Join("testdata", "export.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(string(expected), string(b)+"\n"); diff != "" {
+		t.Fatalf("unexpected JSON Schema: %s", diff)
+	}
+}
+
+func TestExport_recursiveBlock(t *testing.T) {
+	nodeSchema := &schema.BlockSchema{
+		Type: schema.BlockTypeList,
+		Body: &schema.BodySchema{
+			Attributes: map[string]*schema.AttributeSchema{
+				"name": {ValueType: cty.String, IsOptional: true},
+			},
+		},
+	}
+	nodeSchema.Body.Blocks = map[string]*schema.BlockSchema{
+		"node": nodeSchema,
+	}
+
+	s, err := Export(&schema.BodySchema{
+		Blocks: map[string]*schema.BlockSchema{
+			"node": nodeSchema,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"$schema":"http://json-schema.org/draft-07/schema#","type":"object",` +
+		`"properties":{"node":{"$ref":"#/definitions/node"}},"additionalProperties":false,` +
+		`"definitions":{"node":{"type":"array","items":{"type":"object",` +
+		`"properties":{"name":{"type":"string"},"node":{"$ref":"#/definitions/node"}},` +
+		`"additionalProperties":false}}}}`
+	if diff := cmp.Diff(expected, string(b)); diff != "" {
+		t.Fatalf("unexpected JSON Schema: %s", diff)
+	}
+}
+
+func TestExport_invalidType(t *testing.T) {
+	bs := &schema.BodySchema{
+		Blocks: map[string]*schema.BlockSchema{
+			"foo": {
+				Body: &schema.BodySchema{
+					Attributes: map[string]*schema.AttributeSchema{
+						"bar": {IsOptional: true},
+					},
+				},
+			},
+		},
+	}
+
+	_, err := Export(bs)
+	if err == nil {
+		t.Fatal("expected error for missing type")
+	}
+	expectedErr := "foo: bar: missing type"
+	if diff := cmp.Diff(expectedErr, err.Error()); diff != "" {
+		t.Fatalf("unexpected error: %s", diff)
+	}
+}
+
+func TestTypeSchema(t *testing.T) {
+	two := uint64(2)
+
+	testCases := []struct {
+		t              cty.Type
+		expectedSchema *Schema
+	}{
+		{cty.DynamicPseudoType, &Schema{}},
+		{cty.String, &Schema{Type: "string"}},
+		{cty.Number, &Schema{Type: "number"}},
+		{cty.Bool, &Schema{Type: "boolean"}},
+		{
+			cty.List(cty.String),
+			&Schema{Type: "array", Items: &Schema{Type: "string"}},
+		},
+		{
+			cty.Set(cty.Number),
+			&Schema{Type: "array", Items: &Schema{Type: "number"}},
+		},
+		{
+			cty.Map(cty.Bool),
+			&Schema{Type: "object", AdditionalProperties: &Schema{Type: "boolean"}},
+		},
+		{
+			cty.Tuple([]cty.Type{cty.String, cty.Number}),
+			&Schema{
+				Type: "array",
+				Items: []*Schema{
+					{Type: "string"},
+					{Type: "number"},
+				},
+				MinItems: &two,
+				MaxItems: &two,
+			},
+		},
+		{
+			cty.Object(map[string]cty.Type{
+				"foo": cty.String,
+				"bar": cty.List(cty.Number),
+			}),
+			&Schema{
+				Type: "object",
+				Properties: map[string]*Schema{
+					"foo": {Type: "string"},
+					"bar": {Type: "array", Items: &Schema{Type: "number"}},
+				},
+				Required:             []string{"bar", "foo"},
+				AdditionalProperties: false,
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.t.FriendlyName()), func(t *testing.T) {
+			s, err := typeSchema(tc.t)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expectedSchema, s); diff != "" {
+				t.Fatalf("unexpected schema: %s", diff)
+			}
+		})
+	}
+}
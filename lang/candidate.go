@@ -9,12 +9,17 @@ const (
 	AttributeCandidateKind
 	BlockCandidateKind
 	LabelCandidateKind
+	ValueCandidateKind
+	TraversalCandidateKind
+	FunctionCandidateKind
+	KeywordCandidateKind
 )
 
 type CandidateKind uint
 
 // Candidate represents a completion candidate in the form of
-// an attribute, block, or a label
+// an attribute, block, label, a value, or an expression
+// (reference, function or keyword)
 type Candidate struct {
 	Label               string
 	Description         MarkupContent
@@ -27,6 +32,19 @@ type Candidate struct {
 	// TriggerSuggest allows server to instruct the client whether
 	// to reopen candidate suggestion popup after insertion
 	TriggerSuggest bool
+
+	// SortText is used by clients to order candidates instead of Label,
+	// such that the order of candidates (as ranked by relevance)
+	// is preserved
+	SortText string
+
+	// FilterText is used by clients to filter candidates
+	// as the user types, instead of Label
+	FilterText string
+
+	// ResolveKey is an opaque key identifying the candidate
+	// whose details are to be resolved lazily (if enabled)
+	ResolveKey string
 }
 
 // TextEdit represents a change (edit) of an HCL config file
@@ -50,6 +68,11 @@ type TextEdit struct {
 type Candidates struct {
 	List       []Candidate
 	IsComplete bool
+
+	// ContinuationKey is an opaque key which can be used to obtain
+	// the next page of candidates of an incomplete list
+	// (see decoder's CandidatesContinuation).
+	ContinuationKey string
 }
 
 // NewCandidates creates a new (incomplete) list of candidates
@@ -3,6 +3,7 @@ package lang
 import (
 	"fmt"
 
+	"github.com/hashicorp/hcl/v2"
 	"github.com/zclconf/go-cty/cty"
 )
 
@@ -41,6 +42,30 @@ func (r Reference) Marshal() ([]byte, error) {
 	return []byte(ref), nil
 }
 
+// TraversalToReference converts the (absolute) traversal into Reference
+func TraversalToReference(traversal hcl.Traversal) (Reference, error) {
+	r := Reference{}
+	for _, tr := range traversal {
+		switch t := tr.(type) {
+		case hcl.TraverseRoot:
+			r = append(r, RootStep{
+				Name: t.Name,
+			})
+		case hcl.TraverseAttr:
+			r = append(r, AttrStep{
+				Name: t.Name,
+			})
+		case hcl.TraverseIndex:
+			r = append(r, IndexStep{
+				Key: t.Key,
+			})
+		default:
+			return r, fmt.Errorf("invalid traversal: %T", tr)
+		}
+	}
+	return r, nil
+}
+
 type RootStep struct {
 	Name string `json:"name"`
 }
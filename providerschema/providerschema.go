@@ -0,0 +1,273 @@
+// Package providerschema converts provider schemas as produced by
+// `terraform providers schema -json` (represented by terraform-json)
+// into schema understood by the decoder.
+package providerschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+const defaultRegistryHost = "registry.terraform.io"
+
+// LoadFile reads provider schemas from a local file
+// containing output of `terraform providers schema -json`
+func LoadFile(path string) (*tfjson.ProviderSchemas, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	ps := &tfjson.ProviderSchemas{}
+	err = json.Unmarshal(b, ps)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return ps, nil
+}
+
+// ProviderBlockSchema returns schema of the provider block
+// with DependentBody keyed by provider name (first label).
+//
+// Body of the returned block is left empty, so that it can be populated
+// with any attributes common to all providers (e.g. alias).
+func ProviderBlockSchema(ps *tfjson.ProviderSchemas) *schema.BlockSchema {
+	bs := &schema.BlockSchema{
+		Type: schema.BlockTypeObject,
+		Labels: []*schema.LabelSchema{
+			{
+				Name:     "name",
+				IsDepKey: true,
+			},
+		},
+		DependentBody: make(map[schema.SchemaKey]*schema.BodySchema, 0),
+	}
+
+	forEachProvider(ps, func(addr string, provider *tfjson.ProviderSchema) {
+		if provider.ConfigSchema == nil {
+			return
+		}
+		name := localName(addr)
+		key := labelSchemaKey(name)
+		if _, ok := bs.DependentBody[key]; ok {
+			return
+		}
+		body := BodySchema(provider.ConfigSchema.Block)
+		body.Detail = providerDetail(addr)
+		bs.DependentBody[key] = body
+	})
+
+	return bs
+}
+
+// ResourceBlockSchema returns schema of the resource block
+// with DependentBody keyed by resource type (first label).
+//
+// Body of the returned block is left empty, so that it can be populated
+// with any meta-arguments (e.g. count or provider).
+func ResourceBlockSchema(ps *tfjson.ProviderSchemas) *schema.BlockSchema {
+	bs := &schema.BlockSchema{
+		Type: schema.BlockTypeObject,
+		Labels: []*schema.LabelSchema{
+			{
+				Name:     "type",
+				IsDepKey: true,
+			},
+			{Name: "name"},
+		},
+		// e.g. aws_instance.web
+		Address: &schema.BlockAddrSchema{
+			Steps: []schema.AddrStep{
+				schema.LabelStep{Index: 0},
+				schema.LabelStep{Index: 1},
+			},
+		},
+		DependentBody: make(map[schema.SchemaKey]*schema.BodySchema, 0),
+	}
+
+	forEachProvider(ps, func(addr string, provider *tfjson.ProviderSchema) {
+		addTypeSchemas(bs.DependentBody, addr, provider.ResourceSchemas)
+	})
+
+	return bs
+}
+
+// DataBlockSchema returns schema of the data block
+// with DependentBody keyed by data source type (first label).
+//
+// Body of the returned block is left empty, so that it can be populated
+// with any meta-arguments (e.g. count or provider).
+func DataBlockSchema(ps *tfjson.ProviderSchemas) *schema.BlockSchema {
+	bs := &schema.BlockSchema{
+		Type: schema.BlockTypeObject,
+		Labels: []*schema.LabelSchema{
+			{
+				Name:     "type",
+				IsDepKey: true,
+			},
+			{Name: "name"},
+		},
+		// e.g. data.aws_ami.ubuntu
+		Address: &schema.BlockAddrSchema{
+			Steps: []schema.AddrStep{
+				schema.StaticStep{Name: "data"},
+				schema.LabelStep{Index: 0},
+				schema.LabelStep{Index: 1},
+			},
+		},
+		DependentBody: make(map[schema.SchemaKey]*schema.BodySchema, 0),
+	}
+
+	forEachProvider(ps, func(addr string, provider *tfjson.ProviderSchema) {
+		addTypeSchemas(bs.DependentBody, addr, provider.DataSourceSchemas)
+	})
+
+	return bs
+}
+
+// BodySchema converts the given terraform-json block into BodySchema
+func BodySchema(block *tfjson.SchemaBlock) *schema.BodySchema {
+	bs := schema.NewBodySchema()
+	if block == nil {
+		return bs
+	}
+
+	bs.Description = markupContent(block.Description, block.DescriptionKind)
+	bs.IsDeprecated = block.Deprecated
+
+	for name, attr := range block.Attributes {
+		bs.Attributes[name] = attributeSchema(attr)
+	}
+
+	for bType, nestedBlock := range block.NestedBlocks {
+		bs.Blocks[bType] = blockSchema(nestedBlock)
+	}
+
+	return bs
+}
+
+func attributeSchema(attr *tfjson.SchemaAttribute) *schema.AttributeSchema {
+	return &schema.AttributeSchema{
+		ValueType:    attr.AttributeType,
+		Description:  markupContent(attr.Description, attr.DescriptionKind),
+		IsRequired:   attr.Required,
+		IsOptional:   attr.Optional,
+		IsComputed:   attr.Computed,
+		IsDeprecated: attr.Deprecated,
+	}
+}
+
+func blockSchema(block *tfjson.SchemaBlockType) *schema.BlockSchema {
+	bs := &schema.BlockSchema{
+		Type:     blockType(block.NestingMode),
+		MinItems: block.MinItems,
+		MaxItems: block.MaxItems,
+		Body:     BodySchema(block.Block),
+	}
+
+	if block.Block != nil {
+		bs.Description = bs.Body.Description
+		bs.IsDeprecated = bs.Body.IsDeprecated
+	}
+
+	if bs.Type == schema.BlockTypeMap {
+		// map blocks are keyed by their only label
+		bs.Labels = []*schema.LabelSchema{
+			{Name: "name"},
+		}
+	}
+
+	return bs
+}
+
+func blockType(mode tfjson.SchemaNestingMode) schema.BlockType {
+	switch mode {
+	case tfjson.SchemaNestingModeSingle:
+		return schema.BlockTypeObject
+	case tfjson.SchemaNestingModeList:
+		return schema.BlockTypeList
+	case tfjson.SchemaNestingModeSet:
+		return schema.BlockTypeSet
+	case tfjson.SchemaNestingModeMap:
+		return schema.BlockTypeMap
+	}
+	return schema.BlockTypeNil
+}
+
+func markupContent(value string, kind tfjson.SchemaDescriptionKind) lang.MarkupContent {
+	if value == "" {
+		return lang.MarkupContent{}
+	}
+	if kind == tfjson.SchemaDescriptionKindMarkdown {
+		return lang.Markdown(value)
+	}
+	return lang.PlainText(value)
+}
+
+func addTypeSchemas(depBody map[schema.SchemaKey]*schema.BodySchema, addr string, schemas map[string]*tfjson.Schema) {
+	for typeName, s := range schemas {
+		key := labelSchemaKey(typeName)
+		if _, ok := depBody[key]; ok {
+			continue
+		}
+		var block *tfjson.SchemaBlock
+		if s != nil {
+			block = s.Block
+		}
+		body := BodySchema(block)
+		body.Detail = providerDetail(addr)
+		depBody[key] = body
+	}
+}
+
+// forEachProvider calls the given function for each provider
+// in a stable order, so that the first provider (by address)
+// wins when multiple providers declare the same name or type
+func forEachProvider(ps *tfjson.ProviderSchemas, f func(addr string, provider *tfjson.ProviderSchema)) {
+	if ps == nil {
+		return
+	}
+
+	addrs := make([]string, 0, len(ps.Schemas))
+	for addr := range ps.Schemas {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+
+	for _, addr := range addrs {
+		provider := ps.Schemas[addr]
+		if provider == nil {
+			continue
+		}
+		f(addr, provider)
+	}
+}
+
+func labelSchemaKey(value string) schema.SchemaKey {
+	return schema.NewSchemaKey(schema.DependencyKeys{
+		Labels: []schema.LabelDependent{
+			{Index: 0, Value: value},
+		},
+	})
+}
+
+// localName returns name of the provider as used in configuration,
+// e.g. "aws" for "registry.terraform.io/hashicorp/aws"
+func localName(addr string) string {
+	parts := strings.Split(addr, "/")
+	return parts[len(parts)-1]
+}
+
+// providerDetail returns address of the provider without
+// the default registry hostname, e.g. "hashicorp/aws"
+func providerDetail(addr string) string {
+	return strings.TrimPrefix(addr, defaultRegistryHost+"/")
+}
@@ -0,0 +1,238 @@
+package providerschema
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl-lang/schema"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/zclconf/go-cty-debug/ctydebug"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var cmpOpts = cmp.Options{ctydebug.CmpOptions, cmpopts.EquateEmpty()}
+
+func TestLoadFile(t *testing.T) {
+	ps, err := LoadFile(filepath.Join("testdata", "schemas.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ps.Schemas) != 2 {
+		t.Fatalf("expected 2 providers, given: %d", len(ps.Schemas))
+	}
+}
+
+func TestLoadFile_invalidVersion(t *testing.T) {
+	_, err := LoadFile(filepath.Join("testdata", "invalid-version.json"))
+	if err == nil {
+		t.Fatal("expected error for unsupported format version")
+	}
+}
+
+func TestProviderBlockSchema(t *testing.T) {
+	ps := loadTestSchemas(t)
+
+	expectedSchema := &schema.BlockSchema{
+		Type: schema.BlockTypeObject,
+		Labels: []*schema.LabelSchema{
+			{Name: "name", IsDepKey: true},
+		},
+		DependentBody: map[schema.SchemaKey]*schema.BodySchema{
+			labelSchemaKey("aws"): {
+				Detail: "hashicorp/aws",
+				Attributes: map[string]*schema.AttributeSchema{
+					"region": {
+						ValueType:   cty.String,
+						Description: lang.PlainText("The region where AWS operations will take place."),
+						IsRequired:  true,
+					},
+				},
+			},
+			labelSchemaKey("null"): {
+				Detail: "hashicorp/null",
+			},
+		},
+	}
+
+	bs := ProviderBlockSchema(ps)
+	if diff := cmp.Diff(expectedSchema, bs, cmpOpts); diff != "" {
+		t.Fatalf("unexpected schema: %s", diff)
+	}
+}
+
+func TestResourceBlockSchema(t *testing.T) {
+	ps := loadTestSchemas(t)
+
+	expectedSchema := &schema.BlockSchema{
+		Type: schema.BlockTypeObject,
+		Labels: []*schema.LabelSchema{
+			{Name: "type", IsDepKey: true},
+			{Name: "name"},
+		},
+		Address: &schema.BlockAddrSchema{
+			Steps: []schema.AddrStep{
+				schema.LabelStep{Index: 0},
+				schema.LabelStep{Index: 1},
+			},
+		},
+		DependentBody: map[schema.SchemaKey]*schema.BodySchema{
+			labelSchemaKey("aws_instance"): {
+				Detail: "hashicorp/aws",
+				Attributes: map[string]*schema.AttributeSchema{
+					"ami": {
+						ValueType:   cty.String,
+						Description: lang.Markdown("AMI to use for the instance"),
+						IsRequired:  true,
+					},
+					"id": {
+						ValueType:  cty.String,
+						IsOptional: true,
+						IsComputed: true,
+					},
+					"tags": {
+						ValueType:  cty.Map(cty.String),
+						IsOptional: true,
+					},
+				},
+				Blocks: map[string]*schema.BlockSchema{
+					"credit_specification": {
+						Type:         schema.BlockTypeList,
+						Description:  lang.Markdown("Credit options"),
+						IsDeprecated: true,
+						MinItems:     1,
+						MaxItems:     1,
+						Body: &schema.BodySchema{
+							Description:  lang.Markdown("Credit options"),
+							IsDeprecated: true,
+						},
+					},
+					"ebs_block_device": {
+						Type: schema.BlockTypeSet,
+						Body: &schema.BodySchema{
+							Attributes: map[string]*schema.AttributeSchema{
+								"device_name": {
+									ValueType:  cty.String,
+									IsRequired: true,
+								},
+							},
+						},
+					},
+					"setting": {
+						Type: schema.BlockTypeMap,
+						Labels: []*schema.LabelSchema{
+							{Name: "name"},
+						},
+						Body: &schema.BodySchema{},
+					},
+					"timeouts": {
+						Type: schema.BlockTypeObject,
+						Body: &schema.BodySchema{
+							Attributes: map[string]*schema.AttributeSchema{
+								"create": {
+									ValueType:  cty.String,
+									IsOptional: true,
+								},
+							},
+						},
+					},
+				},
+			},
+			labelSchemaKey("null_resource"): {
+				Detail:      "hashicorp/null",
+				Description: lang.Markdown("The `null_resource` resource implements the standard resource lifecycle."),
+				Attributes: map[string]*schema.AttributeSchema{
+					"triggers": {
+						ValueType:  cty.Map(cty.String),
+						IsOptional: true,
+					},
+				},
+			},
+		},
+	}
+
+	bs := ResourceBlockSchema(ps)
+	if diff := cmp.Diff(expectedSchema, bs, cmpOpts); diff != "" {
+		t.Fatalf("unexpected schema: %s", diff)
+	}
+}
+
+func TestDataBlockSchema(t *testing.T) {
+	ps := loadTestSchemas(t)
+
+	expectedSchema := &schema.BlockSchema{
+		Type: schema.BlockTypeObject,
+		Labels: []*schema.LabelSchema{
+			{Name: "type", IsDepKey: true},
+			{Name: "name"},
+		},
+		Address: &schema.BlockAddrSchema{
+			Steps: []schema.AddrStep{
+				schema.StaticStep{Name: "data"},
+				schema.LabelStep{Index: 0},
+				schema.LabelStep{Index: 1},
+			},
+		},
+		DependentBody: map[schema.SchemaKey]*schema.BodySchema{
+			labelSchemaKey("aws_ami"): {
+				Detail: "hashicorp/aws",
+				Attributes: map[string]*schema.AttributeSchema{
+					"owners": {
+						ValueType:    cty.List(cty.String),
+						Description:  lang.PlainText("List of AMI owners"),
+						IsOptional:   true,
+						IsDeprecated: true,
+					},
+				},
+			},
+		},
+	}
+
+	bs := DataBlockSchema(ps)
+	if diff := cmp.Diff(expectedSchema, bs, cmpOpts); diff != "" {
+		t.Fatalf("unexpected schema: %s", diff)
+	}
+}
+
+func TestResourceBlockSchema_conflictingTypes(t *testing.T) {
+	ps := &tfjson.ProviderSchemas{
+		Schemas: map[string]*tfjson.ProviderSchema{
+			"registry.terraform.io/hashicorp/aws": {
+				ResourceSchemas: map[string]*tfjson.Schema{
+					"aws_instance": {Block: &tfjson.SchemaBlock{Description: "official"}},
+				},
+			},
+			"example.com/community/aws": {
+				ResourceSchemas: map[string]*tfjson.Schema{
+					"aws_instance": {Block: &tfjson.SchemaBlock{Description: "community"}},
+				},
+			},
+		},
+	}
+
+	bs := ResourceBlockSchema(ps)
+	body, ok := bs.DependentBodySchema(schema.DependencyKeys{
+		Labels: []schema.LabelDependent{
+			{Index: 0, Value: "aws_instance"},
+		},
+	})
+	if !ok {
+		t.Fatal("expected schema for aws_instance")
+	}
+
+	// providers are processed in order of their addresses
+	if body.Detail != "example.com/community/aws" {
+		t.Fatalf("unexpected provider: %q", body.Detail)
+	}
+}
+
+func loadTestSchemas(t *testing.T) *tfjson.ProviderSchemas {
+	ps, err := LoadFile(filepath.Join("testdata", "schemas.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ps
+}
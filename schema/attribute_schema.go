@@ -21,6 +21,33 @@ type AttributeSchema struct {
 	// IsDepKey describes whether to use this attribute (and its value)
 	// as key when looking up dependent schema
 	IsDepKey bool
+
+	// MapKeys describes well-known keys of a map attribute
+	// which are used for completion and checked for presence
+	// if marked as required
+	MapKeys map[string]*MapKeySchema
+
+	// IsMapKeysExhaustive describes whether MapKeys represent
+	// all keys which may be declared in the map
+	IsMapKeysExhaustive bool
+
+	// AllowedValues describes literal values (if any)
+	// which a string attribute is expected to be one of
+	AllowedValues []AllowedValue
+}
+
+// MapKeySchema describes a well-known key of a map attribute
+type MapKeySchema struct {
+	Description  lang.MarkupContent
+	IsRequired   bool
+	IsDeprecated bool
+}
+
+// AllowedValue describes one of enumerated literal values of an attribute
+type AllowedValue struct {
+	Value        string
+	Description  lang.MarkupContent
+	IsDeprecated bool
 }
 
 type ValueTypes []cty.Type
@@ -57,5 +84,51 @@ func (as *AttributeSchema) Validate() error {
 		return errors.New("one of IsRequired, IsOptional, or IsComputed must be set")
 	}
 
+	if len(as.MapKeys) > 0 && !as.isMapType() {
+		return errors.New("MapKeys can only be set for map type")
+	}
+
+	if as.IsMapKeysExhaustive && len(as.MapKeys) == 0 {
+		return errors.New("IsMapKeysExhaustive requires MapKeys to be set")
+	}
+
+	if len(as.AllowedValues) > 0 && !as.isStringType() {
+		return errors.New("AllowedValues can only be set for string type")
+	}
+
 	return nil
 }
+
+// AllowedValue returns AllowedValue matching the given value (if any)
+func (as *AttributeSchema) AllowedValue(value string) (AllowedValue, bool) {
+	for _, av := range as.AllowedValues {
+		if av.Value == value {
+			return av, true
+		}
+	}
+	return AllowedValue{}, false
+}
+
+func (as *AttributeSchema) isStringType() bool {
+	if as.ValueType == cty.String {
+		return true
+	}
+	for _, vt := range as.ValueTypes {
+		if vt == cty.String {
+			return true
+		}
+	}
+	return false
+}
+
+func (as *AttributeSchema) isMapType() bool {
+	if as.ValueType.IsMapType() {
+		return true
+	}
+	for _, vt := range as.ValueTypes {
+		if vt.IsMapType() {
+			return true
+		}
+	}
+	return false
+}
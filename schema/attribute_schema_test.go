@@ -62,6 +62,55 @@ func TestAttributeSchema_Validate(t *testing.T) {
 			},
 			nil,
 		},
+		{
+			&AttributeSchema{
+				ValueType:  cty.String,
+				IsOptional: true,
+				MapKeys: map[string]*MapKeySchema{
+					"Owner": {IsRequired: true},
+				},
+			},
+			errors.New("MapKeys can only be set for map type"),
+		},
+		{
+			&AttributeSchema{
+				ValueType:           cty.Map(cty.String),
+				IsOptional:          true,
+				IsMapKeysExhaustive: true,
+			},
+			errors.New("IsMapKeysExhaustive requires MapKeys to be set"),
+		},
+		{
+			&AttributeSchema{
+				ValueType:  cty.Map(cty.String),
+				IsOptional: true,
+				MapKeys: map[string]*MapKeySchema{
+					"Owner": {IsRequired: true},
+				},
+			},
+			nil,
+		},
+		{
+			&AttributeSchema{
+				ValueType:  cty.Number,
+				IsOptional: true,
+				AllowedValues: []AllowedValue{
+					{Value: "one"},
+				},
+			},
+			errors.New("AllowedValues can only be set for string type"),
+		},
+		{
+			&AttributeSchema{
+				ValueTypes: []cty.Type{cty.Bool, cty.String},
+				IsOptional: true,
+				AllowedValues: []AllowedValue{
+					{Value: "one"},
+					{Value: "two", IsDeprecated: true},
+				},
+			},
+			nil,
+		},
 	}
 
 	for i, tc := range testCases {
@@ -0,0 +1,63 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+)
+
+// BlockAddrSchema describes how blocks are referenced in expressions,
+// e.g. resource "aws_instance" "web" as aws_instance.web
+// or data "aws_ami" "ubuntu" as data.aws_ami.ubuntu
+type BlockAddrSchema struct {
+	Steps []AddrStep
+}
+
+type addrStepSigil struct{}
+
+// AddrStep represents a step of the block address
+type AddrStep interface {
+	isAddrStepImpl() addrStepSigil
+}
+
+// StaticStep represents a fixed step of the address, e.g. "data"
+type StaticStep struct {
+	Name string
+}
+
+func (StaticStep) isAddrStepImpl() addrStepSigil {
+	return addrStepSigil{}
+}
+
+// LabelStep represents a step of the address whose name
+// is the value of the label at the given index
+type LabelStep struct {
+	Index int
+}
+
+func (LabelStep) isAddrStepImpl() addrStepSigil {
+	return addrStepSigil{}
+}
+
+// Validate checks the address has steps and that
+// labels referenced by steps are declared by the block
+func (as *BlockAddrSchema) Validate(labels []*LabelSchema) error {
+	if len(as.Steps) == 0 {
+		return errors.New("at least one step is required")
+	}
+	for i, step := range as.Steps {
+		switch s := step.(type) {
+		case StaticStep:
+			if s.Name == "" {
+				return fmt.Errorf("step %d: name must be set", i)
+			}
+		case LabelStep:
+			if s.Index < 0 || s.Index >= len(labels) {
+				return fmt.Errorf("step %d: label index %d out of range (%d labels declared)",
+					i, s.Index, len(labels))
+			}
+		default:
+			return fmt.Errorf("step %d: unknown step type %T", i, step)
+		}
+	}
+	return nil
+}
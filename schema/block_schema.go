@@ -1,6 +1,12 @@
 package schema
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/go-multierror"
 	"github.com/hashicorp/hcl-lang/lang"
 )
 
@@ -16,8 +22,118 @@ type BlockSchema struct {
 	IsDeprecated bool
 	MinItems     uint64
 	MaxItems     uint64
+
+	// Address describes how the block is referenced in expressions,
+	// which makes declared blocks available for completion
+	// of traversals (e.g. aws_instance.web)
+	Address *BlockAddrSchema
 }
 
 func (*BlockSchema) isSchemaImpl() schemaImplSigil {
 	return schemaImplSigil{}
 }
+
+// Validate checks the block schema including its labels,
+// body and all dependent bodies
+func (bs *BlockSchema) Validate() error {
+	var result *multierror.Error
+
+	if bs.MaxItems > 0 && bs.MinItems > bs.MaxItems {
+		result = multierror.Append(result, fmt.Errorf(
+			"MinItems (%d) cannot be greater than MaxItems (%d)", bs.MinItems, bs.MaxItems))
+	}
+
+	if bs.Type == BlockTypeMap && len(bs.Labels) == 0 {
+		result = multierror.Append(result, errors.New("BlockTypeMap requires at least one label"))
+	}
+
+	labelNames := make(map[string]bool, len(bs.Labels))
+	for i, label := range bs.Labels {
+		if label == nil {
+			result = multierror.Append(result, fmt.Errorf("label %d: must not be nil", i))
+			continue
+		}
+		err := label.Validate()
+		if err != nil {
+			result = multierror.Append(result, fmt.Errorf("label %d: %s", i, err))
+		}
+		if label.Name == "" {
+			continue
+		}
+		if labelNames[label.Name] {
+			result = multierror.Append(result, fmt.Errorf("label %d: duplicate label name %q", i, label.Name))
+		}
+		labelNames[label.Name] = true
+	}
+
+	if bs.Address != nil {
+		err := bs.Address.Validate(bs.Labels)
+		if err != nil {
+			result = multierror.Append(result, fmt.Errorf("address: %s", err))
+		}
+	}
+
+	if bs.Body != nil {
+		err := bs.Body.Validate()
+		if err != nil {
+			result = appendPrefixedErrors(result, "", err)
+		}
+	}
+
+	keys := make([]string, 0, len(bs.DependentBody))
+	for key := range bs.DependentBody {
+		keys = append(keys, string(key))
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		path := fmt.Sprintf("[%s]", schemaKeyString(SchemaKey(key)))
+
+		err := bs.validateSchemaKey(SchemaKey(key))
+		if err != nil {
+			result = appendPrefixedErrors(result, path, err)
+		}
+
+		body := bs.DependentBody[SchemaKey(key)]
+		if body == nil {
+			continue
+		}
+		err = body.Validate()
+		if err != nil {
+			result = appendPrefixedErrors(result, path, err)
+		}
+	}
+
+	return result.ErrorOrNil()
+}
+
+// validateSchemaKey checks that the key can be decoded and that
+// any labels it depends on exist and are marked as IsDepKey
+func (bs *BlockSchema) validateSchemaKey(key SchemaKey) error {
+	var dks DependencyKeys
+	err := json.Unmarshal([]byte(key), &dks)
+	if err != nil {
+		return fmt.Errorf("invalid SchemaKey: %s", err)
+	}
+
+	var result *multierror.Error
+	for _, ld := range dks.Labels {
+		if ld.Index < 0 || ld.Index >= len(bs.Labels) {
+			result = multierror.Append(result, fmt.Errorf(
+				"label index %d out of range (%d labels declared)", ld.Index, len(bs.Labels)))
+			continue
+		}
+		label := bs.Labels[ld.Index]
+		if label != nil && !label.IsDepKey {
+			result = multierror.Append(result, fmt.Errorf(
+				"label %d (%q) must be marked as IsDepKey", ld.Index, label.Name))
+		}
+	}
+	for _, ad := range dks.Attributes {
+		if ad.Name == "" {
+			result = multierror.Append(result, errors.New("attribute dependency name must be set"))
+		}
+	}
+
+	return result.ErrorOrNil()
+}
@@ -2,6 +2,8 @@ package schema
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/hashicorp/go-multierror"
 	"github.com/hashicorp/hcl-lang/lang"
@@ -45,28 +47,56 @@ func (bs *BodySchema) Validate() error {
 	}
 
 	var result *multierror.Error
-	for name, attr := range bs.Attributes {
-		err := attr.Validate()
+	if bs.AnyAttribute != nil {
+		err := bs.AnyAttribute.Validate()
 		if err != nil {
-			result = multierror.Append(result, fmt.Errorf("%s: %s", name, err))
+			result = multierror.Append(result, fmt.Errorf("AnyAttribute: %s", err))
 		}
 	}
 
-	for bType, block := range bs.Blocks {
-		if block.Body == nil {
-			continue
+	names := attributeNames(bs)
+	sort.Strings(names)
+	for _, name := range names {
+		err := bs.Attributes[name].Validate()
+		if err != nil {
+			result = multierror.Append(result, fmt.Errorf("%s: %s", name, err))
 		}
-		err := block.Body.Validate()
+	}
+
+	bTypes := blockTypes(bs)
+	sort.Strings(bTypes)
+	for _, bType := range bTypes {
+		err := bs.Blocks[bType].Validate()
 		if err != nil {
-			if me, ok := err.(*multierror.Error); ok {
-				for _, err := range me.Errors {
-					result = multierror.Append(result, fmt.Errorf("%s: %s", bType, err))
-				}
-			} else {
-				result = multierror.Append(result, fmt.Errorf("%s: %s", bType, err))
-			}
+			result = appendPrefixedErrors(result, bType, err)
 		}
 	}
 
 	return result.ErrorOrNil()
 }
+
+// appendPrefixedErrors appends err (or all errors it wraps)
+// to result, prefixing each with the given path
+func appendPrefixedErrors(result *multierror.Error, prefix string, err error) *multierror.Error {
+	if me, ok := err.(*multierror.Error); ok {
+		for _, err := range me.Errors {
+			result = multierror.Append(result, prefixError(prefix, err))
+		}
+		return result
+	}
+	return multierror.Append(result, prefixError(prefix, err))
+}
+
+// prefixError qualifies the error with the given path, such that
+// dependent body keys in square brackets follow the block type
+// directly, e.g. resource["aws_instance"]: ami: ...
+func prefixError(prefix string, err error) error {
+	msg := err.Error()
+	if prefix == "" {
+		return err
+	}
+	if strings.HasPrefix(msg, "[") {
+		return fmt.Errorf("%s%s", prefix, msg)
+	}
+	return fmt.Errorf("%s: %s", prefix, msg)
+}
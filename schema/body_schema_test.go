@@ -0,0 +1,184 @@
+package schema
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/go-multierror"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestBodySchema_Validate(t *testing.T) {
+	awsKey := NewSchemaKey(DependencyKeys{
+		Labels: []LabelDependent{
+			{Index: 0, Value: "aws_instance"},
+		},
+	})
+
+	testCases := []struct {
+		name           string
+		schema         *BodySchema
+		expectedErrors []string
+	}{
+		{
+			"valid",
+			&BodySchema{
+				Attributes: map[string]*AttributeSchema{
+					"name": {ValueType: cty.String, IsRequired: true},
+				},
+				Blocks: map[string]*BlockSchema{
+					"resource": {
+						Type: BlockTypeList,
+						Labels: []*LabelSchema{
+							{Name: "type", IsDepKey: true},
+							{Name: "name"},
+						},
+						DependentBody: map[SchemaKey]*BodySchema{
+							awsKey: {
+								Attributes: map[string]*AttributeSchema{
+									"ami": {ValueType: cty.String, IsOptional: true},
+								},
+							},
+							NewSchemaKey(DependencyKeys{}): {},
+						},
+					},
+				},
+			},
+			nil,
+		},
+		{
+			"any attribute",
+			&BodySchema{
+				AnyAttribute: &AttributeSchema{ValueType: cty.String},
+			},
+			[]string{
+				"AnyAttribute: one of IsRequired, IsOptional, or IsComputed must be set",
+			},
+		},
+		{
+			"block fields",
+			&BodySchema{
+				Blocks: map[string]*BlockSchema{
+					"dynamic": {
+						Type:     BlockTypeMap,
+						MinItems: 2,
+						MaxItems: 1,
+					},
+					"resource": {
+						Labels: []*LabelSchema{
+							{Name: "name"},
+							{Name: "name"},
+							{},
+						},
+					},
+				},
+			},
+			[]string{
+				"dynamic: MinItems (2) cannot be greater than MaxItems (1)",
+				"dynamic: BlockTypeMap requires at least one label",
+				"resource: label 1: duplicate label name \"name\"",
+				"resource: label 2: Name must be set",
+			},
+		},
+		{
+			"block address",
+			&BodySchema{
+				Blocks: map[string]*BlockSchema{
+					"data": {
+						Labels: []*LabelSchema{
+							{Name: "type"},
+						},
+						Address: &BlockAddrSchema{
+							Steps: []AddrStep{
+								StaticStep{},
+								LabelStep{Index: 1},
+							},
+						},
+					},
+					"resource": {
+						Address: &BlockAddrSchema{},
+					},
+				},
+			},
+			[]string{
+				"data: address: step 0: name must be set",
+				"resource: address: at least one step is required",
+			},
+		},
+		{
+			"nested body",
+			&BodySchema{
+				Blocks: map[string]*BlockSchema{
+					"resource": {
+						Body: &BodySchema{
+							Blocks: map[string]*BlockSchema{
+								"lifecycle": {
+									Body: &BodySchema{
+										Attributes: map[string]*AttributeSchema{
+											"create_before_destroy": {ValueType: cty.Bool},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			[]string{
+				"resource: lifecycle: create_before_destroy: one of IsRequired, IsOptional, or IsComputed must be set",
+			},
+		},
+		{
+			"dependent bodies",
+			&BodySchema{
+				Blocks: map[string]*BlockSchema{
+					"resource": {
+						Labels: []*LabelSchema{
+							{Name: "type"},
+							{Name: "name"},
+						},
+						DependentBody: map[SchemaKey]*BodySchema{
+							awsKey: {
+								Attributes: map[string]*AttributeSchema{
+									"ami": {ValueType: cty.String},
+								},
+							},
+							NewSchemaKey(DependencyKeys{
+								Labels: []LabelDependent{
+									{Index: 2, Value: "foo"},
+								},
+							}): {},
+							SchemaKey(`{"labels":`): {},
+						},
+					},
+				},
+			},
+			[]string{
+				"resource[{\"labels\":]: invalid SchemaKey: unexpected end of JSON input",
+				"resource[\"aws_instance\"]: label 0 (\"type\") must be marked as IsDepKey",
+				"resource[\"aws_instance\"]: ami: one of IsRequired, IsOptional, or IsComputed must be set",
+				"resource[\"foo\"]: label index 2 out of range (2 labels declared)",
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			err := tc.schema.Validate()
+			var errs []string
+			if err != nil {
+				me, ok := err.(*multierror.Error)
+				if !ok {
+					t.Fatalf("expected multierror, given: %#v", err)
+				}
+				for _, err := range me.Errors {
+					errs = append(errs, err.Error())
+				}
+			}
+			if diff := cmp.Diff(tc.expectedErrors, errs); diff != "" {
+				t.Fatalf("unexpected errors: %s", diff)
+			}
+		})
+	}
+}
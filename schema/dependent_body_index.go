@@ -0,0 +1,268 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+// DependentBodyIndex represents DependentBody of a BlockSchema
+// decoded and indexed upfront, such that lookups do not require
+// (un)marshaling of SchemaKeys.
+//
+// The index reflects DependentBody at the time of creation
+// and needs to be recreated when DependentBody changes.
+type DependentBodyIndex struct {
+	bodies  map[string]*BodySchema
+	entries []indexEntry
+	labels  map[int]*labelTrie
+}
+
+type indexEntry struct {
+	key  string
+	keys DependencyKeys
+	body *BodySchema
+}
+
+// LabelValue represents a label value on which a dependent body depends
+type LabelValue struct {
+	Value string
+	Keys  DependencyKeys
+	Body  *BodySchema
+}
+
+// NewDependentBodyIndex creates a new index of DependentBody
+// of the given BlockSchema. Undecodable keys are skipped.
+func NewDependentBodyIndex(bs *BlockSchema) *DependentBodyIndex {
+	idx := &DependentBodyIndex{
+		bodies: make(map[string]*BodySchema, len(bs.DependentBody)),
+		labels: make(map[int]*labelTrie, 0),
+	}
+
+	for schemaKey, body := range bs.DependentBody {
+		var dks DependencyKeys
+		err := json.Unmarshal([]byte(schemaKey), &dks)
+		if err != nil {
+			continue
+		}
+
+		key := indexKey(dks)
+		idx.bodies[key] = body
+		idx.entries = append(idx.entries, indexEntry{
+			key:  key,
+			keys: dks,
+			body: body,
+		})
+
+		for _, ld := range dks.Labels {
+			trie, ok := idx.labels[ld.Index]
+			if !ok {
+				trie = newLabelTrie()
+				idx.labels[ld.Index] = trie
+			}
+			trie.insert(LabelValue{
+				Value: ld.Value,
+				Keys:  dks,
+				Body:  body,
+			})
+		}
+	}
+
+	sort.Slice(idx.entries, func(i, j int) bool {
+		return idx.entries[i].key < idx.entries[j].key
+	})
+	for _, trie := range idx.labels {
+		trie.sort()
+	}
+
+	return idx
+}
+
+// BodySchema finds relevant BodySchema based on given DependencyKeys,
+// following the same rules as BlockSchema.DependentBodySchema.
+func (idx *DependentBodyIndex) BodySchema(dks DependencyKeys) (*BodySchema, bool) {
+	if len(idx.bodies) == 0 {
+		return nil, false
+	}
+
+	return findDependentBody(dks, func(subset DependencyKeys) (*BodySchema, bool) {
+		body, ok := idx.bodies[indexKey(subset)]
+		return body, ok
+	})
+}
+
+// ForEach calls the callback for each decoded key and its body
+// in a deterministic order. Iteration stops when callback returns false.
+func (idx *DependentBodyIndex) ForEach(cb func(DependencyKeys, *BodySchema) bool) {
+	for _, e := range idx.entries {
+		if !cb(e.keys, e.body) {
+			return
+		}
+	}
+}
+
+// LabelValues calls the callback for each value of label at the given
+// index which has the given prefix, in order sorted by value.
+// Iteration stops when callback returns false.
+func (idx *DependentBodyIndex) LabelValues(index int, prefix string, cb func(LabelValue) bool) {
+	trie, ok := idx.labels[index]
+	if !ok {
+		return
+	}
+	trie.walkPrefix(prefix, cb)
+}
+
+// indexKey returns a canonical (order-independent)
+// string representation of DependencyKeys
+func indexKey(dks DependencyKeys) string {
+	labels := dks.Labels
+	if !sort.SliceIsSorted(labels, func(i, j int) bool {
+		return labels[i].Index < labels[j].Index
+	}) {
+		labels = make([]LabelDependent, len(dks.Labels))
+		copy(labels, dks.Labels)
+		sort.Slice(labels, func(i, j int) bool {
+			return labels[i].Index < labels[j].Index
+		})
+	}
+
+	attrs := dks.Attributes
+	if !sort.SliceIsSorted(attrs, func(i, j int) bool {
+		return attrs[i].Name < attrs[j].Name
+	}) {
+		attrs = make([]AttributeDependent, len(dks.Attributes))
+		copy(attrs, dks.Attributes)
+		sort.Slice(attrs, func(i, j int) bool {
+			return attrs[i].Name < attrs[j].Name
+		})
+	}
+
+	var sb strings.Builder
+	for _, ld := range labels {
+		sb.WriteString("l")
+		sb.WriteString(strconv.Itoa(ld.Index))
+		sb.WriteString("=")
+		sb.WriteString(strconv.Quote(ld.Value))
+		sb.WriteString(";")
+	}
+	for _, ad := range attrs {
+		sb.WriteString("a")
+		sb.WriteString(strconv.Quote(ad.Name))
+		sb.WriteString("=")
+		sb.WriteString(expressionValueIndexKey(ad.Expr))
+		sb.WriteString(";")
+	}
+	return sb.String()
+}
+
+func expressionValueIndexKey(ev ExpressionValue) string {
+	if len(ev.Reference) > 0 {
+		b, _ := ev.Reference.Marshal()
+		return "ref:" + string(b)
+	}
+
+	val := ev.Static
+	if val.Type() == cty.NilType || val.IsNull() || !val.IsWhollyKnown() {
+		return "nil"
+	}
+
+	switch val.Type() {
+	case cty.String:
+		return fmt.Sprintf("str:%q", val.AsString())
+	case cty.Number:
+		return "num:" + val.AsBigFloat().Text('g', -1)
+	case cty.Bool:
+		return fmt.Sprintf("bool:%t", val.True())
+	}
+
+	b, err := json.Marshal(ExpressionValue{Static: val})
+	if err != nil {
+		return "invalid"
+	}
+	return string(b)
+}
+
+// labelTrie is a prefix tree of label values
+// allowing efficient lookup of values by prefix
+type labelTrie struct {
+	root *labelTrieNode
+}
+
+type labelTrieNode struct {
+	edge     byte
+	children []*labelTrieNode
+	values   []LabelValue
+}
+
+func newLabelTrie() *labelTrie {
+	return &labelTrie{root: &labelTrieNode{}}
+}
+
+func (t *labelTrie) insert(lv LabelValue) {
+	node := t.root
+	for i := 0; i < len(lv.Value); i++ {
+		node = node.child(lv.Value[i], true)
+	}
+	node.values = append(node.values, lv)
+}
+
+// sort orders children and values of all nodes
+// such that walking the trie yields sorted values
+func (t *labelTrie) sort() {
+	var sortNode func(*labelTrieNode)
+	sortNode = func(n *labelTrieNode) {
+		sort.Slice(n.children, func(i, j int) bool {
+			return n.children[i].edge < n.children[j].edge
+		})
+		sort.SliceStable(n.values, func(i, j int) bool {
+			return indexKey(n.values[i].Keys) < indexKey(n.values[j].Keys)
+		})
+		for _, child := range n.children {
+			sortNode(child)
+		}
+	}
+	sortNode(t.root)
+}
+
+func (t *labelTrie) walkPrefix(prefix string, cb func(LabelValue) bool) {
+	node := t.root
+	for i := 0; i < len(prefix); i++ {
+		node = node.child(prefix[i], false)
+		if node == nil {
+			return
+		}
+	}
+	node.walk(cb)
+}
+
+func (n *labelTrieNode) child(edge byte, create bool) *labelTrieNode {
+	for _, c := range n.children {
+		if c.edge == edge {
+			return c
+		}
+	}
+	if !create {
+		return nil
+	}
+	c := &labelTrieNode{edge: edge}
+	n.children = append(n.children, c)
+	return c
+}
+
+func (n *labelTrieNode) walk(cb func(LabelValue) bool) bool {
+	for _, lv := range n.values {
+		if !cb(lv) {
+			return false
+		}
+	}
+	for _, c := range n.children {
+		if !c.walk(cb) {
+			return false
+		}
+	}
+	return true
+}
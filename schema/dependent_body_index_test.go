@@ -0,0 +1,263 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/zclconf/go-cty-debug/ctydebug"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestDependentBodyIndex_BodySchema(t *testing.T) {
+	testCases := []struct {
+		name        string
+		blockSchema *BlockSchema
+		dks         DependencyKeys
+	}{
+		{
+			"single label",
+			testSchemaWithLabels,
+			DependencyKeys{
+				Labels: []LabelDependent{
+					{Index: 0, Value: "theircloud"},
+				},
+			},
+		},
+		{
+			"unsorted labels",
+			testSchemaWithLabels,
+			DependencyKeys{
+				Labels: []LabelDependent{
+					{Index: 1, Value: "apple"},
+					{Index: 0, Value: "crazycloud"},
+				},
+			},
+		},
+		{
+			"static attributes",
+			testSchemaWithAttributes,
+			DependencyKeys{
+				Attributes: []AttributeDependent{
+					{
+						Name: "depnum",
+						Expr: ExpressionValue{
+							Static: cty.NumberIntVal(55),
+						},
+					},
+					{
+						Name: "depattr",
+						Expr: ExpressionValue{
+							Static: cty.StringVal("pumpkin"),
+						},
+					},
+				},
+			},
+		},
+		{
+			"reference attribute",
+			testSchemaWithAttributes,
+			DependencyKeys{
+				Attributes: []AttributeDependent{
+					{
+						Name: "depref",
+						Expr: ExpressionValue{
+							Reference: lang.Reference{
+								lang.RootStep{Name: "myroot"},
+								lang.AttrStep{Name: "attrstep"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			expectedSchema, ok := tc.blockSchema.DependentBodySchema(tc.dks)
+			if !ok {
+				t.Fatal("expected to find body schema")
+			}
+
+			idx := NewDependentBodyIndex(tc.blockSchema)
+			bodySchema, ok := idx.BodySchema(tc.dks)
+			if !ok {
+				t.Fatal("expected to find indexed body schema")
+			}
+			if diff := cmp.Diff(expectedSchema, bodySchema, ctydebug.CmpOptions); diff != "" {
+				t.Fatalf("unexpected body schema: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDependentBodyIndex_LabelValues(t *testing.T) {
+	idx := NewDependentBodyIndex(testSchemaWithLabels)
+
+	testCases := []struct {
+		index          int
+		prefix         string
+		expectedValues []string
+	}{
+		{0, "", []string{"complexcloud", "crazycloud", "mycloud", "theircloud"}},
+		{0, "c", []string{"complexcloud", "crazycloud"}},
+		{0, "cr", []string{"crazycloud"}},
+		{0, "unknown", []string{}},
+		{1, "", []string{"apple", "pumpkin", "theircloud"}},
+		{2, "", []string{}},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%d-%s", i, tc.index, tc.prefix), func(t *testing.T) {
+			values := make([]string, 0)
+			idx.LabelValues(tc.index, tc.prefix, func(lv LabelValue) bool {
+				values = append(values, lv.Value)
+				return true
+			})
+			if diff := cmp.Diff(tc.expectedValues, values); diff != "" {
+				t.Fatalf("unexpected values: %s", diff)
+			}
+		})
+	}
+}
+
+func BenchmarkBlockSchema_DependentBodySchema(b *testing.B) {
+	bs := benchmarkBlockSchema(5000)
+	dks := benchmarkDependencyKeys(4321)
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		_, ok := bs.DependentBodySchema(dks)
+		if !ok {
+			b.Fatal("expected to find body schema")
+		}
+	}
+}
+
+func BenchmarkDependentBodyIndex_BodySchema(b *testing.B) {
+	bs := benchmarkBlockSchema(5000)
+	dks := benchmarkDependencyKeys(4321)
+	idx := NewDependentBodyIndex(bs)
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		_, ok := idx.BodySchema(dks)
+		if !ok {
+			b.Fatal("expected to find body schema")
+		}
+	}
+}
+
+func BenchmarkBlockSchema_DependentBodySchema_fallback(b *testing.B) {
+	bs := benchmarkBlockSchema(5000)
+	dks := benchmarkDependencyKeysWithProvider(4321)
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		_, ok := bs.DependentBodySchema(dks)
+		if !ok {
+			b.Fatal("expected to find body schema")
+		}
+	}
+}
+
+func BenchmarkDependentBodyIndex_BodySchema_fallback(b *testing.B) {
+	bs := benchmarkBlockSchema(5000)
+	dks := benchmarkDependencyKeysWithProvider(4321)
+	idx := NewDependentBodyIndex(bs)
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		_, ok := idx.BodySchema(dks)
+		if !ok {
+			b.Fatal("expected to find body schema")
+		}
+	}
+}
+
+func BenchmarkBlockSchema_labelValuesByPrefix(b *testing.B) {
+	bs := benchmarkBlockSchema(5000)
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		count := 0
+		for schemaKey := range bs.DependentBody {
+			var dks DependencyKeys
+			err := json.Unmarshal([]byte(schemaKey), &dks)
+			if err != nil {
+				b.Fatal(err)
+			}
+			for _, ld := range dks.Labels {
+				if ld.Index == 0 && strings.HasPrefix(ld.Value, "aws_type_43") {
+					count++
+				}
+			}
+		}
+		if count != 111 {
+			b.Fatalf("unexpected number of values: %d", count)
+		}
+	}
+}
+
+func BenchmarkDependentBodyIndex_LabelValues(b *testing.B) {
+	bs := benchmarkBlockSchema(5000)
+	idx := NewDependentBodyIndex(bs)
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		count := 0
+		idx.LabelValues(0, "aws_type_43", func(LabelValue) bool {
+			count++
+			return true
+		})
+		if count != 111 {
+			b.Fatalf("unexpected number of values: %d", count)
+		}
+	}
+}
+
+func benchmarkBlockSchema(size int) *BlockSchema {
+	bs := &BlockSchema{
+		Labels: []*LabelSchema{
+			{Name: "type", IsDepKey: true},
+			{Name: "name"},
+		},
+		DependentBody: make(map[SchemaKey]*BodySchema, size),
+	}
+	for i := 0; i < size; i++ {
+		bs.DependentBody[NewSchemaKey(benchmarkDependencyKeys(i))] = &BodySchema{
+			Attributes: map[string]*AttributeSchema{
+				"attr": {ValueType: cty.String},
+			},
+		}
+	}
+	return bs
+}
+
+func benchmarkDependencyKeys(i int) DependencyKeys {
+	return DependencyKeys{
+		Labels: []LabelDependent{
+			{Index: 0, Value: fmt.Sprintf("aws_type_%d", i)},
+		},
+	}
+}
+
+func benchmarkDependencyKeysWithProvider(i int) DependencyKeys {
+	dks := benchmarkDependencyKeys(i)
+	dks.Attributes = []AttributeDependent{
+		{
+			Name: "provider",
+			Expr: ExpressionValue{
+				Reference: lang.Reference{
+					lang.RootStep{Name: "aws"},
+					lang.AttrStep{Name: "west"},
+				},
+			},
+		},
+	}
+	return dks
+}
@@ -6,21 +6,91 @@ import (
 	"sort"
 
 	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/zclconf/go-cty/cty"
 	ctyjson "github.com/zclconf/go-cty/cty/json"
 )
 
 // DependentBodySchema finds relevant BodySchema based on given DependencyKeys
 // such as a label or an attribute (or combination of both).
+//
+// The most specific match is preferred, i.e. schema matching all the keys
+// is returned if it exists, otherwise schemas matching subsets of the keys
+// are looked up (larger subsets first, labels take precedence over attributes).
+// Schema stored under empty DependencyKeys is returned as default
+// if no other schema matches.
 func (bs *BlockSchema) DependentBodySchema(dks DependencyKeys) (*BodySchema, bool) {
-	b, err := dks.MarshalJSON()
-	if err != nil {
+	if len(bs.DependentBody) == 0 {
 		return nil, false
 	}
 
-	key := SchemaKey(string(b))
-	schema, ok := bs.DependentBody[key]
-	return schema, ok
+	return findDependentBody(dks, func(subset DependencyKeys) (*BodySchema, bool) {
+		b, err := subset.MarshalJSON()
+		if err != nil {
+			return nil, false
+		}
+
+		schema, ok := bs.DependentBody[SchemaKey(string(b))]
+		return schema, ok
+	})
+}
+
+// findDependentBody looks up the most specific body via the given lookup
+// function, trying all keys first and their subsets afterwards
+func findDependentBody(dks DependencyKeys, lookup func(DependencyKeys) (*BodySchema, bool)) (*BodySchema, bool) {
+	schema, ok := lookup(dks)
+	if ok {
+		return schema, true
+	}
+
+	keys := make([]DependencyKey, 0, len(dks.Labels)+len(dks.Attributes))
+	for _, ld := range dks.Labels {
+		keys = append(keys, ld)
+	}
+	for _, ad := range dks.Attributes {
+		keys = append(keys, ad)
+	}
+
+	for size := len(keys) - 1; size >= 0; size-- {
+		for _, subset := range dependencyKeySubsets(keys, size) {
+			schema, ok := lookup(subset)
+			if ok {
+				return schema, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// dependencyKeySubsets returns all combinations of the given size
+// preserving the order of keys
+func dependencyKeySubsets(keys []DependencyKey, size int) []DependencyKeys {
+	subsets := make([]DependencyKeys, 0)
+
+	var combine func(start int, picked []DependencyKey)
+	combine = func(start int, picked []DependencyKey) {
+		if len(picked) == size {
+			dks := DependencyKeys{}
+			for _, key := range picked {
+				switch k := key.(type) {
+				case LabelDependent:
+					dks.Labels = append(dks.Labels, k)
+				case AttributeDependent:
+					dks.Attributes = append(dks.Attributes, k)
+				}
+			}
+			subsets = append(subsets, dks)
+			return
+		}
+		for i := start; i <= len(keys)-(size-len(picked)); i++ {
+			combine(i+1, append(picked[:len(picked):len(picked)], keys[i]))
+		}
+	}
+	combine(0, []DependencyKey{})
+
+	return subsets
 }
 
 // DependencyKeys represent values of labels or attributes
@@ -121,6 +191,40 @@ func (ev ExpressionValue) MarshalJSON() ([]byte, error) {
 	return json.Marshal(val)
 }
 
+func (ev *ExpressionValue) UnmarshalJSON(b []byte) error {
+	var val struct {
+		Static    json.RawMessage `json:"static,omitempty"`
+		Reference string          `json:"ref,omitempty"`
+	}
+	err := json.Unmarshal(b, &val)
+	if err != nil {
+		return err
+	}
+
+	if len(val.Static) > 0 {
+		var sv ctyjson.SimpleJSONValue
+		err := sv.UnmarshalJSON(val.Static)
+		if err != nil {
+			return err
+		}
+		ev.Static = sv.Value
+	}
+
+	if val.Reference != "" {
+		traversal, diags := hclsyntax.ParseTraversalAbs([]byte(val.Reference), "", hcl.InitialPos)
+		if diags.HasErrors() {
+			return diags
+		}
+		ref, err := lang.TraversalToReference(traversal)
+		if err != nil {
+			return err
+		}
+		ev.Reference = ref
+	}
+
+	return nil
+}
+
 func (ad AttributeDependent) isDependencyKeyImpl() depKeySigil {
 	return depKeySigil{}
 }
@@ -1,6 +1,7 @@
 package schema
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
 
@@ -203,6 +204,154 @@ func TestBodySchema_FindSchemaDependingOn_label_lookupUnsorted(t *testing.T) {
 	}
 }
 
+func TestBodySchema_FindSchemaDependingOn_fallback(t *testing.T) {
+	blockSchema := &BlockSchema{
+		Labels: []*LabelSchema{
+			{Name: "type", IsDepKey: true},
+			{Name: "name"},
+		},
+		Body: &BodySchema{
+			Attributes: map[string]*AttributeSchema{
+				"provider": {ValueType: cty.DynamicPseudoType, IsDepKey: true},
+			},
+		},
+		DependentBody: map[SchemaKey]*BodySchema{
+			NewSchemaKey(DependencyKeys{}): {
+				Detail: "default",
+			},
+			NewSchemaKey(DependencyKeys{
+				Labels: []LabelDependent{
+					{Index: 0, Value: "aws_instance"},
+				},
+			}): {
+				Detail: "label only",
+			},
+			NewSchemaKey(DependencyKeys{
+				Labels: []LabelDependent{
+					{Index: 0, Value: "aws_instance"},
+				},
+				Attributes: []AttributeDependent{
+					{
+						Name: "provider",
+						Expr: ExpressionValue{
+							Reference: lang.Reference{
+								lang.RootStep{Name: "aws"},
+								lang.AttrStep{Name: "east"},
+							},
+						},
+					},
+				},
+			}): {
+				Detail: "label and attribute",
+			},
+		},
+	}
+
+	testCases := []struct {
+		name           string
+		dks            DependencyKeys
+		expectedDetail string
+	}{
+		{
+			"exact match",
+			DependencyKeys{
+				Labels: []LabelDependent{
+					{Index: 0, Value: "aws_instance"},
+				},
+				Attributes: []AttributeDependent{
+					{
+						Name: "provider",
+						Expr: ExpressionValue{
+							Reference: lang.Reference{
+								lang.RootStep{Name: "aws"},
+								lang.AttrStep{Name: "east"},
+							},
+						},
+					},
+				},
+			},
+			"label and attribute",
+		},
+		{
+			"label subset",
+			DependencyKeys{
+				Labels: []LabelDependent{
+					{Index: 0, Value: "aws_instance"},
+				},
+				Attributes: []AttributeDependent{
+					{
+						Name: "provider",
+						Expr: ExpressionValue{
+							Reference: lang.Reference{
+								lang.RootStep{Name: "aws"},
+								lang.AttrStep{Name: "west"},
+							},
+						},
+					},
+				},
+			},
+			"label only",
+		},
+		{
+			"default",
+			DependencyKeys{
+				Labels: []LabelDependent{
+					{Index: 0, Value: "gcp_instance"},
+				},
+			},
+			"default",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			bodySchema, ok := blockSchema.DependentBodySchema(tc.dks)
+			if !ok {
+				t.Fatal("expected to find body schema")
+			}
+			if diff := cmp.Diff(tc.expectedDetail, bodySchema.Detail); diff != "" {
+				t.Fatalf("unexpected body schema: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDependencyKeys_UnmarshalJSON(t *testing.T) {
+	dks := DependencyKeys{
+		Labels: []LabelDependent{
+			{Index: 0, Value: "aws_instance"},
+		},
+		Attributes: []AttributeDependent{
+			{
+				Name: "depnum",
+				Expr: ExpressionValue{
+					Static: cty.NumberIntVal(42),
+				},
+			},
+			{
+				Name: "depref",
+				Expr: ExpressionValue{
+					Reference: lang.Reference{
+						lang.RootStep{Name: "aws"},
+						lang.AttrStep{Name: "west"},
+					},
+				},
+			},
+		},
+	}
+	key := NewSchemaKey(dks)
+
+	var decoded DependencyKeys
+	err := json.Unmarshal([]byte(key), &decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(key, NewSchemaKey(decoded)); diff != "" {
+		t.Fatalf("unexpected dependency keys: %s", diff)
+	}
+}
+
 var testSchemaWithLabels = &BlockSchema{
 	Labels: []*LabelSchema{
 		{
@@ -0,0 +1,557 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// ChangeKind represents kind of a change between two schemas
+type ChangeKind uint
+
+const (
+	NilChange ChangeKind = iota
+	AttributeAdded
+	AttributeRemoved
+	AttributeTypeChanged
+	AttributeRequirednessChanged
+	AttributeDeprecated
+	BlockAdded
+	BlockRemoved
+	BlockTypeChanged
+	BlockLabelsChanged
+	BlockDeprecated
+	DependentBodyAdded
+	DependentBodyRemoved
+	AnyAttributeAdded
+	AnyAttributeRemoved
+	BlockMinItemsChanged
+	BlockMaxItemsChanged
+	BodyDeprecated
+	BlockAddressChanged
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case AttributeAdded:
+		return "attribute added"
+	case AttributeRemoved:
+		return "attribute removed"
+	case AttributeTypeChanged:
+		return "attribute type changed"
+	case AttributeRequirednessChanged:
+		return "attribute requiredness changed"
+	case AttributeDeprecated:
+		return "attribute deprecated"
+	case BlockAdded:
+		return "block added"
+	case BlockRemoved:
+		return "block removed"
+	case BlockTypeChanged:
+		return "block type changed"
+	case BlockLabelsChanged:
+		return "block labels changed"
+	case BlockDeprecated:
+		return "block deprecated"
+	case DependentBodyAdded:
+		return "dependent body added"
+	case DependentBodyRemoved:
+		return "dependent body removed"
+	case AnyAttributeAdded:
+		return "any attribute added"
+	case AnyAttributeRemoved:
+		return "any attribute removed"
+	case BlockMinItemsChanged:
+		return "block min items changed"
+	case BlockMaxItemsChanged:
+		return "block max items changed"
+	case BodyDeprecated:
+		return "body deprecated"
+	case BlockAddressChanged:
+		return "block address changed"
+	}
+	return ""
+}
+
+// Change describes a single difference between two schemas
+type Change struct {
+	Kind ChangeKind
+
+	// Path is a dot-separated path to the changed attribute or block,
+	// where dependent bodies are represented by their keys
+	// in square brackets, e.g. resource["aws_instance"].ami
+	// and AnyAttribute is represented by "*"
+	Path string
+
+	// Old and New represent human-readable values before and after
+	// the change (if applicable), such as type or labels
+	Old string
+	New string
+
+	// IsBreaking describes whether the change may cause
+	// configuration valid under the old schema to become invalid
+	IsBreaking bool
+}
+
+func (c Change) String() string {
+	msg := fmt.Sprintf("%s: %s", c.Path, c.Kind)
+	if c.Old != "" || c.New != "" {
+		msg += fmt.Sprintf(" (%s -> %s)", c.Old, c.New)
+	}
+	if c.IsBreaking {
+		msg += " [breaking]"
+	}
+	return msg
+}
+
+// Changes represents a list of changes between two schemas
+type Changes []Change
+
+// Breaking returns only changes which are breaking
+func (cs Changes) Breaking() Changes {
+	breaking := make(Changes, 0)
+	for _, c := range cs {
+		if c.IsBreaking {
+			breaking = append(breaking, c)
+		}
+	}
+	return breaking
+}
+
+// String renders changes in a human-readable form, one change per line,
+// prefixed with "+" for additions, "-" for removals and "~" for updates
+func (cs Changes) String() string {
+	var sb strings.Builder
+	for _, c := range cs {
+		prefix := "~"
+		switch c.Kind {
+		case AttributeAdded, BlockAdded, DependentBodyAdded, AnyAttributeAdded:
+			prefix = "+"
+		case AttributeRemoved, BlockRemoved, DependentBodyRemoved, AnyAttributeRemoved:
+			prefix = "-"
+		}
+		fmt.Fprintf(&sb, "%s %s\n", prefix, c)
+	}
+	return sb.String()
+}
+
+// Diff compares two schemas and returns changes needed
+// to turn the old schema into the new one
+func Diff(old, updated *BodySchema) Changes {
+	changes := make(Changes, 0)
+	diffBodies(&changes, "", old, updated)
+	return changes
+}
+
+func diffBodies(changes *Changes, path string, old, updated *BodySchema) {
+	if old == nil {
+		old = &BodySchema{}
+	}
+	if updated == nil {
+		updated = &BodySchema{}
+	}
+
+	if !old.IsDeprecated && updated.IsDeprecated {
+		// e.g. dependent body of a deprecated resource type
+		*changes = append(*changes, Change{
+			Kind: BodyDeprecated,
+			Path: path,
+		})
+	}
+
+	for _, name := range unionKeys(attributeNames(old), attributeNames(updated)) {
+		oldAttr, inOld := old.Attributes[name]
+		newAttr, inNew := updated.Attributes[name]
+		attrPath := joinPath(path, name)
+
+		switch {
+		case !inOld:
+			*changes = append(*changes, Change{
+				Kind:       AttributeAdded,
+				Path:       attrPath,
+				IsBreaking: newAttr.IsRequired,
+			})
+		case !inNew:
+			*changes = append(*changes, Change{
+				Kind:       AttributeRemoved,
+				Path:       attrPath,
+				IsBreaking: true,
+			})
+		default:
+			diffAttributes(changes, attrPath, oldAttr, newAttr)
+		}
+	}
+
+	anyPath := joinPath(path, "*")
+	switch {
+	case old.AnyAttribute == nil && updated.AnyAttribute != nil:
+		*changes = append(*changes, Change{
+			Kind: AnyAttributeAdded,
+			Path: anyPath,
+		})
+	case old.AnyAttribute != nil && updated.AnyAttribute == nil:
+		*changes = append(*changes, Change{
+			Kind:       AnyAttributeRemoved,
+			Path:       anyPath,
+			IsBreaking: true,
+		})
+	case old.AnyAttribute != nil && updated.AnyAttribute != nil:
+		diffAttributes(changes, anyPath, old.AnyAttribute, updated.AnyAttribute)
+	}
+
+	for _, bType := range unionKeys(blockTypes(old), blockTypes(updated)) {
+		oldBlock, inOld := old.Blocks[bType]
+		newBlock, inNew := updated.Blocks[bType]
+		blockPath := joinPath(path, bType)
+
+		switch {
+		case !inOld:
+			*changes = append(*changes, Change{
+				Kind:       BlockAdded,
+				Path:       blockPath,
+				IsBreaking: newBlock.MinItems > 0,
+			})
+		case !inNew:
+			*changes = append(*changes, Change{
+				Kind:       BlockRemoved,
+				Path:       blockPath,
+				IsBreaking: true,
+			})
+		default:
+			diffBlocks(changes, blockPath, oldBlock, newBlock)
+		}
+	}
+}
+
+func diffAttributes(changes *Changes, path string, old, updated *AttributeSchema) {
+	oldType, newType := attributeTypeName(old), attributeTypeName(updated)
+	if !attributeTypesEqual(old, updated) {
+		*changes = append(*changes, Change{
+			Kind:       AttributeTypeChanged,
+			Path:       path,
+			Old:        oldType,
+			New:        newType,
+			IsBreaking: true,
+		})
+	}
+
+	oldRequiredness, newRequiredness := requiredness(old), requiredness(updated)
+	if oldRequiredness != newRequiredness {
+		*changes = append(*changes, Change{
+			Kind: AttributeRequirednessChanged,
+			Path: path,
+			Old:  oldRequiredness,
+			New:  newRequiredness,
+			// computed-only attributes cannot be set in configuration
+			IsBreaking: updated.IsRequired || isComputedOnly(updated),
+		})
+	}
+
+	if !old.IsDeprecated && updated.IsDeprecated {
+		*changes = append(*changes, Change{
+			Kind: AttributeDeprecated,
+			Path: path,
+		})
+	}
+}
+
+func diffBlocks(changes *Changes, path string, old, updated *BlockSchema) {
+	if old.Type != updated.Type {
+		*changes = append(*changes, Change{
+			Kind:       BlockTypeChanged,
+			Path:       path,
+			Old:        old.Type.String(),
+			New:        updated.Type.String(),
+			IsBreaking: true,
+		})
+	}
+
+	oldLabels, newLabels := labelsString(old.Labels), labelsString(updated.Labels)
+	if oldLabels != newLabels {
+		*changes = append(*changes, Change{
+			Kind: BlockLabelsChanged,
+			Path: path,
+			Old:  oldLabels,
+			New:  newLabels,
+			// dependent bodies keyed by labels which are no longer
+			// dependency keys are never selected
+			IsBreaking: len(old.Labels) != len(updated.Labels) ||
+				isDepKeyRemoved(old.Labels, updated.Labels),
+		})
+	}
+
+	oldAddr, newAddr := addressString(old), addressString(updated)
+	if oldAddr != newAddr {
+		*changes = append(*changes, Change{
+			Kind: BlockAddressChanged,
+			Path: path,
+			Old:  oldAddr,
+			New:  newAddr,
+			// references to declared blocks may no longer resolve
+			IsBreaking: oldAddr != "",
+		})
+	}
+
+	if old.MinItems != updated.MinItems {
+		*changes = append(*changes, Change{
+			Kind:       BlockMinItemsChanged,
+			Path:       path,
+			Old:        fmt.Sprintf("%d", old.MinItems),
+			New:        fmt.Sprintf("%d", updated.MinItems),
+			IsBreaking: updated.MinItems > old.MinItems,
+		})
+	}
+
+	if old.MaxItems != updated.MaxItems {
+		*changes = append(*changes, Change{
+			Kind: BlockMaxItemsChanged,
+			Path: path,
+			Old:  maxItemsString(old.MaxItems),
+			New:  maxItemsString(updated.MaxItems),
+			// zero represents no limit
+			IsBreaking: updated.MaxItems != 0 &&
+				(old.MaxItems == 0 || updated.MaxItems < old.MaxItems),
+		})
+	}
+
+	if !old.IsDeprecated && updated.IsDeprecated {
+		*changes = append(*changes, Change{
+			Kind: BlockDeprecated,
+			Path: path,
+		})
+	}
+
+	diffBodies(changes, path, old.Body, updated.Body)
+
+	oldBodies := normalizedDependentBodies(old.DependentBody)
+	newBodies := normalizedDependentBodies(updated.DependentBody)
+
+	oldKeys := make([]string, 0, len(oldBodies))
+	for key := range oldBodies {
+		oldKeys = append(oldKeys, key)
+	}
+	newKeys := make([]string, 0, len(newBodies))
+	for key := range newBodies {
+		newKeys = append(newKeys, key)
+	}
+
+	for _, key := range unionKeys(oldKeys, newKeys) {
+		oldDep, inOld := oldBodies[key]
+		newDep, inNew := newBodies[key]
+		oldBody, newBody := oldDep.body, newDep.body
+
+		schemaKey := newDep.key
+		if !inNew {
+			schemaKey = oldDep.key
+		}
+		depPath := fmt.Sprintf("%s[%s]", path, schemaKeyString(schemaKey))
+
+		switch {
+		case !inOld:
+			*changes = append(*changes, Change{
+				Kind: DependentBodyAdded,
+				Path: depPath,
+			})
+		case !inNew:
+			*changes = append(*changes, Change{
+				Kind:       DependentBodyRemoved,
+				Path:       depPath,
+				IsBreaking: true,
+			})
+		default:
+			diffBodies(changes, depPath, oldBody, newBody)
+		}
+	}
+}
+
+type dependentBody struct {
+	key  SchemaKey
+	body *BodySchema
+}
+
+// normalizedDependentBodies returns dependent bodies keyed by
+// canonical representation of their keys, such that equivalent keys
+// (e.g. with dependencies declared in different order) are matched
+func normalizedDependentBodies(bodies map[SchemaKey]*BodySchema) map[string]dependentBody {
+	normalized := make(map[string]dependentBody, len(bodies))
+	for key, body := range bodies {
+		nKey := string(key)
+		var dks DependencyKeys
+		err := json.Unmarshal([]byte(key), &dks)
+		if err == nil {
+			nKey = indexKey(dks)
+		}
+		normalized[nKey] = dependentBody{key, body}
+	}
+	return normalized
+}
+
+func maxItemsString(maxItems uint64) string {
+	if maxItems == 0 {
+		return "unlimited"
+	}
+	return fmt.Sprintf("%d", maxItems)
+}
+
+func attributeTypesEqual(old, updated *AttributeSchema) bool {
+	if len(old.ValueTypes) != len(updated.ValueTypes) {
+		return false
+	}
+	for i, vt := range old.ValueTypes {
+		if !typesEqual(vt, updated.ValueTypes[i]) {
+			return false
+		}
+	}
+	return typesEqual(old.ValueType, updated.ValueType)
+}
+
+func typesEqual(a, b cty.Type) bool {
+	if a == cty.NilType || b == cty.NilType {
+		return a == cty.NilType && b == cty.NilType
+	}
+	return a.Equals(b)
+}
+
+func attributeTypeName(attr *AttributeSchema) string {
+	if len(attr.ValueTypes) > 0 {
+		return strings.Join(attr.ValueTypes.FriendlyNames(), " or ")
+	}
+	if attr.ValueType == cty.NilType {
+		return ""
+	}
+	return attr.ValueType.FriendlyName()
+}
+
+func requiredness(attr *AttributeSchema) string {
+	if attr.IsRequired {
+		return "required"
+	}
+	if isComputedOnly(attr) {
+		return "computed"
+	}
+	return "optional"
+}
+
+func isComputedOnly(attr *AttributeSchema) bool {
+	return attr.IsComputed && !attr.IsOptional && !attr.IsRequired
+}
+
+// labelsString returns names of the labels,
+// with dependency keys marked, e.g. type [dep key], name
+func labelsString(labels []*LabelSchema) string {
+	names := make([]string, len(labels))
+	for i, l := range labels {
+		names[i] = l.Name
+		if l.IsDepKey {
+			names[i] += " [dep key]"
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// addressString returns human-readable representation
+// of the block address, e.g. data.<type>.<name>
+func addressString(block *BlockSchema) string {
+	if block.Address == nil {
+		return ""
+	}
+	steps := make([]string, len(block.Address.Steps))
+	for i, step := range block.Address.Steps {
+		switch s := step.(type) {
+		case StaticStep:
+			steps[i] = s.Name
+		case LabelStep:
+			name := "*"
+			if s.Index >= 0 && s.Index < len(block.Labels) && block.Labels[s.Index].Name != "" {
+				name = block.Labels[s.Index].Name
+			}
+			steps[i] = fmt.Sprintf("<%s>", name)
+		}
+	}
+	return strings.Join(steps, ".")
+}
+
+// isDepKeyRemoved returns true if any of the old labels
+// marked as dependency key is no longer marked as such
+func isDepKeyRemoved(old, updated []*LabelSchema) bool {
+	for i, l := range old {
+		if l.IsDepKey && i < len(updated) && !updated[i].IsDepKey {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaKeyString returns human-readable representation of the key,
+// e.g. "aws_instance", provider=aws.west
+func schemaKeyString(key SchemaKey) string {
+	var dks DependencyKeys
+	err := json.Unmarshal([]byte(key), &dks)
+	if err != nil {
+		return string(key)
+	}
+
+	parts := make([]string, 0, len(dks.Labels)+len(dks.Attributes))
+	for _, ld := range dks.Labels {
+		parts = append(parts, fmt.Sprintf("%q", ld.Value))
+	}
+	for _, ad := range dks.Attributes {
+		parts = append(parts, fmt.Sprintf("%s=%s", ad.Name, expressionValueString(ad.Expr)))
+	}
+	if len(parts) == 0 {
+		return "*"
+	}
+	return strings.Join(parts, ", ")
+}
+
+func expressionValueString(ev ExpressionValue) string {
+	if len(ev.Reference) > 0 {
+		b, err := ev.Reference.Marshal()
+		if err == nil {
+			return string(b)
+		}
+	}
+	if ev.Static.Type() != cty.NilType {
+		b, err := json.Marshal(ctyjson.SimpleJSONValue{Value: ev.Static})
+		if err == nil {
+			return string(b)
+		}
+	}
+	return ""
+}
+
+func attributeNames(bs *BodySchema) []string {
+	names := make([]string, 0, len(bs.Attributes))
+	for name := range bs.Attributes {
+		names = append(names, name)
+	}
+	return names
+}
+
+func blockTypes(bs *BodySchema) []string {
+	bTypes := make([]string, 0, len(bs.Blocks))
+	for bType := range bs.Blocks {
+		bTypes = append(bTypes, bType)
+	}
+	return bTypes
+}
+
+// unionKeys returns sorted union of the given keys
+func unionKeys(a, b []string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	keys := make([]string, 0, len(a)+len(b))
+	for _, keySet := range [][]string{a, b} {
+		for _, key := range keySet {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
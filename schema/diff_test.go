@@ -0,0 +1,334 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestDiff(t *testing.T) {
+	awsKey := NewSchemaKey(DependencyKeys{
+		Labels: []LabelDependent{
+			{Index: 0, Value: "aws_instance"},
+		},
+	})
+	awsWestKey := NewSchemaKey(DependencyKeys{
+		Labels: []LabelDependent{
+			{Index: 0, Value: "aws_instance"},
+		},
+		Attributes: []AttributeDependent{
+			{
+				Name: "provider",
+				Expr: ExpressionValue{
+					Reference: lang.Reference{
+						lang.RootStep{Name: "aws"},
+						lang.AttrStep{Name: "west"},
+					},
+				},
+			},
+		},
+	})
+	googleKey := NewSchemaKey(DependencyKeys{
+		Labels: []LabelDependent{
+			{Index: 0, Value: "google_compute_instance"},
+		},
+	})
+
+	old := &BodySchema{
+		Attributes: map[string]*AttributeSchema{
+			"version": {ValueType: cty.String, IsOptional: true},
+			"legacy":  {ValueType: cty.Bool, IsOptional: true},
+		},
+		Blocks: map[string]*BlockSchema{
+			"resource": {
+				Type: BlockTypeList,
+				Labels: []*LabelSchema{
+					{Name: "type", IsDepKey: true},
+					{Name: "name"},
+				},
+				Body: &BodySchema{
+					Attributes: map[string]*AttributeSchema{
+						"count": {ValueType: cty.Number, IsOptional: true},
+					},
+				},
+				DependentBody: map[SchemaKey]*BodySchema{
+					awsKey: {
+						Attributes: map[string]*AttributeSchema{
+							"ami":  {ValueType: cty.String, IsOptional: true},
+							"tags": {ValueType: cty.Map(cty.String), IsOptional: true},
+						},
+					},
+					googleKey: {},
+				},
+			},
+			"locals": {
+				Type: BlockTypeObject,
+			},
+		},
+	}
+	updated := &BodySchema{
+		Attributes: map[string]*AttributeSchema{
+			"version": {ValueTypes: ValueTypes{cty.String, cty.Number}, IsOptional: true},
+			"name":    {ValueType: cty.String, IsRequired: true},
+		},
+		Blocks: map[string]*BlockSchema{
+			"resource": {
+				Type: BlockTypeSet,
+				Labels: []*LabelSchema{
+					{Name: "kind", IsDepKey: true},
+					{Name: "name"},
+				},
+				IsDeprecated: true,
+				Body: &BodySchema{
+					Attributes: map[string]*AttributeSchema{
+						"count": {ValueType: cty.Number, IsOptional: true, IsDeprecated: true},
+					},
+				},
+				DependentBody: map[SchemaKey]*BodySchema{
+					awsKey: {
+						Attributes: map[string]*AttributeSchema{
+							"ami":  {ValueType: cty.String, IsRequired: true},
+							"tags": {ValueType: cty.Map(cty.String), IsOptional: true},
+						},
+					},
+					awsWestKey: {},
+				},
+			},
+			"module": {
+				Type:     BlockTypeList,
+				MinItems: 1,
+			},
+		},
+	}
+
+	expectedChanges := Changes{
+		{Kind: AttributeRemoved, Path: "legacy", IsBreaking: true},
+		{Kind: AttributeAdded, Path: "name", IsBreaking: true},
+		{Kind: AttributeTypeChanged, Path: "version", Old: "string", New: "string or number", IsBreaking: true},
+		{Kind: BlockRemoved, Path: "locals", IsBreaking: true},
+		{Kind: BlockAdded, Path: "module", IsBreaking: true},
+		{Kind: BlockTypeChanged, Path: "resource", Old: "list", New: "set", IsBreaking: true},
+		{Kind: BlockLabelsChanged, Path: "resource", Old: "type [dep key], name", New: "kind [dep key], name"},
+		{Kind: BlockDeprecated, Path: "resource"},
+		{Kind: AttributeDeprecated, Path: "resource.count"},
+		{Kind: AttributeRequirednessChanged, Path: `resource["aws_instance"].ami`, Old: "optional", New: "required", IsBreaking: true},
+		{Kind: DependentBodyAdded, Path: `resource["aws_instance", provider=aws.west]`},
+		{Kind: DependentBodyRemoved, Path: `resource["google_compute_instance"]`, IsBreaking: true},
+	}
+
+	changes := Diff(old, updated)
+	if diff := cmp.Diff(expectedChanges, changes); diff != "" {
+		t.Fatalf("unexpected changes: %s", diff)
+	}
+
+	expectedOutput := `- legacy: attribute removed [breaking]
++ name: attribute added [breaking]
+~ version: attribute type changed (string -> string or number) [breaking]
+- locals: block removed [breaking]
++ module: block added [breaking]
+~ resource: block type changed (list -> set) [breaking]
+~ resource: block labels changed (type [dep key], name -> kind [dep key], name)
+~ resource: block deprecated
+~ resource.count: attribute deprecated
+~ resource["aws_instance"].ami: attribute requiredness changed (optional -> required) [breaking]
++ resource["aws_instance", provider=aws.west]: dependent body added
+- resource["google_compute_instance"]: dependent body removed [breaking]
+`
+	if diff := cmp.Diff(expectedOutput, changes.String()); diff != "" {
+		t.Fatalf("unexpected output: %s", diff)
+	}
+
+	if len(changes.Breaking()) != 8 {
+		t.Fatalf("expected 8 breaking changes, given: %d", len(changes.Breaking()))
+	}
+}
+
+func TestDiff_deprecationAndDepKeys(t *testing.T) {
+	awsKey := NewSchemaKey(DependencyKeys{
+		Labels: []LabelDependent{
+			{Index: 0, Value: "aws_instance"},
+		},
+	})
+
+	old := &BodySchema{
+		Blocks: map[string]*BlockSchema{
+			"resource": {
+				Labels: []*LabelSchema{
+					{Name: "type", IsDepKey: true},
+					{Name: "name"},
+				},
+				DependentBody: map[SchemaKey]*BodySchema{
+					awsKey: {
+						Attributes: map[string]*AttributeSchema{
+							"arn": {ValueType: cty.String, IsOptional: true, IsComputed: true},
+							"id":  {ValueType: cty.String, IsOptional: true},
+						},
+					},
+				},
+			},
+		},
+	}
+	updated := &BodySchema{
+		Blocks: map[string]*BlockSchema{
+			"resource": {
+				Labels: []*LabelSchema{
+					{Name: "type"},
+					{Name: "name"},
+				},
+				Address: &BlockAddrSchema{
+					Steps: []AddrStep{
+						LabelStep{Index: 0},
+						LabelStep{Index: 1},
+					},
+				},
+				DependentBody: map[SchemaKey]*BodySchema{
+					awsKey: {
+						IsDeprecated: true,
+						Attributes: map[string]*AttributeSchema{
+							"arn": {ValueType: cty.String, IsComputed: true},
+							"id":  {ValueType: cty.String, IsComputed: true},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	expectedChanges := Changes{
+		{Kind: BlockLabelsChanged, Path: "resource", Old: "type [dep key], name", New: "type, name", IsBreaking: true},
+		{Kind: BlockAddressChanged, Path: "resource", New: "<type>.<name>"},
+		{Kind: BodyDeprecated, Path: `resource["aws_instance"]`},
+		{Kind: AttributeRequirednessChanged, Path: `resource["aws_instance"].arn`, Old: "optional", New: "computed", IsBreaking: true},
+		{Kind: AttributeRequirednessChanged, Path: `resource["aws_instance"].id`, Old: "optional", New: "computed", IsBreaking: true},
+	}
+	changes := Diff(old, updated)
+	if diff := cmp.Diff(expectedChanges, changes); diff != "" {
+		t.Fatalf("unexpected changes: %s", diff)
+	}
+
+	expectedChanges = Changes{
+		{Kind: BlockLabelsChanged, Path: "resource", Old: "type, name", New: "type [dep key], name"},
+		{Kind: BlockAddressChanged, Path: "resource", Old: "<type>.<name>", IsBreaking: true},
+		{Kind: AttributeRequirednessChanged, Path: `resource["aws_instance"].arn`, Old: "computed", New: "optional"},
+		{Kind: AttributeRequirednessChanged, Path: `resource["aws_instance"].id`, Old: "computed", New: "optional"},
+	}
+	changes = Diff(updated, old)
+	if diff := cmp.Diff(expectedChanges, changes); diff != "" {
+		t.Fatalf("unexpected reverse changes: %s", diff)
+	}
+}
+
+func TestDiff_noChanges(t *testing.T) {
+	bs := &BodySchema{
+		Attributes: map[string]*AttributeSchema{
+			"foo": {ValueType: cty.Object(map[string]cty.Type{"bar": cty.String}), IsOptional: true},
+		},
+	}
+	changes := Diff(bs, bs)
+	if len(changes) != 0 {
+		t.Fatalf("expected no changes, given: %s", changes)
+	}
+
+	changes = Diff(nil, nil)
+	if len(changes) != 0 {
+		t.Fatalf("expected no changes, given: %s", changes)
+	}
+}
+
+func TestDiff_anyAttributeAndItems(t *testing.T) {
+	old := &BodySchema{
+		AnyAttribute: &AttributeSchema{ValueType: cty.String, IsOptional: true},
+		Blocks: map[string]*BlockSchema{
+			"env": {
+				Body: &BodySchema{
+					AnyAttribute: &AttributeSchema{ValueType: cty.String, IsOptional: true},
+				},
+			},
+			"network": {MinItems: 1, MaxItems: 2},
+			"storage": {MaxItems: 1},
+		},
+	}
+	updated := &BodySchema{
+		Blocks: map[string]*BlockSchema{
+			"env": {
+				Body: &BodySchema{
+					AnyAttribute: &AttributeSchema{ValueType: cty.Number, IsOptional: true},
+				},
+			},
+			"network": {MinItems: 2, MaxItems: 1},
+			"storage": {},
+		},
+	}
+
+	expectedChanges := Changes{
+		{Kind: AnyAttributeRemoved, Path: "*", IsBreaking: true},
+		{Kind: AttributeTypeChanged, Path: "env.*", Old: "string", New: "number", IsBreaking: true},
+		{Kind: BlockMinItemsChanged, Path: "network", Old: "1", New: "2", IsBreaking: true},
+		{Kind: BlockMaxItemsChanged, Path: "network", Old: "2", New: "1", IsBreaking: true},
+		{Kind: BlockMaxItemsChanged, Path: "storage", Old: "1", New: "unlimited"},
+	}
+	changes := Diff(old, updated)
+	if diff := cmp.Diff(expectedChanges, changes); diff != "" {
+		t.Fatalf("unexpected changes: %s", diff)
+	}
+
+	expectedChanges = Changes{
+		{Kind: AnyAttributeAdded, Path: "*"},
+		{Kind: AttributeTypeChanged, Path: "env.*", Old: "number", New: "string", IsBreaking: true},
+		{Kind: BlockMinItemsChanged, Path: "network", Old: "2", New: "1"},
+		{Kind: BlockMaxItemsChanged, Path: "network", Old: "1", New: "2"},
+		{Kind: BlockMaxItemsChanged, Path: "storage", Old: "unlimited", New: "1", IsBreaking: true},
+	}
+	changes = Diff(updated, old)
+	if diff := cmp.Diff(expectedChanges, changes); diff != "" {
+		t.Fatalf("unexpected reverse changes: %s", diff)
+	}
+}
+
+func TestDiff_equivalentDependentKeys(t *testing.T) {
+	// keys declaring the same dependencies in different order
+	oldKey := SchemaKey(`{"labels":[{"index":1,"value":"sink"},{"index":0,"value":"kafka"}]}`)
+	newKey := NewSchemaKey(DependencyKeys{
+		Labels: []LabelDependent{
+			{Index: 0, Value: "kafka"},
+			{Index: 1, Value: "sink"},
+		},
+	})
+
+	old := &BodySchema{
+		Blocks: map[string]*BlockSchema{
+			"connector": {
+				DependentBody: map[SchemaKey]*BodySchema{
+					oldKey: {
+						Attributes: map[string]*AttributeSchema{
+							"topic": {ValueType: cty.String, IsOptional: true},
+						},
+					},
+				},
+			},
+		},
+	}
+	updated := &BodySchema{
+		Blocks: map[string]*BlockSchema{
+			"connector": {
+				DependentBody: map[SchemaKey]*BodySchema{
+					newKey: {
+						Attributes: map[string]*AttributeSchema{
+							"topic": {ValueType: cty.String, IsRequired: true},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	expectedChanges := Changes{
+		{Kind: AttributeRequirednessChanged, Path: `connector["kafka", "sink"].topic`, Old: "optional", New: "required", IsBreaking: true},
+	}
+	changes := Diff(old, updated)
+	if diff := cmp.Diff(expectedChanges, changes); diff != "" {
+		t.Fatalf("unexpected changes: %s", diff)
+	}
+}
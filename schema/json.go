@@ -0,0 +1,533 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// JSONFormatVersion represents version of the JSON format
+// produced by MarshalBodySchema.
+//
+// Minor version is bumped on backwards-compatible changes
+// (such as new optional fields), major version is bumped
+// when the format changes in a backwards-incompatible way.
+const JSONFormatVersion = "1.0"
+
+type jsonDocument struct {
+	FormatVersion string      `json:"format_version"`
+	Body          *BodySchema `json:"body"`
+}
+
+// MarshalBodySchema encodes the given schema into versioned JSON
+// which can be decoded back via UnmarshalBodySchema
+func MarshalBodySchema(bs *BodySchema) ([]byte, error) {
+	return json.Marshal(jsonDocument{
+		FormatVersion: JSONFormatVersion,
+		Body:          bs,
+	})
+}
+
+// UnmarshalBodySchema decodes schema from versioned JSON
+// produced by MarshalBodySchema
+func UnmarshalBodySchema(b []byte) (*BodySchema, error) {
+	var doc struct {
+		FormatVersion string          `json:"format_version"`
+		Body          json.RawMessage `json:"body"`
+	}
+	err := json.Unmarshal(b, &doc)
+	if err != nil {
+		return nil, err
+	}
+
+	if doc.FormatVersion == "" {
+		return nil, fmt.Errorf("missing format_version")
+	}
+	if !isSupportedFormatVersion(doc.FormatVersion) {
+		return nil, fmt.Errorf("unsupported format_version %q, expected %q",
+			doc.FormatVersion, JSONFormatVersion)
+	}
+
+	bs := &BodySchema{}
+	err = json.Unmarshal(doc.Body, bs)
+	if err != nil {
+		return nil, err
+	}
+
+	return bs, nil
+}
+
+func isSupportedFormatVersion(v string) bool {
+	major := strings.SplitN(v, ".", 2)[0]
+	supportedMajor := strings.SplitN(JSONFormatVersion, ".", 2)[0]
+	return major == supportedMajor
+}
+
+type jsonBodySchema struct {
+	Blocks       map[string]*BlockSchema     `json:"blocks,omitempty"`
+	Attributes   map[string]*AttributeSchema `json:"attributes,omitempty"`
+	AnyAttribute *AttributeSchema            `json:"any_attribute,omitempty"`
+	IsDeprecated bool                        `json:"is_deprecated,omitempty"`
+	Detail       string                      `json:"detail,omitempty"`
+	Description  *jsonMarkupContent          `json:"description,omitempty"`
+	DocsLink     *jsonDocsLink               `json:"docs_link,omitempty"`
+}
+
+type jsonDocsLink struct {
+	URL     string `json:"url"`
+	Tooltip string `json:"tooltip,omitempty"`
+}
+
+func (bs BodySchema) MarshalJSON() ([]byte, error) {
+	jbs := jsonBodySchema{
+		Blocks:       bs.Blocks,
+		Attributes:   bs.Attributes,
+		AnyAttribute: bs.AnyAttribute,
+		IsDeprecated: bs.IsDeprecated,
+		Detail:       bs.Detail,
+		Description:  marshalMarkupContent(bs.Description),
+	}
+	if bs.DocsLink != nil {
+		jbs.DocsLink = &jsonDocsLink{
+			URL:     bs.DocsLink.URL,
+			Tooltip: bs.DocsLink.Tooltip,
+		}
+	}
+
+	return json.Marshal(jbs)
+}
+
+func (bs *BodySchema) UnmarshalJSON(b []byte) error {
+	var jbs jsonBodySchema
+	err := json.Unmarshal(b, &jbs)
+	if err != nil {
+		return err
+	}
+
+	description, err := unmarshalMarkupContent(jbs.Description)
+	if err != nil {
+		return err
+	}
+
+	*bs = BodySchema{
+		Blocks:       jbs.Blocks,
+		Attributes:   jbs.Attributes,
+		AnyAttribute: jbs.AnyAttribute,
+		IsDeprecated: jbs.IsDeprecated,
+		Detail:       jbs.Detail,
+		Description:  description,
+	}
+	if jbs.DocsLink != nil {
+		bs.DocsLink = &DocsLink{
+			URL:     jbs.DocsLink.URL,
+			Tooltip: jbs.DocsLink.Tooltip,
+		}
+	}
+
+	return nil
+}
+
+type jsonBlockSchema struct {
+	Labels        []*LabelSchema        `json:"labels,omitempty"`
+	Type          string                `json:"type,omitempty"`
+	Body          *BodySchema           `json:"body,omitempty"`
+	DependentBody []jsonDependentSchema `json:"dependent_body,omitempty"`
+	Description   *jsonMarkupContent    `json:"description,omitempty"`
+	IsDeprecated  bool                  `json:"is_deprecated,omitempty"`
+	MinItems      uint64                `json:"min_items,omitempty"`
+	MaxItems      uint64                `json:"max_items,omitempty"`
+	Address       []jsonAddrStep        `json:"address,omitempty"`
+}
+
+// jsonAddrStep represents either StaticStep or LabelStep
+type jsonAddrStep struct {
+	Static string `json:"static,omitempty"`
+	Label  *int   `json:"label,omitempty"`
+}
+
+type jsonDependentSchema struct {
+	Keys json.RawMessage `json:"keys"`
+	Body *BodySchema     `json:"body"`
+}
+
+func (bs BlockSchema) MarshalJSON() ([]byte, error) {
+	jbs := jsonBlockSchema{
+		Labels:       bs.Labels,
+		Type:         bs.Type.String(),
+		Body:         bs.Body,
+		Description:  marshalMarkupContent(bs.Description),
+		IsDeprecated: bs.IsDeprecated,
+		MinItems:     bs.MinItems,
+		MaxItems:     bs.MaxItems,
+	}
+
+	if bs.Address != nil {
+		steps, err := marshalAddrSteps(bs.Address.Steps)
+		if err != nil {
+			return nil, err
+		}
+		jbs.Address = steps
+	}
+
+	if len(bs.DependentBody) > 0 {
+		keys := make([]string, 0, len(bs.DependentBody))
+		for key := range bs.DependentBody {
+			keys = append(keys, string(key))
+		}
+		sort.Strings(keys)
+
+		jbs.DependentBody = make([]jsonDependentSchema, len(keys))
+		for i, key := range keys {
+			if !json.Valid([]byte(key)) {
+				return nil, fmt.Errorf("invalid dependent body key: %q", key)
+			}
+			jbs.DependentBody[i] = jsonDependentSchema{
+				Keys: json.RawMessage(key),
+				Body: bs.DependentBody[SchemaKey(key)],
+			}
+		}
+	}
+
+	return json.Marshal(jbs)
+}
+
+func (bs *BlockSchema) UnmarshalJSON(b []byte) error {
+	var jbs jsonBlockSchema
+	err := json.Unmarshal(b, &jbs)
+	if err != nil {
+		return err
+	}
+
+	blockType, err := blockTypeFromString(jbs.Type)
+	if err != nil {
+		return err
+	}
+
+	description, err := unmarshalMarkupContent(jbs.Description)
+	if err != nil {
+		return err
+	}
+
+	*bs = BlockSchema{
+		Labels:       jbs.Labels,
+		Type:         blockType,
+		Body:         jbs.Body,
+		Description:  description,
+		IsDeprecated: jbs.IsDeprecated,
+		MinItems:     jbs.MinItems,
+		MaxItems:     jbs.MaxItems,
+	}
+
+	if len(jbs.Address) > 0 {
+		steps, err := unmarshalAddrSteps(jbs.Address)
+		if err != nil {
+			return err
+		}
+		bs.Address = &BlockAddrSchema{Steps: steps}
+	}
+
+	if len(jbs.DependentBody) > 0 {
+		bs.DependentBody = make(map[SchemaKey]*BodySchema, len(jbs.DependentBody))
+		for _, ds := range jbs.DependentBody {
+			var dks DependencyKeys
+			err := json.Unmarshal(ds.Keys, &dks)
+			if err != nil {
+				return fmt.Errorf("invalid dependent body keys: %w", err)
+			}
+			bs.DependentBody[NewSchemaKey(dks)] = ds.Body
+		}
+	}
+
+	return nil
+}
+
+func marshalAddrSteps(steps []AddrStep) ([]jsonAddrStep, error) {
+	jSteps := make([]jsonAddrStep, len(steps))
+	for i, step := range steps {
+		switch s := step.(type) {
+		case StaticStep:
+			jSteps[i] = jsonAddrStep{Static: s.Name}
+		case LabelStep:
+			idx := s.Index
+			jSteps[i] = jsonAddrStep{Label: &idx}
+		default:
+			return nil, fmt.Errorf("unknown address step type: %T", step)
+		}
+	}
+	return jSteps, nil
+}
+
+func unmarshalAddrSteps(jSteps []jsonAddrStep) ([]AddrStep, error) {
+	steps := make([]AddrStep, len(jSteps))
+	for i, js := range jSteps {
+		switch {
+		case js.Static != "" && js.Label == nil:
+			steps[i] = StaticStep{Name: js.Static}
+		case js.Static == "" && js.Label != nil:
+			steps[i] = LabelStep{Index: *js.Label}
+		default:
+			return nil, fmt.Errorf("address step %d: exactly one of static or label must be set", i)
+		}
+	}
+	return steps, nil
+}
+
+func blockTypeFromString(s string) (BlockType, error) {
+	switch s {
+	case "":
+		return BlockTypeNil, nil
+	case "list":
+		return BlockTypeList, nil
+	case "map":
+		return BlockTypeMap, nil
+	case "object":
+		return BlockTypeObject, nil
+	case "set":
+		return BlockTypeSet, nil
+	case "tuple":
+		return BlockTypeTuple, nil
+	}
+	return BlockTypeNil, fmt.Errorf("unknown block type: %q", s)
+}
+
+type jsonLabelSchema struct {
+	Name        string             `json:"name,omitempty"`
+	Description *jsonMarkupContent `json:"description,omitempty"`
+	IsDepKey    bool               `json:"is_dep_key,omitempty"`
+}
+
+func (ls LabelSchema) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonLabelSchema{
+		Name:        ls.Name,
+		Description: marshalMarkupContent(ls.Description),
+		IsDepKey:    ls.IsDepKey,
+	})
+}
+
+func (ls *LabelSchema) UnmarshalJSON(b []byte) error {
+	var jls jsonLabelSchema
+	err := json.Unmarshal(b, &jls)
+	if err != nil {
+		return err
+	}
+
+	description, err := unmarshalMarkupContent(jls.Description)
+	if err != nil {
+		return err
+	}
+
+	*ls = LabelSchema{
+		Name:        jls.Name,
+		Description: description,
+		IsDepKey:    jls.IsDepKey,
+	}
+
+	return nil
+}
+
+type jsonAttributeSchema struct {
+	Description         *jsonMarkupContent           `json:"description,omitempty"`
+	IsRequired          bool                         `json:"is_required,omitempty"`
+	IsOptional          bool                         `json:"is_optional,omitempty"`
+	IsDeprecated        bool                         `json:"is_deprecated,omitempty"`
+	IsComputed          bool                         `json:"is_computed,omitempty"`
+	ValueType           json.RawMessage              `json:"value_type,omitempty"`
+	ValueTypes          []json.RawMessage            `json:"value_types,omitempty"`
+	IsDepKey            bool                         `json:"is_dep_key,omitempty"`
+	MapKeys             map[string]*jsonMapKeySchema `json:"map_keys,omitempty"`
+	IsMapKeysExhaustive bool                         `json:"is_map_keys_exhaustive,omitempty"`
+	AllowedValues       []jsonAllowedValue           `json:"allowed_values,omitempty"`
+}
+
+type jsonMapKeySchema struct {
+	Description  *jsonMarkupContent `json:"description,omitempty"`
+	IsRequired   bool               `json:"is_required,omitempty"`
+	IsDeprecated bool               `json:"is_deprecated,omitempty"`
+}
+
+type jsonAllowedValue struct {
+	Value        string             `json:"value"`
+	Description  *jsonMarkupContent `json:"description,omitempty"`
+	IsDeprecated bool               `json:"is_deprecated,omitempty"`
+}
+
+func (as AttributeSchema) MarshalJSON() ([]byte, error) {
+	jas := jsonAttributeSchema{
+		Description:         marshalMarkupContent(as.Description),
+		IsRequired:          as.IsRequired,
+		IsOptional:          as.IsOptional,
+		IsDeprecated:        as.IsDeprecated,
+		IsComputed:          as.IsComputed,
+		IsDepKey:            as.IsDepKey,
+		IsMapKeysExhaustive: as.IsMapKeysExhaustive,
+	}
+
+	if as.ValueType != cty.NilType {
+		b, err := ctyjson.MarshalType(as.ValueType)
+		if err != nil {
+			return nil, err
+		}
+		jas.ValueType = b
+	}
+
+	if len(as.ValueTypes) > 0 {
+		jas.ValueTypes = make([]json.RawMessage, len(as.ValueTypes))
+		for i, vt := range as.ValueTypes {
+			b, err := ctyjson.MarshalType(vt)
+			if err != nil {
+				return nil, err
+			}
+			jas.ValueTypes[i] = b
+		}
+	}
+
+	if len(as.MapKeys) > 0 {
+		jas.MapKeys = make(map[string]*jsonMapKeySchema, len(as.MapKeys))
+		for name, mk := range as.MapKeys {
+			if mk == nil {
+				jas.MapKeys[name] = nil
+				continue
+			}
+			jas.MapKeys[name] = &jsonMapKeySchema{
+				Description:  marshalMarkupContent(mk.Description),
+				IsRequired:   mk.IsRequired,
+				IsDeprecated: mk.IsDeprecated,
+			}
+		}
+	}
+
+	if len(as.AllowedValues) > 0 {
+		jas.AllowedValues = make([]jsonAllowedValue, len(as.AllowedValues))
+		for i, av := range as.AllowedValues {
+			jas.AllowedValues[i] = jsonAllowedValue{
+				Value:        av.Value,
+				Description:  marshalMarkupContent(av.Description),
+				IsDeprecated: av.IsDeprecated,
+			}
+		}
+	}
+
+	return json.Marshal(jas)
+}
+
+func (as *AttributeSchema) UnmarshalJSON(b []byte) error {
+	var jas jsonAttributeSchema
+	err := json.Unmarshal(b, &jas)
+	if err != nil {
+		return err
+	}
+
+	description, err := unmarshalMarkupContent(jas.Description)
+	if err != nil {
+		return err
+	}
+
+	*as = AttributeSchema{
+		Description:         description,
+		IsRequired:          jas.IsRequired,
+		IsOptional:          jas.IsOptional,
+		IsDeprecated:        jas.IsDeprecated,
+		IsComputed:          jas.IsComputed,
+		IsDepKey:            jas.IsDepKey,
+		IsMapKeysExhaustive: jas.IsMapKeysExhaustive,
+	}
+
+	if len(jas.ValueType) > 0 {
+		t, err := ctyjson.UnmarshalType(jas.ValueType)
+		if err != nil {
+			return fmt.Errorf("invalid value_type: %w", err)
+		}
+		as.ValueType = t
+	}
+
+	if len(jas.ValueTypes) > 0 {
+		as.ValueTypes = make(ValueTypes, len(jas.ValueTypes))
+		for i, raw := range jas.ValueTypes {
+			t, err := ctyjson.UnmarshalType(raw)
+			if err != nil {
+				return fmt.Errorf("invalid value_types[%d]: %w", i, err)
+			}
+			as.ValueTypes[i] = t
+		}
+	}
+
+	if len(jas.MapKeys) > 0 {
+		as.MapKeys = make(map[string]*MapKeySchema, len(jas.MapKeys))
+		for name, mk := range jas.MapKeys {
+			if mk == nil {
+				as.MapKeys[name] = nil
+				continue
+			}
+			description, err := unmarshalMarkupContent(mk.Description)
+			if err != nil {
+				return err
+			}
+			as.MapKeys[name] = &MapKeySchema{
+				Description:  description,
+				IsRequired:   mk.IsRequired,
+				IsDeprecated: mk.IsDeprecated,
+			}
+		}
+	}
+
+	if len(jas.AllowedValues) > 0 {
+		as.AllowedValues = make([]AllowedValue, len(jas.AllowedValues))
+		for i, av := range jas.AllowedValues {
+			description, err := unmarshalMarkupContent(av.Description)
+			if err != nil {
+				return err
+			}
+			as.AllowedValues[i] = AllowedValue{
+				Value:        av.Value,
+				Description:  description,
+				IsDeprecated: av.IsDeprecated,
+			}
+		}
+	}
+
+	return nil
+}
+
+type jsonMarkupContent struct {
+	Value string `json:"value"`
+	Kind  string `json:"kind,omitempty"`
+}
+
+func marshalMarkupContent(mc lang.MarkupContent) *jsonMarkupContent {
+	if mc.Value == "" && mc.Kind == lang.NilKind {
+		return nil
+	}
+
+	jmc := &jsonMarkupContent{Value: mc.Value}
+	switch mc.Kind {
+	case lang.PlainTextKind:
+		jmc.Kind = "plaintext"
+	case lang.MarkdownKind:
+		jmc.Kind = "markdown"
+	}
+	return jmc
+}
+
+func unmarshalMarkupContent(jmc *jsonMarkupContent) (lang.MarkupContent, error) {
+	if jmc == nil {
+		return lang.MarkupContent{}, nil
+	}
+
+	mc := lang.MarkupContent{Value: jmc.Value}
+	switch jmc.Kind {
+	case "":
+		mc.Kind = lang.NilKind
+	case "plaintext":
+		mc.Kind = lang.PlainTextKind
+	case "markdown":
+		mc.Kind = lang.MarkdownKind
+	default:
+		return mc, fmt.Errorf("unknown markup kind: %q", jmc.Kind)
+	}
+	return mc, nil
+}
@@ -0,0 +1,334 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/zclconf/go-cty-debug/ctydebug"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestBodySchema_JSONRoundTrip(t *testing.T) {
+	testCases := []struct {
+		name   string
+		schema *BodySchema
+	}{
+		{
+			"empty",
+			&BodySchema{},
+		},
+		{
+			"any attribute",
+			&BodySchema{
+				AnyAttribute: &AttributeSchema{
+					ValueType:  cty.Map(cty.String),
+					IsOptional: true,
+				},
+			},
+		},
+		{
+			"full",
+			&BodySchema{
+				Detail:       "hashicorp/aws",
+				Description:  lang.Markdown("AWS **provider**"),
+				IsDeprecated: true,
+				DocsLink: &DocsLink{
+					URL:     "https://registry.terraform.io/providers/hashicorp/aws/latest/docs",
+					Tooltip: "AWS Provider Documentation",
+				},
+				Attributes: map[string]*AttributeSchema{
+					"count": {
+						ValueType:   cty.Number,
+						IsOptional:  true,
+						Description: lang.PlainText("number of instances"),
+					},
+					"tags": {
+						ValueType:  cty.Map(cty.String),
+						IsOptional: true,
+						MapKeys: map[string]*MapKeySchema{
+							"Name": {
+								Description: lang.PlainText("name of the resource"),
+								IsRequired:  true,
+							},
+							"Legacy": {
+								IsDeprecated: true,
+							},
+						},
+						IsMapKeysExhaustive: true,
+					},
+					"tier": {
+						ValueType:  cty.String,
+						IsDepKey:   true,
+						IsComputed: true,
+						IsOptional: true,
+						AllowedValues: []AllowedValue{
+							{Value: "Standard"},
+							{
+								Value:        "Premium",
+								Description:  lang.Markdown("_paid_ tier"),
+								IsDeprecated: true,
+							},
+						},
+					},
+				},
+				Blocks: map[string]*BlockSchema{
+					"resource": {
+						Type:         BlockTypeList,
+						Description:  lang.PlainText("resource block"),
+						IsDeprecated: true,
+						MinItems:     1,
+						MaxItems:     10,
+						Labels: []*LabelSchema{
+							{
+								Name:        "type",
+								Description: lang.PlainText("resource type"),
+								IsDepKey:    true,
+							},
+							{Name: "name"},
+						},
+						Address: &BlockAddrSchema{
+							Steps: []AddrStep{
+								StaticStep{Name: "resource"},
+								LabelStep{Index: 0},
+								LabelStep{Index: 1},
+							},
+						},
+						Body: &BodySchema{
+							Attributes: map[string]*AttributeSchema{
+								"provider": {
+									ValueType:  cty.DynamicPseudoType,
+									IsOptional: true,
+									IsDepKey:   true,
+								},
+							},
+						},
+						DependentBody: map[SchemaKey]*BodySchema{
+							NewSchemaKey(DependencyKeys{
+								Labels: []LabelDependent{
+									{Index: 0, Value: "aws_instance"},
+								},
+							}): {
+								Detail: "aws_instance",
+								Attributes: map[string]*AttributeSchema{
+									"ami": {ValueType: cty.String, IsRequired: true},
+								},
+							},
+							NewSchemaKey(DependencyKeys{
+								Labels: []LabelDependent{
+									{Index: 0, Value: "aws_instance"},
+								},
+								Attributes: []AttributeDependent{
+									{
+										Name: "provider",
+										Expr: ExpressionValue{
+											Reference: lang.Reference{
+												lang.RootStep{Name: "aws"},
+												lang.AttrStep{Name: "west"},
+											},
+										},
+									},
+								},
+							}): {
+								Detail: "aws_instance (west)",
+							},
+							NewSchemaKey(DependencyKeys{
+								Attributes: []AttributeDependent{
+									{
+										Name: "count",
+										Expr: ExpressionValue{
+											Static: cty.NumberIntVal(2),
+										},
+									},
+								},
+							}): {
+								Blocks: map[string]*BlockSchema{
+									"nested": {Type: BlockTypeSet},
+								},
+							},
+							NewSchemaKey(DependencyKeys{}): {
+								Description: lang.PlainText("default"),
+							},
+						},
+					},
+					"lifecycle": {
+						Type: BlockTypeObject,
+					},
+					"setting": {
+						Type: BlockTypeMap,
+						Labels: []*LabelSchema{
+							{Name: "key"},
+						},
+					},
+					"tuple": {
+						Type: BlockTypeTuple,
+					},
+				},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			b, err := MarshalBodySchema(tc.schema)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			bs, err := UnmarshalBodySchema(b)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(tc.schema, bs, ctydebug.CmpOptions); diff != "" {
+				t.Fatalf("schema mismatch after round-trip: %s", diff)
+			}
+
+			// ensure the output is stable
+			b2, err := MarshalBodySchema(bs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(string(b), string(b2)); diff != "" {
+				t.Fatalf("unstable JSON output: %s", diff)
+			}
+		})
+	}
+}
+
+func TestMarshalBodySchema(t *testing.T) {
+	bs := &BodySchema{
+		Attributes: map[string]*AttributeSchema{
+			"name": {
+				ValueType:   cty.List(cty.String),
+				IsRequired:  true,
+				Description: lang.Markdown("**name**"),
+			},
+		},
+		Blocks: map[string]*BlockSchema{
+			"resource": {
+				Type: BlockTypeList,
+				Labels: []*LabelSchema{
+					{Name: "type", IsDepKey: true},
+				},
+				DependentBody: map[SchemaKey]*BodySchema{
+					NewSchemaKey(DependencyKeys{
+						Labels: []LabelDependent{
+							{Index: 0, Value: "aws_instance"},
+						},
+					}): {Detail: "aws_instance"},
+				},
+			},
+		},
+	}
+
+	b, err := MarshalBodySchema(bs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedJSON := `{"format_version":"1.0","body":{` +
+		`"blocks":{"resource":{"labels":[{"name":"type","is_dep_key":true}],"type":"list",` +
+		`"dependent_body":[{"keys":{"labels":[{"index":0,"value":"aws_instance"}]},"body":{"detail":"aws_instance"}}]}},` +
+		`"attributes":{"name":{"description":{"value":"**name**","kind":"markdown"},"is_required":true,"value_type":["list","string"]}}}}`
+	if diff := cmp.Diff(expectedJSON, string(b)); diff != "" {
+		t.Fatalf("unexpected JSON: %s", diff)
+	}
+}
+
+func TestUnmarshalBodySchema_invalid(t *testing.T) {
+	testCases := []struct {
+		name          string
+		json          string
+		expectedError string
+	}{
+		{
+			"missing version",
+			`{"body":{}}`,
+			`missing format_version`,
+		},
+		{
+			"unsupported version",
+			`{"format_version":"2.0","body":{}}`,
+			`unsupported format_version "2.0", expected "1.0"`,
+		},
+		{
+			"unknown block type",
+			`{"format_version":"1.0","body":{"blocks":{"foo":{"type":"unknown"}}}}`,
+			`unknown block type: "unknown"`,
+		},
+		{
+			"unknown markup kind",
+			`{"format_version":"1.0","body":{"description":{"value":"x","kind":"html"}}}`,
+			`unknown markup kind: "html"`,
+		},
+		{
+			"invalid address step",
+			`{"format_version":"1.0","body":{"blocks":{"foo":{"address":[{"static":"foo","label":0}]}}}}`,
+			`address step 0: exactly one of static or label must be set`,
+		},
+		{
+			"invalid value type",
+			`{"format_version":"1.0","body":{"attributes":{"foo":{"value_type":"foo"}}}}`,
+			`invalid value_type: invalid primitive type name "foo"`,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			_, err := UnmarshalBodySchema([]byte(tc.json))
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if diff := cmp.Diff(tc.expectedError, err.Error()); diff != "" {
+				t.Fatalf("unexpected error: %s", diff)
+			}
+		})
+	}
+}
+
+func TestUnmarshalBodySchema_minorVersion(t *testing.T) {
+	bs, err := UnmarshalBodySchema([]byte(`{"format_version":"1.5","body":{"detail":"foo"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(&BodySchema{Detail: "foo"}, bs, ctydebug.CmpOptions); diff != "" {
+		t.Fatalf("unexpected schema: %s", diff)
+	}
+}
+
+func TestAttributeSchema_JSONRoundTrip_valueTypes(t *testing.T) {
+	as := &AttributeSchema{
+		ValueTypes: ValueTypes{
+			cty.String,
+			cty.Object(map[string]cty.Type{
+				"path": cty.String,
+				"size": cty.List(cty.Number),
+			}),
+		},
+		IsRequired: true,
+	}
+
+	b, err := json.Marshal(as)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded := &AttributeSchema{}
+	err = json.Unmarshal(b, decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded.ValueType != cty.NilType {
+		t.Fatalf("expected no ValueType, given: %s", decoded.ValueType.FriendlyName())
+	}
+	if diff := cmp.Diff(as.ValueTypes, decoded.ValueTypes, ctydebug.CmpOptions); diff != "" {
+		t.Fatalf("value types mismatch after round-trip: %s", diff)
+	}
+	if !decoded.IsRequired {
+		t.Fatal("expected IsRequired to be preserved")
+	}
+}
@@ -1,6 +1,8 @@
 package schema
 
 import (
+	"errors"
+
 	"github.com/hashicorp/hcl-lang/lang"
 )
 
@@ -17,3 +19,10 @@ type LabelSchema struct {
 func (*LabelSchema) isSchemaImpl() schemaImplSigil {
 	return schemaImplSigil{}
 }
+
+func (ls *LabelSchema) Validate() error {
+	if ls.Name == "" {
+		return errors.New("Name must be set")
+	}
+	return nil
+}
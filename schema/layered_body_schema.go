@@ -0,0 +1,115 @@
+package schema
+
+import (
+	"sort"
+)
+
+// LayeredBodySchema represents a read-only view of multiple body schemas
+// layered on top of each other, such as body of a block and its dependent body.
+//
+// Attributes and blocks are resolved from layers in the order
+// in which they were provided, i.e. the first layer declaring
+// an attribute or a block wins. Layers are never copied nor mutated.
+//
+// AnyAttribute is only taken from the base schema, i.e. overlays
+// (such as dependent bodies) can declare named attributes,
+// but cannot allow arbitrary ones.
+type LayeredBodySchema struct {
+	base   *BodySchema
+	layers []*BodySchema
+}
+
+// NewLayeredBodySchema creates a new view of the given base schema
+// with overlays. Nil schemas are ignored.
+func NewLayeredBodySchema(base *BodySchema, overlays ...*BodySchema) *LayeredBodySchema {
+	layers := make([]*BodySchema, 0, len(overlays)+1)
+	if base != nil {
+		layers = append(layers, base)
+	}
+	for _, overlay := range overlays {
+		if overlay != nil {
+			layers = append(layers, overlay)
+		}
+	}
+	return &LayeredBodySchema{base: base, layers: layers}
+}
+
+// IsEmpty returns true if there are no layers in the view
+func (ls *LayeredBodySchema) IsEmpty() bool {
+	return len(ls.layers) == 0
+}
+
+// Attribute returns schema of the named attribute from the first layer
+// which declares it
+func (ls *LayeredBodySchema) Attribute(name string) (*AttributeSchema, bool) {
+	for _, layer := range ls.layers {
+		attr, ok := layer.Attributes[name]
+		if ok {
+			return attr, true
+		}
+	}
+	return nil, false
+}
+
+// AnyAttribute returns AnyAttribute of the base schema
+func (ls *LayeredBodySchema) AnyAttribute() *AttributeSchema {
+	if ls.base == nil {
+		return nil
+	}
+	return ls.base.AnyAttribute
+}
+
+// AttributeOrAny returns schema of the named attribute,
+// or AnyAttribute if the attribute isn't declared
+func (ls *LayeredBodySchema) AttributeOrAny(name string) (*AttributeSchema, bool) {
+	attr, ok := ls.Attribute(name)
+	if ok {
+		return attr, true
+	}
+	attr = ls.AnyAttribute()
+	return attr, attr != nil
+}
+
+// Block returns schema of the block type from the first layer
+// which declares it
+func (ls *LayeredBodySchema) Block(bType string) (*BlockSchema, bool) {
+	for _, layer := range ls.layers {
+		block, ok := layer.Blocks[bType]
+		if ok {
+			return block, true
+		}
+	}
+	return nil, false
+}
+
+// AttributeNames returns sorted names of attributes declared in any layer
+func (ls *LayeredBodySchema) AttributeNames() []string {
+	seen := make(map[string]bool, 0)
+	names := make([]string, 0)
+	for _, layer := range ls.layers {
+		for name := range layer.Attributes {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// BlockTypes returns sorted block types declared in any layer
+func (ls *LayeredBodySchema) BlockTypes() []string {
+	seen := make(map[string]bool, 0)
+	bTypes := make([]string, 0)
+	for _, layer := range ls.layers {
+		for bType := range layer.Blocks {
+			if !seen[bType] {
+				seen[bType] = true
+				bTypes = append(bTypes, bType)
+			}
+		}
+	}
+	sort.Strings(bTypes)
+	return bTypes
+}
@@ -0,0 +1,90 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zclconf/go-cty-debug/ctydebug"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestLayeredBodySchema(t *testing.T) {
+	base := &BodySchema{
+		Attributes: map[string]*AttributeSchema{
+			"count": {ValueType: cty.Number},
+			"name":  {ValueType: cty.String},
+		},
+		Blocks: map[string]*BlockSchema{
+			"lifecycle": {Type: BlockTypeObject},
+		},
+	}
+	dependent := &BodySchema{
+		Attributes: map[string]*AttributeSchema{
+			"name": {ValueType: cty.Number},
+			"ami":  {ValueType: cty.String},
+		},
+		Blocks: map[string]*BlockSchema{
+			"ebs_volume": {Type: BlockTypeList},
+		},
+	}
+
+	ls := NewLayeredBodySchema(base, nil, dependent)
+
+	if diff := cmp.Diff([]string{"ami", "count", "name"}, ls.AttributeNames()); diff != "" {
+		t.Fatalf("unexpected attribute names: %s", diff)
+	}
+	if diff := cmp.Diff([]string{"ebs_volume", "lifecycle"}, ls.BlockTypes()); diff != "" {
+		t.Fatalf("unexpected block types: %s", diff)
+	}
+
+	attr, ok := ls.Attribute("name")
+	if !ok {
+		t.Fatal("expected to find name attribute")
+	}
+	if diff := cmp.Diff(base.Attributes["name"], attr, ctydebug.CmpOptions); diff != "" {
+		t.Fatalf("expected base attribute to take precedence: %s", diff)
+	}
+
+	attr, ok = ls.Attribute("ami")
+	if !ok {
+		t.Fatal("expected to find ami attribute")
+	}
+	if diff := cmp.Diff(dependent.Attributes["ami"], attr, ctydebug.CmpOptions); diff != "" {
+		t.Fatalf("unexpected dependent attribute: %s", diff)
+	}
+
+	_, ok = ls.Block("ebs_volume")
+	if !ok {
+		t.Fatal("expected to find ebs_volume block")
+	}
+
+	if len(base.Attributes) != 2 || len(base.Blocks) != 1 {
+		t.Fatal("expected base schema not to be mutated")
+	}
+}
+
+func TestLayeredBodySchema_AttributeOrAny(t *testing.T) {
+	ls := NewLayeredBodySchema(&BodySchema{
+		AnyAttribute: &AttributeSchema{ValueType: cty.String},
+	}, &BodySchema{})
+
+	attr, ok := ls.AttributeOrAny("foo")
+	if !ok {
+		t.Fatal("expected AnyAttribute to be returned")
+	}
+	if attr.ValueType != cty.String {
+		t.Fatalf("unexpected attribute type: %#v", attr.ValueType)
+	}
+
+	ls = NewLayeredBodySchema(&BodySchema{}, &BodySchema{
+		AnyAttribute: &AttributeSchema{ValueType: cty.String},
+	})
+	_, ok = ls.AttributeOrAny("foo")
+	if ok {
+		t.Fatal("expected AnyAttribute of overlay to be ignored")
+	}
+
+	if !NewLayeredBodySchema(nil).IsEmpty() {
+		t.Fatal("expected view without layers to be empty")
+	}
+}
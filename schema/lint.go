@@ -0,0 +1,241 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+// LintIssueKind represents kind of a problem found by Lint
+type LintIssueKind uint
+
+const (
+	NilLintIssue LintIssueKind = iota
+	UnreachableDependentBody
+	NonScalarDepKeyAttribute
+	RedefinedBaseAttribute
+	RedefinedBaseBlock
+)
+
+func (k LintIssueKind) String() string {
+	switch k {
+	case UnreachableDependentBody:
+		return "unreachable dependent body"
+	case NonScalarDepKeyAttribute:
+		return "non-scalar dependency key attribute"
+	case RedefinedBaseAttribute:
+		return "attribute redefined in dependent body"
+	case RedefinedBaseBlock:
+		return "block redefined in dependent body"
+	}
+	return ""
+}
+
+// LintIssue describes a schema definition which is valid,
+// but is unlikely to behave as the schema author intended
+type LintIssue struct {
+	Kind LintIssueKind
+
+	// Path is a dot-separated path to the affected block, attribute
+	// or dependent body (see Change.Path for the format)
+	Path string
+
+	// Message describes the issue in detail
+	Message string
+
+	// Suggestion describes how the issue can be fixed
+	Suggestion string
+}
+
+func (li LintIssue) String() string {
+	msg := fmt.Sprintf("%s: %s", li.Path, li.Message)
+	if li.Suggestion != "" {
+		msg += fmt.Sprintf(" (%s)", li.Suggestion)
+	}
+	return msg
+}
+
+// Lint checks the schema for definitions which can never take effect
+// when decoding configuration, such as:
+//
+//   - dependent bodies with keys which can never match, e.g. because
+//     the referenced label or attribute is not marked as IsDepKey
+//   - IsDepKey attributes with non-scalar types, whose values
+//     cannot be used as dependency keys
+//   - dependent bodies redefining attributes or blocks of the base body,
+//     which are ignored in favour of the base body definitions
+//
+// Issues are returned in deterministic order, following the schema hierarchy.
+func Lint(bs *BodySchema) []LintIssue {
+	issues := make([]LintIssue, 0)
+	lintBody(&issues, "", bs, map[*BlockSchema]bool{})
+	return issues
+}
+
+// lintBody reports issues of blocks of the body.
+//
+// parents holds schemas of the enclosing blocks, such that
+// recursive blocks are only checked once (where first declared).
+func lintBody(issues *[]LintIssue, path string, bs *BodySchema, parents map[*BlockSchema]bool) {
+	if bs == nil {
+		return
+	}
+
+	bTypes := blockTypes(bs)
+	sort.Strings(bTypes)
+	for _, bType := range bTypes {
+		lintBlock(issues, joinPath(path, bType), bs.Blocks[bType], parents)
+	}
+}
+
+func lintBlock(issues *[]LintIssue, path string, block *BlockSchema, parents map[*BlockSchema]bool) {
+	if block == nil || parents[block] {
+		return
+	}
+	parents[block] = true
+	defer delete(parents, block)
+
+	var baseAttrs map[string]*AttributeSchema
+	var baseBlocks map[string]*BlockSchema
+	if block.Body != nil {
+		baseAttrs = block.Body.Attributes
+		baseBlocks = block.Body.Blocks
+
+		names := attributeNames(block.Body)
+		sort.Strings(names)
+		for _, name := range names {
+			attr := block.Body.Attributes[name]
+			if attr.IsDepKey && !isScalarAttribute(attr) {
+				*issues = append(*issues, LintIssue{
+					Kind:       NonScalarDepKeyAttribute,
+					Path:       joinPath(path, name),
+					Message:    fmt.Sprintf("attribute of type %s cannot be used as dependency key", attributeTypeName(attr)),
+					Suggestion: "use a primitive type or unmark IsDepKey",
+				})
+			}
+		}
+	}
+
+	lintBody(issues, path, block.Body, parents)
+
+	keys := make([]string, 0, len(block.DependentBody))
+	for key := range block.DependentBody {
+		keys = append(keys, string(key))
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		depPath := fmt.Sprintf("%s[%s]", path, schemaKeyString(SchemaKey(key)))
+		depBody := block.DependentBody[SchemaKey(key)]
+
+		lintSchemaKey(issues, depPath, block, SchemaKey(key))
+
+		if depBody == nil {
+			continue
+		}
+
+		names := attributeNames(depBody)
+		sort.Strings(names)
+		for _, name := range names {
+			if _, ok := baseAttrs[name]; ok {
+				*issues = append(*issues, LintIssue{
+					Kind:       RedefinedBaseAttribute,
+					Path:       joinPath(depPath, name),
+					Message:    fmt.Sprintf("attribute %q is already defined in the block body and takes precedence", name),
+					Suggestion: "remove it from either the dependent body or the block body",
+				})
+			}
+		}
+
+		bTypes := blockTypes(depBody)
+		sort.Strings(bTypes)
+		for _, bType := range bTypes {
+			if _, ok := baseBlocks[bType]; ok {
+				*issues = append(*issues, LintIssue{
+					Kind:       RedefinedBaseBlock,
+					Path:       joinPath(depPath, bType),
+					Message:    fmt.Sprintf("block %q is already defined in the block body and takes precedence", bType),
+					Suggestion: "remove it from either the dependent body or the block body",
+				})
+			}
+		}
+
+		lintBody(issues, depPath, depBody, parents)
+	}
+}
+
+// lintSchemaKey reports key if it depends on labels or attributes
+// which are never collected as dependency keys by the decoder
+func lintSchemaKey(issues *[]LintIssue, path string, block *BlockSchema, key SchemaKey) {
+	var dks DependencyKeys
+	err := json.Unmarshal([]byte(key), &dks)
+	if err != nil {
+		// reported by Validate
+		return
+	}
+
+	for _, ld := range dks.Labels {
+		if ld.Index < 0 || ld.Index >= len(block.Labels) {
+			*issues = append(*issues, LintIssue{
+				Kind:       UnreachableDependentBody,
+				Path:       path,
+				Message:    fmt.Sprintf("label index %d is out of range", ld.Index),
+				Suggestion: "remove the dependent body or declare the label",
+			})
+			continue
+		}
+		label := block.Labels[ld.Index]
+		if label != nil && !label.IsDepKey {
+			*issues = append(*issues, LintIssue{
+				Kind:       UnreachableDependentBody,
+				Path:       path,
+				Message:    fmt.Sprintf("label %q is not marked as IsDepKey", label.Name),
+				Suggestion: "mark the label as IsDepKey",
+			})
+		}
+	}
+
+	for _, ad := range dks.Attributes {
+		var attr *AttributeSchema
+		if block.Body != nil {
+			attr = block.Body.Attributes[ad.Name]
+		}
+		if attr == nil {
+			*issues = append(*issues, LintIssue{
+				Kind:       UnreachableDependentBody,
+				Path:       path,
+				Message:    fmt.Sprintf("attribute %q is not defined in the block body", ad.Name),
+				Suggestion: "declare the attribute in the block body and mark it as IsDepKey",
+			})
+			continue
+		}
+		if !attr.IsDepKey {
+			*issues = append(*issues, LintIssue{
+				Kind:       UnreachableDependentBody,
+				Path:       path,
+				Message:    fmt.Sprintf("attribute %q is not marked as IsDepKey", ad.Name),
+				Suggestion: "mark the attribute as IsDepKey",
+			})
+		}
+	}
+}
+
+// isScalarAttribute returns true if none of the attribute's types
+// is a collection or structural type
+func isScalarAttribute(attr *AttributeSchema) bool {
+	types := attr.ValueTypes
+	if len(types) == 0 {
+		types = ValueTypes{attr.ValueType}
+	}
+	for _, t := range types {
+		if t == cty.NilType {
+			continue
+		}
+		if t.IsCollectionType() || t.IsObjectType() || t.IsTupleType() {
+			return false
+		}
+	}
+	return true
+}
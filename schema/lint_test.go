@@ -0,0 +1,162 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestLint(t *testing.T) {
+	providerKey := NewSchemaKey(DependencyKeys{
+		Labels: []LabelDependent{
+			{Index: 0, Value: "aws_instance"},
+		},
+		Attributes: []AttributeDependent{
+			{
+				Name: "provider",
+				Expr: ExpressionValue{
+					Reference: lang.Reference{
+						lang.RootStep{Name: "aws"},
+						lang.AttrStep{Name: "west"},
+					},
+				},
+			},
+		},
+	})
+	regionKey := NewSchemaKey(DependencyKeys{
+		Attributes: []AttributeDependent{
+			{
+				Name: "region",
+				Expr: ExpressionValue{Static: cty.StringVal("eu-west-1")},
+			},
+		},
+	})
+	nameKey := NewSchemaKey(DependencyKeys{
+		Labels: []LabelDependent{
+			{Index: 1, Value: "foo"},
+		},
+	})
+	typeKey := NewSchemaKey(DependencyKeys{
+		Labels: []LabelDependent{
+			{Index: 0, Value: "aws_instance"},
+		},
+	})
+
+	bs := &BodySchema{
+		Blocks: map[string]*BlockSchema{
+			"resource": {
+				Labels: []*LabelSchema{
+					{Name: "type", IsDepKey: true},
+					{Name: "name"},
+				},
+				Body: &BodySchema{
+					Attributes: map[string]*AttributeSchema{
+						"count":    {ValueType: cty.Number, IsOptional: true},
+						"provider": {ValueType: cty.DynamicPseudoType, IsOptional: true},
+						"tags":     {ValueType: cty.Map(cty.String), IsOptional: true, IsDepKey: true},
+					},
+					Blocks: map[string]*BlockSchema{
+						"lifecycle": {},
+					},
+				},
+				DependentBody: map[SchemaKey]*BodySchema{
+					providerKey: {},
+					regionKey:   {},
+					nameKey:     {},
+					typeKey: {
+						Attributes: map[string]*AttributeSchema{
+							"ami":   {ValueType: cty.String, IsOptional: true},
+							"count": {ValueType: cty.Number, IsRequired: true},
+						},
+						Blocks: map[string]*BlockSchema{
+							"lifecycle": {},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	expectedIssues := []LintIssue{
+		{
+			Kind:       NonScalarDepKeyAttribute,
+			Path:       "resource.tags",
+			Message:    "attribute of type map of string cannot be used as dependency key",
+			Suggestion: "use a primitive type or unmark IsDepKey",
+		},
+		{
+			Kind:       UnreachableDependentBody,
+			Path:       `resource[region="eu-west-1"]`,
+			Message:    `attribute "region" is not defined in the block body`,
+			Suggestion: "declare the attribute in the block body and mark it as IsDepKey",
+		},
+		{
+			Kind:       UnreachableDependentBody,
+			Path:       `resource["aws_instance", provider=aws.west]`,
+			Message:    `attribute "provider" is not marked as IsDepKey`,
+			Suggestion: "mark the attribute as IsDepKey",
+		},
+		{
+			Kind:       RedefinedBaseAttribute,
+			Path:       `resource["aws_instance"].count`,
+			Message:    `attribute "count" is already defined in the block body and takes precedence`,
+			Suggestion: "remove it from either the dependent body or the block body",
+		},
+		{
+			Kind:       RedefinedBaseBlock,
+			Path:       `resource["aws_instance"].lifecycle`,
+			Message:    `block "lifecycle" is already defined in the block body and takes precedence`,
+			Suggestion: "remove it from either the dependent body or the block body",
+		},
+		{
+			Kind:       UnreachableDependentBody,
+			Path:       `resource["foo"]`,
+			Message:    `label "name" is not marked as IsDepKey`,
+			Suggestion: "mark the label as IsDepKey",
+		},
+	}
+
+	issues := Lint(bs)
+	if diff := cmp.Diff(expectedIssues, issues); diff != "" {
+		t.Fatalf("unexpected issues: %s", diff)
+	}
+
+	expectedString := `resource.tags: attribute of type map of string cannot be used as dependency key (use a primitive type or unmark IsDepKey)`
+	if diff := cmp.Diff(expectedString, issues[0].String()); diff != "" {
+		t.Fatalf("unexpected string: %s", diff)
+	}
+}
+
+func TestLint_recursiveBlock(t *testing.T) {
+	nodeSchema := &BlockSchema{
+		Body: &BodySchema{
+			Attributes: map[string]*AttributeSchema{
+				"tags": {ValueType: cty.Map(cty.String), IsOptional: true, IsDepKey: true},
+			},
+		},
+	}
+	nodeSchema.Body.Blocks = map[string]*BlockSchema{
+		"node": nodeSchema,
+	}
+	bs := &BodySchema{
+		Blocks: map[string]*BlockSchema{
+			"node": nodeSchema,
+		},
+	}
+
+	expectedIssues := []LintIssue{
+		{
+			Kind:       NonScalarDepKeyAttribute,
+			Path:       "node.tags",
+			Message:    "attribute of type map of string cannot be used as dependency key",
+			Suggestion: "use a primitive type or unmark IsDepKey",
+		},
+	}
+
+	issues := Lint(bs)
+	if diff := cmp.Diff(expectedIssues, issues); diff != "" {
+		t.Fatalf("unexpected issues: %s", diff)
+	}
+}
@@ -0,0 +1,326 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/hashicorp/hcl-lang/lang"
+)
+
+// ConflictPolicy describes how conflicting definitions
+// are resolved when merging schemas
+type ConflictPolicy uint
+
+const (
+	// ConflictError causes merging to fail on any conflict
+	ConflictError ConflictPolicy = iota
+	// ConflictPreferBase keeps the definition which was merged first
+	ConflictPreferBase
+	// ConflictPreferOverlay replaces the definition by the one merged later
+	ConflictPreferOverlay
+)
+
+// MergeBodySchemas merges the given overlays into base and returns
+// a new BodySchema, failing on any conflicting definitions.
+//
+// See MergeBodySchemasWithPolicy for details.
+func MergeBodySchemas(base *BodySchema, overlays ...*BodySchema) (*BodySchema, error) {
+	return MergeBodySchemasWithPolicy(ConflictError, base, overlays...)
+}
+
+// MergeBodySchemasWithPolicy merges the given overlays into base
+// (in the order provided) and returns a new BodySchema.
+//
+// Blocks, Attributes and DependentBody are merged at every depth.
+// Blocks declared in both schemas are merged recursively. Other fields
+// (e.g. Description or attributes as a whole) only conflict if both
+// schemas declare them with different values, in which case
+// the given policy decides the outcome. None of the given schemas
+// are mutated, but the result may share unmodified parts with them.
+func MergeBodySchemasWithPolicy(policy ConflictPolicy, base *BodySchema, overlays ...*BodySchema) (*BodySchema, error) {
+	merged := NewBodySchema()
+	m := &merger{policy: policy}
+
+	schemas := append([]*BodySchema{base}, overlays...)
+	for _, bs := range schemas {
+		if bs == nil {
+			continue
+		}
+		err := m.mergeBody(merged, bs, "")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return merged, nil
+}
+
+type merger struct {
+	policy ConflictPolicy
+}
+
+// conflict decides whether the overlay should replace the base value
+func (m *merger) conflict(path, what string) (bool, error) {
+	switch m.policy {
+	case ConflictPreferBase:
+		return false, nil
+	case ConflictPreferOverlay:
+		return true, nil
+	}
+	if path == "" {
+		return false, fmt.Errorf("conflicting %s", what)
+	}
+	return false, fmt.Errorf("%s: conflicting %s", path, what)
+}
+
+// mergeBody merges src into dst, where dst is owned by the merger
+func (m *merger) mergeBody(dst, src *BodySchema, path string) error {
+	var err error
+
+	dst.IsDeprecated = dst.IsDeprecated || src.IsDeprecated
+
+	dst.Detail, err = m.mergeString(dst.Detail, src.Detail, path, "detail")
+	if err != nil {
+		return err
+	}
+	dst.Description, err = m.mergeMarkup(dst.Description, src.Description, path, "description")
+	if err != nil {
+		return err
+	}
+
+	if src.DocsLink != nil {
+		if dst.DocsLink == nil {
+			dst.DocsLink = src.DocsLink
+		} else if *dst.DocsLink != *src.DocsLink {
+			replace, err := m.conflict(path, "docs link")
+			if err != nil {
+				return err
+			}
+			if replace {
+				dst.DocsLink = src.DocsLink
+			}
+		}
+	}
+
+	if src.AnyAttribute != nil {
+		if dst.AnyAttribute == nil {
+			dst.AnyAttribute = src.AnyAttribute
+		} else if !reflect.DeepEqual(dst.AnyAttribute, src.AnyAttribute) {
+			replace, err := m.conflict(path, "any attribute")
+			if err != nil {
+				return err
+			}
+			if replace {
+				dst.AnyAttribute = src.AnyAttribute
+			}
+		}
+	}
+
+	if dst.Attributes == nil {
+		dst.Attributes = make(map[string]*AttributeSchema, len(src.Attributes))
+	}
+	for name, attr := range src.Attributes {
+		existing, ok := dst.Attributes[name]
+		if !ok || reflect.DeepEqual(existing, attr) {
+			dst.Attributes[name] = attr
+			continue
+		}
+		replace, err := m.conflict(path, fmt.Sprintf("attribute %q", name))
+		if err != nil {
+			return err
+		}
+		if replace {
+			dst.Attributes[name] = attr
+		}
+	}
+
+	if dst.Blocks == nil {
+		dst.Blocks = make(map[string]*BlockSchema, len(src.Blocks))
+	}
+	for bType, block := range src.Blocks {
+		existing, ok := dst.Blocks[bType]
+		if !ok {
+			dst.Blocks[bType] = block
+			continue
+		}
+		merged, err := m.mergeBlock(existing, block, joinPath(path, bType))
+		if err != nil {
+			return err
+		}
+		dst.Blocks[bType] = merged
+	}
+
+	return nil
+}
+
+// mergeBlock returns a new BlockSchema with src merged into dst
+func (m *merger) mergeBlock(dst, src *BlockSchema, path string) (*BlockSchema, error) {
+	var err error
+	merged := &BlockSchema{
+		Labels:       dst.Labels,
+		Type:         dst.Type,
+		Description:  dst.Description,
+		IsDeprecated: dst.IsDeprecated || src.IsDeprecated,
+		MinItems:     dst.MinItems,
+		MaxItems:     dst.MaxItems,
+		Address:      dst.Address,
+	}
+
+	if len(src.Labels) > 0 {
+		if len(merged.Labels) == 0 {
+			merged.Labels = src.Labels
+		} else if !reflect.DeepEqual(merged.Labels, src.Labels) {
+			replace, err := m.conflict(path, "labels")
+			if err != nil {
+				return nil, err
+			}
+			if replace {
+				merged.Labels = src.Labels
+			}
+		}
+	}
+
+	if src.Address != nil {
+		if merged.Address == nil {
+			merged.Address = src.Address
+		} else if !reflect.DeepEqual(merged.Address, src.Address) {
+			replace, err := m.conflict(path, "address")
+			if err != nil {
+				return nil, err
+			}
+			if replace {
+				merged.Address = src.Address
+			}
+		}
+	}
+
+	if src.Type != BlockTypeNil {
+		if merged.Type == BlockTypeNil {
+			merged.Type = src.Type
+		} else if merged.Type != src.Type {
+			replace, err := m.conflict(path, "block type")
+			if err != nil {
+				return nil, err
+			}
+			if replace {
+				merged.Type = src.Type
+			}
+		}
+	}
+
+	merged.Description, err = m.mergeMarkup(merged.Description, src.Description, path, "description")
+	if err != nil {
+		return nil, err
+	}
+	merged.MinItems, err = m.mergeUint(merged.MinItems, src.MinItems, path, "min items")
+	if err != nil {
+		return nil, err
+	}
+	merged.MaxItems, err = m.mergeUint(merged.MaxItems, src.MaxItems, path, "max items")
+	if err != nil {
+		return nil, err
+	}
+
+	merged.Body, err = m.mergeOptionalBody(dst.Body, src.Body, path)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(dst.DependentBody) > 0 || len(src.DependentBody) > 0 {
+		merged.DependentBody = make(map[SchemaKey]*BodySchema, len(dst.DependentBody)+len(src.DependentBody))
+		for key, body := range dst.DependentBody {
+			merged.DependentBody[key] = body
+		}
+		for key, body := range src.DependentBody {
+			mergedBody, err := m.mergeOptionalBody(merged.DependentBody[key], body,
+				fmt.Sprintf("%s (%s)", path, key))
+			if err != nil {
+				return nil, err
+			}
+			merged.DependentBody[key] = mergedBody
+		}
+	}
+
+	return merged, nil
+}
+
+// mergeOptionalBody returns a new BodySchema with src merged into dst
+// if both are present, or whichever of them is present
+func (m *merger) mergeOptionalBody(dst, src *BodySchema, path string) (*BodySchema, error) {
+	if dst == nil {
+		return src, nil
+	}
+	if src == nil {
+		return dst, nil
+	}
+
+	merged := NewBodySchema()
+	err := m.mergeBody(merged, dst, path)
+	if err != nil {
+		return nil, err
+	}
+	err = m.mergeBody(merged, src, path)
+	if err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+func (m *merger) mergeString(dst, src, path, what string) (string, error) {
+	if src == "" || dst == src {
+		return dst, nil
+	}
+	if dst == "" {
+		return src, nil
+	}
+	replace, err := m.conflict(path, what)
+	if err != nil {
+		return dst, err
+	}
+	if replace {
+		return src, nil
+	}
+	return dst, nil
+}
+
+func (m *merger) mergeMarkup(dst, src lang.MarkupContent, path, what string) (lang.MarkupContent, error) {
+	if src.Value == "" || dst == src {
+		return dst, nil
+	}
+	if dst.Value == "" {
+		return src, nil
+	}
+	replace, err := m.conflict(path, what)
+	if err != nil {
+		return dst, err
+	}
+	if replace {
+		return src, nil
+	}
+	return dst, nil
+}
+
+func (m *merger) mergeUint(dst, src uint64, path, what string) (uint64, error) {
+	if src == 0 || dst == src {
+		return dst, nil
+	}
+	if dst == 0 {
+		return src, nil
+	}
+	replace, err := m.conflict(path, what)
+	if err != nil {
+		return dst, err
+	}
+	if replace {
+		return src, nil
+	}
+	return dst, nil
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return strings.Join([]string{path, name}, ".")
+}
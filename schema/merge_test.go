@@ -0,0 +1,292 @@
+package schema
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/hashicorp/hcl-lang/lang"
+	"github.com/zclconf/go-cty-debug/ctydebug"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var mergeCmpOpts = cmp.Options{ctydebug.CmpOptions, cmpopts.EquateEmpty()}
+
+func TestMergeBodySchemas(t *testing.T) {
+	awsKey := NewSchemaKey(DependencyKeys{
+		Labels: []LabelDependent{
+			{Index: 0, Value: "aws_instance"},
+		},
+	})
+	googleKey := NewSchemaKey(DependencyKeys{
+		Labels: []LabelDependent{
+			{Index: 0, Value: "google_compute_instance"},
+		},
+	})
+
+	base := &BodySchema{
+		Attributes: map[string]*AttributeSchema{
+			"version": {ValueType: cty.String, IsOptional: true},
+		},
+		Blocks: map[string]*BlockSchema{
+			"resource": {
+				Type:        BlockTypeList,
+				Description: lang.PlainText("resource block"),
+				Labels: []*LabelSchema{
+					{Name: "type", IsDepKey: true},
+					{Name: "name"},
+				},
+				Body: &BodySchema{
+					Attributes: map[string]*AttributeSchema{
+						"count": {ValueType: cty.Number, IsOptional: true},
+					},
+				},
+			},
+		},
+	}
+	awsOverlay := &BodySchema{
+		Blocks: map[string]*BlockSchema{
+			"resource": {
+				DependentBody: map[SchemaKey]*BodySchema{
+					awsKey: {
+						Detail: "hashicorp/aws",
+						Attributes: map[string]*AttributeSchema{
+							"ami": {ValueType: cty.String, IsRequired: true},
+						},
+					},
+				},
+			},
+		},
+	}
+	googleOverlay := &BodySchema{
+		Attributes: map[string]*AttributeSchema{
+			// identical definition does not conflict
+			"version": {ValueType: cty.String, IsOptional: true},
+		},
+		Blocks: map[string]*BlockSchema{
+			"resource": {
+				Type: BlockTypeList,
+				DependentBody: map[SchemaKey]*BodySchema{
+					googleKey: {
+						Detail: "hashicorp/google",
+					},
+				},
+			},
+		},
+	}
+
+	merged, err := MergeBodySchemas(base, awsOverlay, googleOverlay)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedSchema := &BodySchema{
+		Attributes: map[string]*AttributeSchema{
+			"version": {ValueType: cty.String, IsOptional: true},
+		},
+		Blocks: map[string]*BlockSchema{
+			"resource": {
+				Type:        BlockTypeList,
+				Description: lang.PlainText("resource block"),
+				Labels: []*LabelSchema{
+					{Name: "type", IsDepKey: true},
+					{Name: "name"},
+				},
+				Body: &BodySchema{
+					Attributes: map[string]*AttributeSchema{
+						"count": {ValueType: cty.Number, IsOptional: true},
+					},
+				},
+				DependentBody: map[SchemaKey]*BodySchema{
+					awsKey: {
+						Detail: "hashicorp/aws",
+						Attributes: map[string]*AttributeSchema{
+							"ami": {ValueType: cty.String, IsRequired: true},
+						},
+					},
+					googleKey: {
+						Detail: "hashicorp/google",
+					},
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(expectedSchema, merged, mergeCmpOpts); diff != "" {
+		t.Fatalf("unexpected merged schema: %s", diff)
+	}
+
+	if len(base.Blocks["resource"].DependentBody) != 0 {
+		t.Fatal("expected base schema not to be mutated")
+	}
+}
+
+func TestMergeBodySchemasWithPolicy_conflicts(t *testing.T) {
+	base := &BodySchema{
+		Detail: "base",
+		Attributes: map[string]*AttributeSchema{
+			"name": {ValueType: cty.String, IsRequired: true},
+		},
+		Blocks: map[string]*BlockSchema{
+			"provider": {
+				Type:     BlockTypeObject,
+				MaxItems: 1,
+				DependentBody: map[SchemaKey]*BodySchema{
+					NewSchemaKey(DependencyKeys{}): {
+						Attributes: map[string]*AttributeSchema{
+							"region": {ValueType: cty.String, IsOptional: true},
+						},
+					},
+				},
+			},
+		},
+	}
+	overlay := &BodySchema{
+		Detail: "overlay",
+		Attributes: map[string]*AttributeSchema{
+			"name": {ValueType: cty.Number, IsOptional: true},
+		},
+		Blocks: map[string]*BlockSchema{
+			"provider": {
+				Type:     BlockTypeList,
+				MaxItems: 2,
+				DependentBody: map[SchemaKey]*BodySchema{
+					NewSchemaKey(DependencyKeys{}): {
+						Attributes: map[string]*AttributeSchema{
+							"region": {ValueType: cty.Number, IsOptional: true},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name           string
+		policy         ConflictPolicy
+		expectedSchema *BodySchema
+		expectedErr    string
+	}{
+		{
+			"error",
+			ConflictError,
+			nil,
+			`conflicting detail`,
+		},
+		{
+			"prefer base",
+			ConflictPreferBase,
+			&BodySchema{
+				Detail: "base",
+				Attributes: map[string]*AttributeSchema{
+					"name": {ValueType: cty.String, IsRequired: true},
+				},
+				Blocks: map[string]*BlockSchema{
+					"provider": {
+						Type:     BlockTypeObject,
+						MaxItems: 1,
+						DependentBody: map[SchemaKey]*BodySchema{
+							NewSchemaKey(DependencyKeys{}): {
+								Attributes: map[string]*AttributeSchema{
+									"region": {ValueType: cty.String, IsOptional: true},
+								},
+							},
+						},
+					},
+				},
+			},
+			"",
+		},
+		{
+			"prefer overlay",
+			ConflictPreferOverlay,
+			&BodySchema{
+				Detail: "overlay",
+				Attributes: map[string]*AttributeSchema{
+					"name": {ValueType: cty.Number, IsOptional: true},
+				},
+				Blocks: map[string]*BlockSchema{
+					"provider": {
+						Type:     BlockTypeList,
+						MaxItems: 2,
+						DependentBody: map[SchemaKey]*BodySchema{
+							NewSchemaKey(DependencyKeys{}): {
+								Attributes: map[string]*AttributeSchema{
+									"region": {ValueType: cty.Number, IsOptional: true},
+								},
+							},
+						},
+					},
+				},
+			},
+			"",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.name), func(t *testing.T) {
+			merged, err := MergeBodySchemasWithPolicy(tc.policy, base, overlay)
+			if tc.expectedErr != "" {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				if diff := cmp.Diff(tc.expectedErr, err.Error()); diff != "" {
+					t.Fatalf("unexpected error: %s", diff)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expectedSchema, merged, mergeCmpOpts); diff != "" {
+				t.Fatalf("unexpected merged schema: %s", diff)
+			}
+		})
+	}
+}
+
+func TestMergeBodySchemas_nestedConflict(t *testing.T) {
+	base := &BodySchema{
+		Blocks: map[string]*BlockSchema{
+			"resource": {
+				Body: &BodySchema{
+					Blocks: map[string]*BlockSchema{
+						"lifecycle": {
+							Body: &BodySchema{
+								Attributes: map[string]*AttributeSchema{
+									"ignore_changes": {ValueType: cty.List(cty.String), IsOptional: true},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	overlay := &BodySchema{
+		Blocks: map[string]*BlockSchema{
+			"resource": {
+				Body: &BodySchema{
+					Blocks: map[string]*BlockSchema{
+						"lifecycle": {
+							Body: &BodySchema{
+								Attributes: map[string]*AttributeSchema{
+									"ignore_changes": {ValueType: cty.String, IsOptional: true},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	_, err := MergeBodySchemas(base, overlay)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	expectedErr := `resource.lifecycle: conflicting attribute "ignore_changes"`
+	if diff := cmp.Diff(expectedErr, err.Error()); diff != "" {
+		t.Fatalf("unexpected error: %s", diff)
+	}
+}